@@ -24,6 +24,8 @@ var (
 
 func init() {
 	rootCmd.AddCommand(New())
+	rootCmd.AddCommand(NewVulnDB())
+	rootCmd.AddCommand(NewReport())
 }
 
 func main() {
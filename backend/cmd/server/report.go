@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"bin-vul-inspector/cmd/version"
+	"bin-vul-inspector/pkg/bha"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/report"
+	"bin-vul-inspector/pkg/vulndb"
+)
+
+type reportHTMLOptions struct {
+	input      string
+	output     string
+	name       string
+	binary     string
+	method     string
+	algorithm  string
+	model      string
+	minimumSim float64
+	nvd        []string
+}
+
+// NewReport 离线报告工具
+func NewReport() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "report",
+		Short:        "Offline report tools",
+		Long:         "Offline report tools",
+		SilenceUsage: true,
+	}
+	cmd.AddCommand(newReportHTML())
+	return cmd
+}
+
+func newReportHTML() *cobra.Command {
+	opts := new(reportHTMLOptions)
+	cmd := &cobra.Command{
+		Use:   "html",
+		Short: "Render an exported bha-result.json as a single-file HTML report",
+		Long: `Render an exported bha-result.json as a single-file HTML report.
+
+The report needs neither the server nor a database. Task metadata that is not
+part of bha-result.json can be given by flags. Pass --binary to compute the
+hash and size of the scanned file, and --nvd to add severity from local NVD
+JSON 2.0 feeds.`,
+		Example:      "bin-vul-inspector report html -i bha-result.json -o report.html --binary firmware.bin --algorithm sfs --nvd nvdcve-2.0-2024.json.gz",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.input, "input", "i", bha.ResultJsonFilename, "bha-result.json file")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "report.html", "output html file")
+	cmd.Flags().StringVar(&opts.name, "name", "", "task name")
+	cmd.Flags().StringVar(&opts.binary, "binary", "", "scanned file, used to compute hash and size")
+	cmd.Flags().StringVar(&opts.method, "detection-method", "", "detection method")
+	cmd.Flags().StringVar(&opts.algorithm, "algorithm", "", "detection algorithm")
+	cmd.Flags().StringVar(&opts.model, "model", "", "model name")
+	cmd.Flags().Float64Var(&opts.minimumSim, "minimum-sim", 0, "minimum similarity used by the scan")
+	cmd.Flags().StringSliceVar(&opts.nvd, "nvd", nil, "NVD JSON 2.0 feed files or directories")
+	return cmd
+}
+
+func (opts *reportHTMLOptions) run() error {
+	meta := report.HTMLMeta{
+		Name:            opts.name,
+		DetectionMethod: opts.method,
+		Algorithm:       opts.algorithm,
+		Model:           opts.model,
+		MinimumSim:      opts.minimumSim,
+	}
+	if opts.binary != "" {
+		hash, size, err := fileSHA256(opts.binary)
+		if err != nil {
+			return err
+		}
+		meta.FileHash, meta.FileSize = hash, size
+	}
+
+	vulns, err := opts.vulns()
+	if err != nil {
+		return err
+	}
+
+	builder := report.NewHTMLBuilder(version.Version, meta, vulns)
+	if err = decodeResultFile(opts.input, &htmlResultHandler{builder: builder}); err != nil {
+		return err
+	}
+
+	f, err := os.Create(opts.output)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err = builder.Render(w); err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// vulns 从本地NVD数据源读取结果中匹配到的CVE
+func (opts *reportHTMLOptions) vulns() (report.Vulns, error) {
+	vulns := make(report.Vulns)
+	if len(opts.nvd) == 0 {
+		return vulns, nil
+	}
+
+	feeds, err := expandNVDFeeds(opts.nvd)
+	if err != nil {
+		return nil, err
+	}
+
+	cves := &cveCollector{cves: make(map[string]struct{})}
+	if err = decodeResultFile(opts.input, cves); err != nil {
+		return nil, err
+	}
+
+	for _, feed := range feeds {
+		err = parseNVDFeed(feed, func(vuln *models.Vulnerability) error {
+			if _, ok := cves.cves[vuln.CVE]; ok {
+				vulns[vuln.CVE] = vuln
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", feed, err)
+		}
+	}
+	return vulns, nil
+}
+
+func parseNVDFeed(path string, handler func(vuln *models.Vulnerability) error) error {
+	r, err := vulndb.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	return vulndb.ParseNVD(r, handler)
+}
+
+func decodeResultFile(path string, handler bha.ResultHandler) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	return bha.DecodeResult(bufio.NewReader(f), handler)
+}
+
+func fileSHA256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// htmlResultHandler 将扫描结果写入HTML报告
+type htmlResultHandler struct {
+	builder *report.HTMLBuilder
+	stats   bha.FileStats
+}
+
+func (h *htmlResultHandler) HandleFunc(file *bha.Result, fn *bha.Func) error {
+	h.stats.Add(fn)
+	if len(fn.Results) == 0 {
+		return nil
+	}
+
+	results := make([]models.BhaFuncResult, 0, len(fn.Results))
+	for _, e := range fn.Results {
+		results = append(results, models.BhaFuncResult{
+			Purl:     e.Purl,
+			Version:  e.Version,
+			Refs:     e.Refs,
+			FName:    e.FName,
+			CVE:      e.CVE,
+			Arch:     e.Arch,
+			OptLevel: e.OptLevel,
+			Sim:      e.Sim,
+		})
+	}
+	h.builder.AddFunc(&models.BhaFunc{
+		FileId:   file.FileId,
+		FileArch: file.FileArch,
+		FilePath: file.FilePath,
+		Addr:     fn.Addr,
+		FName:    fn.FName,
+	}, results)
+	return nil
+}
+
+func (h *htmlResultHandler) HandleFile(file *bha.Result) error {
+	h.builder.AddFile(&models.BhaFile{
+		FileId:           file.FileId,
+		FilePath:         file.FilePath,
+		FileArch:         file.FileArch,
+		FuncCount:        h.stats.FuncCount,
+		MatchedFuncCount: h.stats.MatchedFuncCount,
+		BestSim:          h.stats.BestSim,
+		CVECount:         h.stats.CVECount(),
+	})
+	h.stats = bha.FileStats{}
+	return nil
+}
+
+// cveCollector 收集扫描结果中的CVE
+type cveCollector struct {
+	cves map[string]struct{}
+}
+
+func (c *cveCollector) HandleFunc(_ *bha.Result, fn *bha.Func) error {
+	for _, e := range fn.Results {
+		if e.CVE != "" {
+			c.cves[e.CVE] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (c *cveCollector) HandleFile(*bha.Result) error {
+	return nil
+}
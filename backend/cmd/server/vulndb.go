@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"bin-vul-inspector/app"
+	"bin-vul-inspector/pkg/api/services"
+	"bin-vul-inspector/pkg/mongo"
+)
+
+type vulndbOptions struct {
+	config string
+	nvd    []string
+	kev    string
+	epss   string
+}
+
+// NewVulnDB 离线漏洞库管理
+func NewVulnDB() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "vulndb",
+		Short:        "Offline vulnerability database",
+		Long:         "Offline vulnerability database",
+		SilenceUsage: true,
+	}
+	cmd.AddCommand(newVulnDBImport())
+	return cmd
+}
+
+func newVulnDBImport() *cobra.Command {
+	opts := new(vulndbOptions)
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import NVD JSON 2.0 feeds, CISA KEV and EPSS CSV from local files",
+		Long: `Import NVD JSON 2.0 feeds, CISA KEV and EPSS CSV from local files.
+
+Files may be gzip compressed. A directory passed to --nvd imports every
+*.json and *.json.gz file in it. Re-importing updates existing records.`,
+		Example:      "bin-vul-inspector vulndb import -c config.yaml --nvd nvdcve-2.0-2024.json.gz --kev known_exploited_vulnerabilities.csv --epss epss_scores.csv.gz",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(context.Background())
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.config, "config", "c", "config.yaml", "config file")
+	cmd.Flags().StringSliceVar(&opts.nvd, "nvd", nil, "NVD JSON 2.0 feed files or directories")
+	cmd.Flags().StringVar(&opts.kev, "kev", "", "CISA KEV csv file")
+	cmd.Flags().StringVar(&opts.epss, "epss", "", "EPSS csv file")
+	return cmd
+}
+
+func (opts *vulndbOptions) run(ctx context.Context) error {
+	nvd, err := expandNVDFeeds(opts.nvd)
+	if err != nil {
+		return err
+	}
+	if len(nvd) == 0 && opts.kev == "" && opts.epss == "" {
+		return fmt.Errorf("at least one of --nvd, --kev, --epss is required")
+	}
+
+	toolkit, err := app.NewKit(ctx, opts.config)
+	if err != nil {
+		return err
+	}
+	defer toolkit.Close(ctx)
+
+	if err = mongo.InitCollections(ctx, toolkit.Mongo); err != nil {
+		return fmt.Errorf("init mongo indices error: %w", err)
+	}
+
+	stat, err := services.NewVulnerability(toolkit).Import(ctx, services.VulnImportOption{
+		NVD:  nvd,
+		KEV:  opts.kev,
+		EPSS: opts.epss,
+	})
+	if err != nil {
+		return err
+	}
+
+	toolkit.Logger.Infof("import finished, nvd: %d, kev: %d, epss: %d", stat.NVD, stat.KEV, stat.EPSS)
+	return nil
+}
+
+// expandNVDFeeds 展开目录中的NVD数据源文件
+func expandNVDFeeds(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() && (strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")) {
+				files = append(files, filepath.Join(p, name))
+			}
+		}
+	}
+	return files, nil
+}
@@ -16,6 +16,87 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/bha/diff": {
+            "get": {
+                "description": "对比同一固件不同版本的扫描结果，文件按路径或唯一的文件名匹配，函数按函数名或地址匹配\n- fixed: 基准任务中存在，对比任务中消失，可能已修复\n- introduced: 对比任务中新增\n- changed: 相似分数变化超过阈值\n- unchanged: 相似分数变化未超过阈值，unchanged=true 时返回\nformat 为 sarif 或 html 时以附件形式返回，sarif 以 baselineState 标记变化",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "BhaTask"
+                ],
+                "summary": "对比两个任务的漏洞匹配结果",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "基准任务id",
+                        "name": "base",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "对比任务id",
+                        "name": "head",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 1,
+                        "minimum": 0,
+                        "type": "number",
+                        "default": 0.05,
+                        "description": "相似分数变化阈值",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否包含未变化的匹配",
+                        "name": "unchanged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否包含被抑制规则命中的匹配",
+                        "name": "suppressed",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "sarif",
+                            "html"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "结果格式",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.BhaDiff"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
         "/bha/model": {
             "get": {
                 "tags": [
@@ -212,20 +293,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/bha/task/{task_id}/file/func_results": {
+        "/bha/search": {
             "get": {
+                "description": "按 CVE、purl、版本、函数名称、相似分数范围检索所有任务的函数相似性对比结果\ncve、purl、fname 至少填写一个",
                 "tags": [
                     "BhaTask"
                 ],
-                "summary": "func result 函数相似性对比结果",
+                "summary": "search 跨任务检索检测结果",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "task_id",
-                        "name": "task_id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "minimum": 1,
                         "type": "integer",
@@ -246,21 +321,44 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "func id",
-                        "name": "func_id",
-                        "in": "query",
-                        "required": true
+                        "description": "CVE编号",
+                        "name": "cve",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "topN",
-                        "name": "top_n",
+                        "description": "purl, 前缀匹配",
+                        "name": "purl",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "关键字查询",
-                        "name": "q",
+                        "description": "版本",
+                        "name": "version",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "函数名称, 匹配检测文件函数或匹配文件函数",
+                        "name": "fname",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "最小相似分数",
+                        "name": "min_sim",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "最大相似分数",
+                        "name": "max_sim",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否包含被抑制规则命中的结果",
+                        "name": "suppressed",
                         "in": "query"
                     }
                 ],
@@ -276,7 +374,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.ListResponse-models_BhaFunc"
+                                            "$ref": "#/definitions/dto.ListResponse-models_BhaSearchHit"
                                         }
                                     }
                                 }
@@ -286,20 +384,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/bha/task/{task_id}/file/funcs": {
+        "/bha/suppressions": {
             "get": {
                 "tags": [
-                    "BhaTask"
+                    "BhaSuppression"
                 ],
-                "summary": "func 检测文件函数列表",
+                "summary": "抑制规则列表",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "task_id",
-                        "name": "task_id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "minimum": 1,
                         "type": "integer",
@@ -320,9 +411,21 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "关键字查询",
+                        "description": "关键字查询, CVE、函数名称、文件名",
                         "name": "q",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "项目",
+                        "name": "project",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件hash",
+                        "name": "file_hash",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -337,7 +440,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.ListResponse-models_BhaFunc"
+                                            "$ref": "#/definitions/dto.ListResponse-models_BhaSuppression"
                                         }
                                     }
                                 }
@@ -345,132 +448,91 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/bha/task/{task_id}/report": {
-            "get": {
-                "description": "获取报告",
+            },
+            "post": {
+                "description": "规则仅应用于之后入库的扫描结果",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "BhaTask"
+                    "BhaSuppression"
                 ],
-                "summary": "获取报告",
+                "summary": "新增抑制规则",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "task_id",
-                        "name": "task_id",
-                        "in": "path",
-                        "required": true
+                        "description": "抑制规则",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BhaSuppressionReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.CreateRes"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/tasks": {
+        "/bha/suppressions/export": {
             "get": {
-                "consumes": [
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "BhaSuppression"
+                ],
+                "summary": "导出抑制规则文件",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BhaSuppressionFile"
+                        }
+                    }
+                }
+            }
+        },
+        "/bha/suppressions/import": {
+            "post": {
+                "description": "按匹配条件合并，已存在的规则更新研判状态、依据及原因",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "BhaSuppression"
                 ],
-                "summary": "任务列表",
+                "summary": "导入抑制规则文件",
                 "parameters": [
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 1,
-                        "example": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 20,
-                        "example": 20,
-                        "description": "页大小",
-                        "name": "page_size",
-                        "in": "query",
+                        "type": "file",
+                        "description": "抑制规则文件",
+                        "name": "upload_file",
+                        "in": "formData",
                         "required": true
-                    },
-                    {
-                        "enum": [
-                            "bha"
-                        ],
-                        "type": "string",
-                        "description": "任务模式",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "任务id",
-                        "name": "task_ids",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "名称",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "web"
-                        ],
-                        "type": "string",
-                        "description": "来源",
-                        "name": "source",
-                        "in": "query"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "enum": [
-                                "queuing",
-                                "processing",
-                                "finished",
-                                "failed",
-                                "terminated"
-                            ],
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "任务状态",
-                        "name": "statuses",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "开始时间",
-                        "name": "start_at",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "结束时间",
-                        "name": "end_at",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -485,34 +547,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "allOf": [
-                                                {
-                                                    "$ref": "#/definitions/dto.ListResponse-dto_TaskListItem"
-                                                },
-                                                {
-                                                    "type": "object",
-                                                    "properties": {
-                                                        "list": {
-                                                            "type": "array",
-                                                            "items": {
-                                                                "allOf": [
-                                                                    {
-                                                                        "$ref": "#/definitions/dto.TaskListItem"
-                                                                    },
-                                                                    {
-                                                                        "type": "object",
-                                                                        "properties": {
-                                                                            "status": {
-                                                                                "type": "string"
-                                                                            }
-                                                                        }
-                                                                    }
-                                                                ]
-                                                            }
-                                                        }
-                                                    }
-                                                }
-                                            ]
+                                            "$ref": "#/definitions/dto.BhaSuppressionImportRes"
                                         }
                                     }
                                 }
@@ -520,120 +555,37 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "#### mode为上传扫描时(默认):\n- types,必填参数\n- extra,按type分别校验\n",
+            }
+        },
+        "/bha/suppressions/{id}": {
+            "put": {
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "BhaSuppression"
                 ],
-                "summary": "创建扫描任务",
+                "summary": "更新抑制规则",
                 "parameters": [
                     {
-                        "enum": [
-                            0
-                        ],
-                        "type": "integer",
-                        "description": "任务模式 0,上传扫描",
-                        "name": "mode",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "任务名称",
-                        "name": "name",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "描述",
-                        "name": "desc",
-                        "in": "formData"
-                    },
-                    {
-                        "enum": [
-                            "web"
-                        ],
                         "type": "string",
-                        "default": "web",
-                        "description": "来源",
-                        "name": "source",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "enum": [
-                                "bha"
-                            ],
-                            "type": "string"
-                        },
-                        "collectionFormat": "multi",
-                        "description": "任务模式",
-                        "name": "types",
-                        "in": "formData",
+                        "description": "规则id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "文件",
-                        "name": "upload_file",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "default": "{\"bha\":{\"detection_method\": \"fast\", \"algorithm\":\"SFS\",\"model_id\": \"\"}}",
-                        "description": "任务配置(默认值仅方便swagger中输入)",
-                        "name": "extra",
-                        "in": "formData"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                        "description": "抑制规则",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/dto.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/dto.TaskCreateRes"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/dto.BhaSuppressionReq"
                         }
                     }
-                }
-            }
-        },
-        "/tasks/{task_id}": {
-            "get": {
-                "description": "任务详情",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Task"
-                ],
-                "summary": "任务详情",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "task_id",
-                        "name": "task_id",
-                        "in": "path",
-                        "required": true
-                    }
                 ],
                 "responses": {
                     "200": {
@@ -647,19 +599,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "allOf": [
-                                                {
-                                                    "$ref": "#/definitions/dto.TaskDetail"
-                                                },
-                                                {
-                                                    "type": "object",
-                                                    "properties": {
-                                                        "status": {
-                                                            "type": "string"
-                                                        }
-                                                    }
-                                                }
-                                            ]
+                                            "$ref": "#/definitions/models.BhaSuppression"
                                         }
                                     }
                                 }
@@ -669,19 +609,19 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "删除任务，以及相应数据",
+                "description": "已入库的扫描结果保持抑制状态",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "BhaSuppression"
                 ],
-                "summary": "删除任务",
+                "summary": "删除抑制规则",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "task_id",
-                        "name": "task_id",
+                        "description": "规则id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -696,16 +636,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/{task_id}/terminate": {
-            "post": {
-                "description": "中止任务",
-                "produces": [
-                    "application/json"
-                ],
+        "/bha/task/{task_id}/abis": {
+            "get": {
+                "description": "上传 APK、AAB、AAR、JAR 等安装包时按 ABI 分组统计 native 库的扫描结果，其他任务为空",
                 "tags": [
-                    "Task"
+                    "BhaTask"
                 ],
-                "summary": "中止任务",
+                "summary": "abi 安装包各 ABI 的扫描结果统计",
                 "parameters": [
                     {
                         "type": "string",
@@ -719,21 +656,34 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.BhaAbiSummary"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/tasks/{task_id}/{type}/asm_file": {
+        "/bha/task/{task_id}/cves": {
             "get": {
-                "produces": [
-                    "text/plain"
-                ],
+                "description": "按 CVE/Purl/Version 聚合函数相似性对比结果，并补充漏洞库中的漏洞信息",
                 "tags": [
-                    "Task"
+                    "BhaTask"
                 ],
-                "summary": "任务反汇编文件",
+                "summary": "cve 按CVE聚合的检测结果",
                 "parameters": [
                     {
                         "type": "string",
@@ -743,32 +693,76 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "type",
-                        "name": "type",
-                        "in": "path",
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "页大小",
+                        "name": "page_size",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字查询, CVE编号或purl",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sim",
+                            "severity",
+                            "epss"
+                        ],
+                        "type": "string",
+                        "default": "sim",
+                        "description": "排序方式",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否包含被抑制规则命中的结果",
+                        "name": "suppressed",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ListResponse-models_BhaCVE"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/tasks/{task_id}/{type}/log": {
+        "/bha/task/{task_id}/file/func_results": {
             "get": {
-                "produces": [
-                    "text/plain"
-                ],
                 "tags": [
-                    "Task"
+                    "BhaTask"
                 ],
-                "summary": "任务日志文件",
+                "summary": "func result 函数相似性对比结果",
                 "parameters": [
                     {
                         "type": "string",
@@ -778,367 +772,2981 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "type",
-                        "name": "type",
-                        "in": "path",
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query",
                         "required": true
                     },
                     {
+                        "minimum": 1,
                         "type": "integer",
-                        "description": "skip",
-                        "name": "skip",
+                        "default": 20,
+                        "description": "页大小",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "func id",
+                        "name": "func_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "topN",
+                        "name": "top_n",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "关键字查询",
+                        "name": "q",
                         "in": "query"
-                    }
+                    },
+                    {
+                        "enum": [
+                            "untriaged",
+                            "confirmed",
+                            "false_positive",
+                            "wont_fix"
+                        ],
+                        "type": "string",
+                        "description": "研判状态",
+                        "name": "triage_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "处理人",
+                        "name": "assignee",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否包含被抑制规则命中的结果",
+                        "name": "suppressed",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ListResponse-models_BhaFuncResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/bha/task/{task_id}/file/func_results/export": {
+            "get": {
+                "description": "按函数导出全部匹配结果，每个匹配结果一行，包含函数及文件信息\n- csv: 首行为列名，refs以空格分隔，无匹配结果的函数rank及sim为空\n- ndjson: 每行一个json对象",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "BhaTask"
+                ],
+                "summary": "导出函数相似性对比结果",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "导出格式",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "type": "integer",
+                        "description": "每个函数导出的匹配结果数",
+                        "name": "top_n",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字查询, 匹配函数名称",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1,
+                        "minimum": 0,
+                        "type": "number",
+                        "description": "最小相似分数",
+                        "name": "min_sim",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否导出无匹配结果的函数",
+                        "name": "unmatched",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否导出被抑制规则命中的结果",
+                        "name": "suppressed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/bha/task/{task_id}/file/func_results/triage": {
+            "put": {
+                "description": "仅更新传入的字段，comment 非空时为每个结果追加评论，suppress 为 true 时同时创建抑制规则，单次最多1000个",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "BhaTask"
+                ],
+                "summary": "批量研判函数相似性对比结果",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "研判信息",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BhaFuncResultBulkTriageReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.BhaTriageRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/bha/task/{task_id}/file/func_results/{id}/triage": {
+            "put": {
+                "description": "仅更新传入的字段，comment 非空时追加评论，suppress 为 true 时同时创建抑制规则",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "BhaTask"
+                ],
+                "summary": "研判函数相似性对比结果",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "func result id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "研判信息",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BhaTriageReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.BhaFuncResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/bha/task/{task_id}/file/funcs": {
+            "get": {
+                "tags": [
+                    "BhaTask"
+                ],
+                "summary": "func 检测文件函数列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "页大小",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件 id",
+                        "name": "file_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字查询",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "untriaged",
+                            "confirmed",
+                            "false_positive",
+                            "wont_fix"
+                        ],
+                        "type": "string",
+                        "description": "存在该研判状态的匹配结果",
+                        "name": "triage_status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "存在该处理人的匹配结果",
+                        "name": "assignee",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "研判条件是否包含被抑制规则命中的结果",
+                        "name": "suppressed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ListResponse-models_BhaFunc"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/bha/task/{task_id}/files": {
+            "get": {
+                "tags": [
+                    "BhaTask"
+                ],
+                "summary": "file 检测文件列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "页大小",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字查询, 文件路径",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "安装包中 native 库的 ABI",
+                        "name": "abi",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ListResponse-models_BhaFile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/bha/task/{task_id}/report": {
+            "get": {
+                "description": "获取报告\n- zip: 原始扫描结果 bha-result.json 压缩包\n- sarif: SARIF 2.1.0，每个检测文件函数与CVE的匹配为一条结果\n- cyclonedx: CycloneDX 1.5 SBOM及VEX，根据匹配结果推断每个检测文件内嵌的组件\n- html: 单文件HTML报告，包含任务信息、漏洞统计、文件及函数匹配明细，可离线查看",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "BhaTask"
+                ],
+                "summary": "获取报告",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "zip",
+                            "sarif",
+                            "cyclonedx",
+                            "html"
+                        ],
+                        "type": "string",
+                        "default": "zip",
+                        "description": "报告格式",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/sca/task/{task_id}/components": {
+            "get": {
+                "description": "根据版本字符串、SONAME 及符号版本识别的组件，按名称及版本聚合，CVE 由漏洞库中 NVD 的 CPE 配置按版本关联",
+                "tags": [
+                    "ScaTask"
+                ],
+                "summary": "component 组件列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "页大小",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字查询, 组件名称或文件路径",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "仅返回存在漏洞的组件",
+                        "name": "vulnerable",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ListResponse-models_ScaComponent"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "任务列表",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "example": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "example": 20,
+                        "description": "页大小",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "bha"
+                        ],
+                        "type": "string",
+                        "description": "任务模式",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "任务id",
+                        "name": "task_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "名称",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "web"
+                        ],
+                        "type": "string",
+                        "description": "来源",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "queuing",
+                                "processing",
+                                "finished",
+                                "failed",
+                                "terminated"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "任务状态",
+                        "name": "statuses",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "开始时间",
+                        "name": "start_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "结束时间",
+                        "name": "end_at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/dto.ListResponse-dto_TaskListItem"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "list": {
+                                                            "type": "array",
+                                                            "items": {
+                                                                "allOf": [
+                                                                    {
+                                                                        "$ref": "#/definitions/dto.TaskListItem"
+                                                                    },
+                                                                    {
+                                                                        "type": "object",
+                                                                        "properties": {
+                                                                            "status": {
+                                                                                "type": "string"
+                                                                            }
+                                                                        }
+                                                                    }
+                                                                ]
+                                                            }
+                                                        }
+                                                    }
+                                                }
+                                            ]
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "#### mode为上传扫描时(默认):\n- types,必填参数\n- extra,按type分别校验\n- extra.bha.top_n,可选,每个函数保留的候选结果数,默认 sfs 1 其他 100,最大 sfs 10 其他 500\n- extra.bha.minimum_sim,可选,最小相似度 0~1,默认 0\n- extra.bha.no_cache,可选,为 true 时不复用相同文件及参数的扫描结果,默认 false\n- extra.bha.input,可选,输入类型 file 可执行文件、压缩包或固件镜像,container 为 docker save 或 OCI 镜像布局的 tar 包,默认 file\n- extra.sca.input,可选,输入类型,取值同 extra.bha.input,默认 file\n- bha、sca 扫描时按文件头识别上传文件,不是可执行文件、压缩包、安装包或固件镜像,或 bha 扫描时可执行文件架构不支持时返回 1523\n",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "创建扫描任务",
+                "parameters": [
+                    {
+                        "enum": [
+                            0
+                        ],
+                        "type": "integer",
+                        "description": "任务模式 0,上传扫描",
+                        "name": "mode",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务名称",
+                        "name": "name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "描述",
+                        "name": "desc",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "web"
+                        ],
+                        "type": "string",
+                        "default": "web",
+                        "description": "来源",
+                        "name": "source",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "enum": [
+                                "bha"
+                            ],
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "任务模式",
+                        "name": "types",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "文件",
+                        "name": "upload_file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "{\"bha\":{\"detection_method\": \"fast\", \"algorithm\":\"SFS\",\"model_id\": \"\", \"top_n\": 1, \"minimum_sim\": 0}}",
+                        "description": "任务配置(默认值仅方便swagger中输入)",
+                        "name": "extra",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.TaskCreateRes"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}": {
+            "get": {
+                "description": "任务详情",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "任务详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "allOf": [
+                                                {
+                                                    "$ref": "#/definitions/dto.TaskDetail"
+                                                },
+                                                {
+                                                    "type": "object",
+                                                    "properties": {
+                                                        "status": {
+                                                            "type": "string"
+                                                        }
+                                                    }
+                                                }
+                                            ]
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "删除任务，以及相应数据",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "删除任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/files": {
+            "get": {
+                "description": "上传压缩包解包出的文件，skipped 为未扫描的原因",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "解包文件列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "页大小",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "binary",
+                            "archive",
+                            "other"
+                        ],
+                        "type": "string",
+                        "description": "文件类型",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "true 仅未扫描的文件，false 仅扫描单元",
+                        "name": "skipped",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "关键字查询, 文件路径",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ListResponse-models_TaskFile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/files/tree": {
+            "get": {
+                "description": "上传压缩包解包出的文件树，嵌套压缩包的节点包含其解压出的文件",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "解包文件树",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.TaskFileTree"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/terminate": {
+            "post": {
+                "description": "中止任务",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "中止任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/{type}/asm_file": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "任务反汇编文件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{task_id}/{type}/log": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "任务日志文件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task_id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "skip",
+                        "name": "skip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/vulnerabilities/{cve}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vulnerability"
+                ],
+                "summary": "漏洞详情",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "CVE编号",
+                        "name": "cve",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Vulnerability"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.BhaFuncResultBulkTriageReq": {
+            "type": "object",
+            "properties": {
+                "assignee": {
+                    "description": "处理人，空字符串表示清除",
+                    "type": "string"
+                },
+                "author": {
+                    "description": "评论人",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "评论",
+                    "type": "string"
+                },
+                "ids": {
+                    "description": "bha func result id",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "justification": {
+                    "description": "研判依据，空字符串表示清除",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "研判状态",
+                    "type": "string"
+                },
+                "suppress": {
+                    "description": "同时创建抑制规则，应用于同一项目的后续扫描",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.BhaModelItem": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_builtin": {
+                    "description": "是否是内置",
+                    "type": "boolean"
+                },
+                "md5": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "模型名称",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "模型路径",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "模型类型 ssfs,bsd",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BhaSuppressionFile": {
+            "type": "object",
+            "properties": {
+                "suppressions": {
+                    "description": "规则",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BhaSuppressionReq"
+                    }
+                },
+                "version": {
+                    "description": "文件格式版本",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.BhaSuppressionImportRes": {
+            "type": "object",
+            "properties": {
+                "inserted": {
+                    "description": "新增的规则数",
+                    "type": "integer"
+                },
+                "updated": {
+                    "description": "已存在并更新的规则数",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.BhaSuppressionReq": {
+            "type": "object",
+            "properties": {
+                "addr": {
+                    "description": "函数地址",
+                    "type": "string"
+                },
+                "author": {
+                    "description": "创建人",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "原因",
+                    "type": "string"
+                },
+                "cve": {
+                    "description": "CVE编号",
+                    "type": "string"
+                },
+                "file_hash": {
+                    "description": "适用的文件hash，为空时不限",
+                    "type": "string"
+                },
+                "file_name": {
+                    "description": "文件名，不含目录，为空时匹配全部文件",
+                    "type": "string"
+                },
+                "fname": {
+                    "description": "检测文件函数名称",
+                    "type": "string"
+                },
+                "justification": {
+                    "description": "研判依据",
+                    "type": "string"
+                },
+                "max_sim": {
+                    "description": "最大相似分数，默认1",
+                    "type": "number"
+                },
+                "min_sim": {
+                    "description": "最小相似分数，默认0",
+                    "type": "number"
+                },
+                "project": {
+                    "description": "适用的项目，即任务名称，为空时不限",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "命中后的研判状态，默认 false_positive",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BhaTriageReq": {
+            "type": "object",
+            "properties": {
+                "assignee": {
+                    "description": "处理人，空字符串表示清除",
+                    "type": "string"
+                },
+                "author": {
+                    "description": "评论人",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "评论",
+                    "type": "string"
+                },
+                "justification": {
+                    "description": "研判依据，空字符串表示清除",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "研判状态",
+                    "type": "string"
+                },
+                "suppress": {
+                    "description": "同时创建抑制规则，应用于同一项目的后续扫描",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.BhaTriageRes": {
+            "type": "object",
+            "properties": {
+                "matched": {
+                    "description": "匹配到的结果数",
+                    "type": "integer"
+                },
+                "suppressed": {
+                    "description": "新增的抑制规则数",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreateRes": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ListResponse-dto_BhaModelItem": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BhaModelItem"
+                    }
+                }
+            }
+        },
+        "dto.ListResponse-dto_TaskListItem": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TaskListItem"
+                    }
+                }
+            }
+        },
+        "dto.ListResponse-models_BhaCVE": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaCVE"
+                    }
+                }
+            }
+        },
+        "dto.ListResponse-models_BhaFile": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaFile"
+                    }
+                }
+            }
+        },
+        "dto.ListResponse-models_BhaFunc": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaFunc"
+                    }
+                }
+            }
+        },
+        "dto.ListResponse-models_BhaFuncResult": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaFuncResult"
+                    }
+                }
+            }
+        },
+        "dto.ListResponse-models_BhaSearchHit": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaSearchHit"
+                    }
+                }
+            }
+        },
+        "dto.ListResponse-models_BhaSuppression": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaSuppression"
+                    }
+                }
+            }
+        },
+        "dto.ListResponse-models_ScaComponent": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScaComponent"
+                    }
+                }
+            }
+        },
+        "dto.ListResponse-models_TaskFile": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskFile"
+                    }
+                }
+            }
+        },
+        "dto.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {},
+                "err_message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TaskCreateRes": {
+            "type": "object",
+            "properties": {
+                "task_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TaskDetail": {
+            "type": "object",
+            "properties": {
+                "backend": {
+                    "description": "执行bha扫描的后端",
+                    "type": "string"
+                },
+                "binary": {
+                    "description": "上传的可执行文件的预分析结果，扫描前仅包含格式、架构等文件头信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BinaryInfo"
+                        }
+                    ]
+                },
+                "cached_from": {
+                    "description": "复用扫描结果的任务id",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "debug_message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "desc": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "object",
+                    "properties": {
+                        "bha": {
+                            "$ref": "#/definitions/models.BhaParams"
+                        },
+                        "sast": {
+                            "$ref": "#/definitions/models.SastParams"
+                        },
+                        "sca": {
+                            "$ref": "#/definitions/models.ScaParams"
+                        }
+                    }
+                },
+                "detect_method": {
+                    "type": "string"
+                },
+                "err_message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "file_hash": {
+                    "type": "string"
+                },
+                "file_md5": {
+                    "type": "string"
+                },
+                "file_path": {
+                    "type": "string"
+                },
+                "file_sha1": {
+                    "type": "string"
+                },
+                "file_size": {
+                    "type": "integer"
+                },
+                "modified_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "package": {
+                    "description": "上传的安装包类型 apk, aab, aar, jar",
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "bha扫描进度",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskProgress"
+                        }
+                    ]
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unpack": {
+                    "description": "上传压缩包的解包统计",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskUnpack"
+                        }
+                    ]
+                },
+                "vulnerabilities": {
+                    "description": "bha匹配到的CVE严重等级统计",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.VulnSummary"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.TaskFileNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TaskFileNode"
+                    }
+                },
+                "format": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "未扫描的原因",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "dir, binary, archive, image, other",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TaskFileTree": {
+            "type": "object",
+            "properties": {
+                "root": {
+                    "description": "根目录",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.TaskFileNode"
+                        }
+                    ]
+                },
+                "unpack": {
+                    "description": "解包统计，上传文件不是压缩包时为空",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskUnpack"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.TaskListItem": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "debug_message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "desc": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "object",
+                    "properties": {
+                        "bha": {
+                            "$ref": "#/definitions/models.BhaParams"
+                        },
+                        "sast": {
+                            "$ref": "#/definitions/models.SastParams"
+                        },
+                        "sca": {
+                            "$ref": "#/definitions/models.ScaParams"
+                        }
+                    }
+                },
+                "detect_method": {
+                    "type": "string"
+                },
+                "err_message": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "file_hash": {
+                    "type": "string"
+                },
+                "file_md5": {
+                    "type": "string"
+                },
+                "file_path": {
+                    "type": "string"
+                },
+                "file_sha1": {
+                    "type": "string"
+                },
+                "file_size": {
+                    "type": "integer"
+                },
+                "modified_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.BhaAbiSummary": {
+            "type": "object",
+            "properties": {
+                "abi": {
+                    "description": "ABI",
+                    "type": "string"
+                },
+                "arch": {
+                    "description": "ABI 对应的架构",
+                    "type": "string"
+                },
+                "best_sim": {
+                    "description": "最高相似分数",
+                    "type": "number"
+                },
+                "cve_count": {
+                    "description": "匹配到的CVE数(同一 ABI 内去重)",
+                    "type": "integer"
+                },
+                "file_count": {
+                    "description": "文件数",
+                    "type": "integer"
+                },
+                "matched_func_count": {
+                    "description": "存在匹配结果的函数数",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BhaCVE": {
+            "type": "object",
+            "properties": {
+                "archs": {
+                    "description": "引用函数的架构",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "best_sim": {
+                    "description": "最高相似分数",
+                    "type": "number"
+                },
+                "cve": {
+                    "description": "CVE编号",
+                    "type": "string"
+                },
+                "files": {
+                    "description": "受影响的文件",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaCVEFile"
+                    }
+                },
+                "funcs": {
+                    "description": "匹配的检测文件函数，按相似分数降序",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaCVEFunc"
+                    }
+                },
+                "optlevels": {
+                    "description": "引用函数的优化等级",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "purl": {
+                    "description": "purl",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "版本",
+                    "type": "string"
+                },
+                "vuln": {
+                    "description": "漏洞信息，查询时补充",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Vulnerability"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.BhaCVEFile": {
+            "type": "object",
+            "properties": {
+                "file_arch": {
+                    "description": "二进制文件架构",
+                    "type": "string"
+                },
+                "file_id": {
+                    "description": "文件 id",
+                    "type": "string"
+                },
+                "file_path": {
+                    "description": "文件路径",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BhaCVEFunc": {
+            "type": "object",
+            "properties": {
+                "addr": {
+                    "description": "函数地址",
+                    "type": "string"
+                },
+                "file_id": {
+                    "description": "文件 id",
+                    "type": "string"
+                },
+                "file_path": {
+                    "description": "文件路径",
+                    "type": "string"
+                },
+                "fname": {
+                    "description": "检测文件函数名称",
+                    "type": "string"
+                },
+                "func_id": {
+                    "description": "bha func id",
+                    "type": "string"
+                },
+                "sim": {
+                    "description": "相似分数",
+                    "type": "number"
+                }
+            }
+        },
+        "models.BhaDiff": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "基准任务",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BhaDiffTask"
+                        }
+                    ]
+                },
+                "head": {
+                    "description": "对比任务",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BhaDiffTask"
+                        }
+                    ]
+                },
+                "items": {
+                    "description": "明细",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaDiffItem"
+                    }
+                },
+                "summary": {
+                    "description": "统计",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BhaDiffSummary"
+                        }
+                    ]
+                },
+                "threshold": {
+                    "description": "相似分数变化阈值",
+                    "type": "number"
+                }
+            }
+        },
+        "models.BhaDiffItem": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "description": "基准任务中的匹配",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BhaFinding"
+                        }
+                    ]
+                },
+                "cve": {
+                    "description": "CVE编号",
+                    "type": "string"
+                },
+                "delta": {
+                    "description": "相似分数变化，对比任务减基准任务",
+                    "type": "number"
+                },
+                "head": {
+                    "description": "对比任务中的匹配",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BhaFinding"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "状态",
+                    "type": "string"
+                },
+                "vuln": {
+                    "description": "漏洞信息，查询时补充",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Vulnerability"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.BhaDiffSummary": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "description": "相似分数变化超过阈值的匹配数",
+                    "type": "integer"
+                },
+                "fixed": {
+                    "description": "消失的匹配数",
+                    "type": "integer"
+                },
+                "fixed_cves": {
+                    "description": "对比任务中完全消失的CVE",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "introduced": {
+                    "description": "新增的匹配数",
+                    "type": "integer"
+                },
+                "introduced_cves": {
+                    "description": "基准任务中不存在的CVE",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unchanged": {
+                    "description": "相似分数变化未超过阈值的匹配数",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BhaDiffTask": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "file_hash": {
+                    "description": "文件hash",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "任务名称",
+                    "type": "string"
+                },
+                "task_id": {
+                    "description": "任务id",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BhaFile": {
+            "type": "object",
+            "properties": {
+                "abi": {
+                    "description": "安装包中 native 库的 ABI",
+                    "type": "string"
+                },
+                "best_sim": {
+                    "description": "最高相似分数",
+                    "type": "number"
+                },
+                "cve_count": {
+                    "description": "匹配到的CVE数(去重)",
+                    "type": "integer"
+                },
+                "file_arch": {
+                    "description": "二进制文件架构",
+                    "type": "string"
+                },
+                "file_id": {
+                    "description": "文件 id",
+                    "type": "string"
+                },
+                "file_path": {
+                    "description": "文件路径",
+                    "type": "string"
+                },
+                "func_count": {
+                    "description": "函数数",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "id",
+                    "type": "string"
+                },
+                "image": {
+                    "description": "容器镜像 digest",
+                    "type": "string"
+                },
+                "layer": {
+                    "description": "引入该文件的镜像层 digest",
+                    "type": "string"
+                },
+                "matched_func_count": {
+                    "description": "存在匹配结果的函数数",
+                    "type": "integer"
+                },
+                "task_id": {
+                    "description": "任务id",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BhaFinding": {
+            "type": "object",
+            "properties": {
+                "addr": {
+                    "description": "函数地址",
+                    "type": "string"
+                },
+                "arch": {
+                    "description": "架构",
+                    "type": "string"
+                },
+                "cve": {
+                    "description": "CVE编号",
+                    "type": "string"
+                },
+                "file_arch": {
+                    "description": "二进制文件架构",
+                    "type": "string"
+                },
+                "file_id": {
+                    "description": "文件 id",
+                    "type": "string"
+                },
+                "file_path": {
+                    "description": "文件路径",
+                    "type": "string"
+                },
+                "fname": {
+                    "description": "检测文件函数名称",
+                    "type": "string"
+                },
+                "func_id": {
+                    "description": "bha func id",
+                    "type": "string"
+                },
+                "optlevel": {
+                    "description": "优化等级",
+                    "type": "string"
+                },
+                "purl": {
+                    "description": "purl",
+                    "type": "string"
+                },
+                "ref_fname": {
+                    "description": "匹配文件函数名称",
+                    "type": "string"
+                },
+                "sim": {
+                    "description": "相似分数",
+                    "type": "number"
+                },
+                "suppression_id": {
+                    "description": "命中的抑制规则id",
+                    "type": "string"
+                },
+                "task_id": {
+                    "description": "任务id",
+                    "type": "string"
+                },
+                "triage": {
+                    "description": "研判信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BhaTriage"
+                        }
+                    ]
+                },
+                "version": {
+                    "description": "版本",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BhaFunc": {
+            "type": "object",
+            "properties": {
+                "addr": {
+                    "description": "函数地址",
+                    "type": "string"
+                },
+                "file_arch": {
+                    "description": "二进制文件架构",
+                    "type": "string"
+                },
+                "file_id": {
+                    "description": "文件 id",
+                    "type": "string"
+                },
+                "file_path": {
+                    "description": "文件路径",
+                    "type": "string"
+                },
+                "fname": {
+                    "description": "检测文件函数名称",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "id",
+                    "type": "string"
+                },
+                "task_id": {
+                    "description": "任务id",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BhaFuncResult": {
+            "type": "object",
+            "properties": {
+                "arch": {
+                    "description": "架构",
+                    "type": "string"
+                },
+                "cve": {
+                    "description": "CVE编号",
+                    "type": "string"
+                },
+                "fname": {
+                    "description": "匹配文件函数名称",
+                    "type": "string"
+                },
+                "func_id": {
+                    "description": "bha func id",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "id",
+                    "type": "string"
+                },
+                "optlevel": {
+                    "description": "优化等级",
+                    "type": "string"
+                },
+                "purl": {
+                    "description": "purl",
+                    "type": "string"
+                },
+                "refs": {
+                    "description": "引用",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sim": {
+                    "description": "相似分数",
+                    "type": "number"
+                },
+                "suppression_id": {
+                    "description": "命中的抑制规则id",
+                    "type": "string"
+                },
+                "task_id": {
+                    "description": "任务id",
+                    "type": "string"
+                },
+                "triage": {
+                    "description": "研判信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BhaTriage"
+                        }
+                    ]
+                },
+                "version": {
+                    "description": "版本",
+                    "type": "string"
+                },
+                "vuln": {
+                    "description": "漏洞信息，查询时补充",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Vulnerability"
                         }
+                    ]
+                }
+            }
+        },
+        "models.BhaParams": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "description": "检测算法 sfs,ssfs,bsd",
+                    "type": "string"
+                },
+                "arches": {
+                    "description": "支持的架构，升序，扫描时记录",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
+                },
+                "detection_method": {
+                    "description": "检测方式 fast, intelligent",
+                    "type": "string"
+                },
+                "input": {
+                    "description": "输入类型 file, container",
+                    "type": "string"
+                },
+                "max_depth": {
+                    "description": "嵌套解包深度上限，扫描时记录",
+                    "type": "integer"
+                },
+                "max_file_count": {
+                    "description": "解包文件数上限，扫描时记录",
+                    "type": "integer"
+                },
+                "max_file_size": {
+                    "description": "解包单个文件大小上限，扫描时记录",
+                    "type": "integer"
+                },
+                "minimum_sim": {
+                    "description": "最小相似度 [0,1]",
+                    "type": "number"
+                },
+                "model_hash": {
+                    "description": "模型文件内容hash，扫描时记录",
+                    "type": "string"
+                },
+                "model_id": {
+                    "description": "模型id",
+                    "type": "string"
+                },
+                "no_cache": {
+                    "description": "不复用相同文件的扫描结果，强制重新扫描",
+                    "type": "boolean"
+                },
+                "top_n": {
+                    "description": "每个函数保留的候选结果数",
+                    "type": "integer"
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.BhaModelItem": {
+        },
+        "models.BhaSearchHit": {
+            "type": "object",
+            "properties": {
+                "addr": {
+                    "description": "函数地址",
+                    "type": "string"
+                },
+                "arch": {
+                    "description": "架构",
+                    "type": "string"
+                },
+                "cve": {
+                    "description": "CVE编号",
+                    "type": "string"
+                },
+                "file_arch": {
+                    "description": "二进制文件架构",
+                    "type": "string"
+                },
+                "file_id": {
+                    "description": "文件 id",
+                    "type": "string"
+                },
+                "file_path": {
+                    "description": "文件路径",
+                    "type": "string"
+                },
+                "fname": {
+                    "description": "检测文件函数名称",
+                    "type": "string"
+                },
+                "func_id": {
+                    "description": "bha func id",
+                    "type": "string"
+                },
+                "optlevel": {
+                    "description": "优化等级",
+                    "type": "string"
+                },
+                "purl": {
+                    "description": "purl",
+                    "type": "string"
+                },
+                "ref_fname": {
+                    "description": "匹配文件函数名称",
+                    "type": "string"
+                },
+                "sim": {
+                    "description": "相似分数",
+                    "type": "number"
+                },
+                "task_id": {
+                    "description": "任务id",
+                    "type": "string"
+                },
+                "task_name": {
+                    "description": "任务名称",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "版本",
+                    "type": "string"
+                },
+                "vuln": {
+                    "description": "漏洞信息，查询时补充",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Vulnerability"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.BhaSuppression": {
             "type": "object",
             "properties": {
+                "addr": {
+                    "description": "函数地址，为空时不限",
+                    "type": "string"
+                },
+                "author": {
+                    "description": "创建人",
+                    "type": "string"
+                },
+                "comment": {
+                    "description": "原因",
+                    "type": "string"
+                },
                 "created_at": {
                     "description": "创建时间",
                     "type": "string"
                 },
+                "cve": {
+                    "description": "CVE编号，为空时不限",
+                    "type": "string"
+                },
+                "file_hash": {
+                    "description": "适用的文件hash，为空时不限",
+                    "type": "string"
+                },
+                "file_name": {
+                    "description": "文件名，不含目录，为空时匹配全部文件",
+                    "type": "string"
+                },
+                "fname": {
+                    "description": "检测文件函数名称，为空时不限",
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
-                "is_builtin": {
-                    "description": "是否是内置",
-                    "type": "boolean"
+                "justification": {
+                    "description": "研判依据",
+                    "type": "string"
                 },
-                "md5": {
+                "max_sim": {
+                    "description": "最大相似分数",
+                    "type": "number"
+                },
+                "min_sim": {
+                    "description": "最小相似分数",
+                    "type": "number"
+                },
+                "project": {
+                    "description": "适用的项目，即任务名称，为空时不限",
                     "type": "string"
                 },
-                "name": {
-                    "description": "模型名称",
+                "status": {
+                    "description": "命中后的研判状态 false_positive, wont_fix",
                     "type": "string"
                 },
-                "path": {
-                    "description": "模型路径",
+                "updated_at": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BhaTriage": {
+            "type": "object",
+            "properties": {
+                "assignee": {
+                    "description": "处理人",
                     "type": "string"
                 },
-                "type": {
-                    "description": "模型类型 ssfs,bsd",
+                "comments": {
+                    "description": "评论",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BhaTriageComment"
+                    }
+                },
+                "justification": {
+                    "description": "研判依据",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "研判状态",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "更新时间",
                     "type": "string"
                 }
             }
         },
-        "dto.CreateRes": {
+        "models.BhaTriageComment": {
             "type": "object",
             "properties": {
-                "id": {
+                "author": {
+                    "description": "评论人",
+                    "type": "string"
+                },
+                "content": {
+                    "description": "内容",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "评论时间",
                     "type": "string"
                 }
             }
         },
-        "dto.ListResponse-dto_BhaModelItem": {
+        "models.BinaryInfo": {
             "type": "object",
             "properties": {
-                "count": {
+                "arch": {
+                    "description": "架构 x86, x86_64, arm, arm64, mips, mips64 等",
+                    "type": "string"
+                },
+                "bits": {
+                    "description": "位数 32, 64",
+                    "type": "integer"
+                },
+                "build_id": {
+                    "description": "ELF 的 GNU build-id，Mach-O 的 UUID",
+                    "type": "string"
+                },
+                "compilers": {
+                    "description": ".comment 段中的编译器信息",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "endian": {
+                    "description": "字节序 little, big",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "格式 elf, pe, macho",
+                    "type": "string"
+                },
+                "func_count": {
+                    "description": "函数符号数，去除符号表时为动态符号表中的函数数",
                     "type": "integer"
                 },
-                "list": {
+                "needed": {
+                    "description": "依赖的动态库",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "soname": {
+                    "description": "动态库名称，Mach-O 为 install name",
+                    "type": "string"
+                },
+                "stripped": {
+                    "description": "是否已去除符号表",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.CVSS": {
+            "type": "object",
+            "properties": {
+                "base_score": {
+                    "description": "基础分数",
+                    "type": "number"
+                },
+                "severity": {
+                    "description": "严重等级",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "评分来源",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Primary, Secondary",
+                    "type": "string"
+                },
+                "vector": {
+                    "description": "向量",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "CVSS版本 2.0,3.0,3.1,4.0",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SastParams": {
+            "type": "object",
+            "properties": {
+                "extra_params": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "lang": {
+                    "type": "string"
+                },
+                "rule_range_ids": {
+                    "description": "规则检测范围id",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rules": {
+                    "description": "自定义规则",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.BhaModelItem"
+                        "type": "string"
                     }
                 }
             }
         },
-        "dto.ListResponse-dto_TaskListItem": {
+        "models.ScaComponent": {
             "type": "object",
             "properties": {
-                "count": {
+                "cve_count": {
+                    "description": "CVE数",
                     "type": "integer"
                 },
-                "list": {
+                "cves": {
+                    "description": "影响该版本的CVE",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.TaskListItem"
+                        "type": "string"
                     }
-                }
-            }
-        },
-        "dto.ListResponse-models_BhaFunc": {
-            "type": "object",
-            "properties": {
-                "count": {
-                    "type": "integer"
                 },
-                "list": {
+                "files": {
+                    "description": "包含该组件的文件",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.BhaFunc"
+                        "$ref": "#/definitions/models.ScaFile"
                     }
-                }
-            }
-        },
-        "dto.Response": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer"
                 },
-                "data": {},
-                "err_message": {
+                "id": {
+                    "description": "id",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "组件名称",
+                    "type": "string"
+                },
+                "product": {
+                    "description": "CPE product",
+                    "type": "string"
+                },
+                "purl": {
+                    "description": "purl",
+                    "type": "string"
+                },
+                "task_id": {
+                    "description": "任务id",
+                    "type": "string"
+                },
+                "vendor": {
+                    "description": "CPE vendor",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "版本，无法确定时为空",
                     "type": "string"
+                },
+                "vulns": {
+                    "description": "漏洞严重等级统计",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.VulnSummary"
+                        }
+                    ]
                 }
             }
         },
-        "dto.TaskCreateRes": {
+        "models.ScaEvidence": {
             "type": "object",
             "properties": {
-                "task_id": {
+                "type": {
+                    "description": "string 可打印字符串, soname 共享库SONAME, symbol 符号版本",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "匹配的内容",
                     "type": "string"
                 }
             }
         },
-        "dto.TaskDetail": {
+        "models.ScaFile": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "abi": {
+                    "description": "安装包中 native 库的 ABI",
                     "type": "string"
                 },
-                "debug_message": {
+                "evidence": {
+                    "description": "识别依据",
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.ScaEvidence"
                     }
                 },
-                "desc": {
+                "file_arch": {
+                    "description": "二进制文件架构",
                     "type": "string"
                 },
-                "detail": {
-                    "type": "object",
-                    "properties": {
-                        "bha": {
-                            "$ref": "#/definitions/models.BhaParams"
-                        },
-                        "sast": {
-                            "$ref": "#/definitions/models.SastParams"
-                        },
-                        "sca": {
-                            "$ref": "#/definitions/models.ScaParams"
-                        }
-                    }
+                "file_path": {
+                    "description": "文件路径",
+                    "type": "string"
                 },
-                "detect_method": {
+                "image": {
+                    "description": "容器镜像 digest",
                     "type": "string"
                 },
-                "err_message": {
+                "layer": {
+                    "description": "引入该文件的镜像层 digest",
+                    "type": "string"
+                },
+                "symbol_version": {
+                    "description": "未识别出版本时共享库符号版本中的最高版本，仅说明组件不低于该版本",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScaParams": {
+            "type": "object",
+            "properties": {
+                "--optional-feature": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "file_hash": {
+                "input": {
+                    "description": "输入类型 file, container，与 bha 参数同时内嵌于 detail，字段名需区分",
                     "type": "string"
                 },
-                "file_path": {
+                "reachability_analysis": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.TaskFile": {
+            "type": "object",
+            "properties": {
+                "abi": {
+                    "description": "安装包中 native 库的 ABI，如 arm64-v8a",
                     "type": "string"
                 },
-                "file_size": {
-                    "type": "integer"
+                "arch": {
+                    "description": "扫描时传给 bha server 的架构，安装包中的 native 库为 ABI 对应的架构",
+                    "type": "string"
                 },
-                "modified_at": {
+                "backend": {
+                    "description": "执行扫描单元的后端",
                     "type": "string"
                 },
-                "name": {
+                "binary": {
+                    "description": "可执行文件的预分析结果",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BinaryInfo"
+                        }
+                    ]
+                },
+                "format": {
+                    "description": "可执行文件格式 elf, pe, macho，文件系统的类型 squashfs, cpio, jffs2, ubi, ubifs",
                     "type": "string"
                 },
-                "source": {
+                "id": {
                     "type": "string"
                 },
-                "status": {
+                "layer": {
+                    "description": "容器镜像中引入该文件的镜像层 digest",
+                    "type": "string"
+                },
+                "object": {
+                    "description": "扫描单元在对象存储中的路径",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "在上传文件中的路径，嵌套压缩包内的文件以压缩包路径为前缀，文件系统内的文件以 \u003c镜像路径\u003e/\u003c类型\u003e@\u003c偏移\u003e 为前缀",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "文件大小，单位为字节",
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "未扫描的原因",
                     "type": "string"
                 },
                 "task_id": {
+                    "description": "任务id",
                     "type": "string"
                 },
-                "types": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "type": {
+                    "description": "文件类型 binary, archive, image, other",
+                    "type": "string"
                 }
             }
         },
-        "dto.TaskListItem": {
+        "models.TaskImage": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "digest": {
+                    "description": "镜像 digest，OCI 为镜像清单的 digest，docker save 为镜像 id",
                     "type": "string"
                 },
-                "debug_message": {
+                "layers": {
+                    "description": "按应用顺序的镜像层 digest",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "desc": {
-                    "type": "string"
-                },
-                "detail": {
-                    "type": "object",
-                    "properties": {
-                        "bha": {
-                            "$ref": "#/definitions/models.BhaParams"
-                        },
-                        "sast": {
-                            "$ref": "#/definitions/models.SastParams"
-                        },
-                        "sca": {
-                            "$ref": "#/definitions/models.ScaParams"
-                        }
-                    }
-                },
-                "detect_method": {
+                "platform": {
+                    "description": "平台，如 linux/amd64",
                     "type": "string"
                 },
-                "err_message": {
+                "tags": {
+                    "description": "镜像名称",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
+                }
+            }
+        },
+        "models.TaskProgress": {
+            "type": "object",
+            "properties": {
+                "percent": {
+                    "description": "进度百分比 0-100",
+                    "type": "number"
                 },
-                "file_hash": {
-                    "type": "string"
-                },
-                "file_path": {
+                "phase": {
+                    "description": "当前阶段 decompile, embed, match",
                     "type": "string"
                 },
-                "file_size": {
-                    "type": "integer"
-                },
-                "modified_at": {
+                "updated_at": {
+                    "description": "更新时间",
                     "type": "string"
-                },
-                "name": {
+                }
+            }
+        },
+        "models.TaskUnpack": {
+            "type": "object",
+            "properties": {
+                "archive": {
+                    "description": "压缩包类型，安装包为 apk, aab, aar, jar，固件镜像为 firmware，容器镜像为 docker 或 oci",
                     "type": "string"
                 },
-                "source": {
-                    "type": "string"
+                "files": {
+                    "description": "文件数",
+                    "type": "integer"
                 },
-                "status": {
-                    "type": "string"
+                "image": {
+                    "description": "上传的容器镜像",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskImage"
+                        }
+                    ]
                 },
-                "task_id": {
-                    "type": "string"
+                "skipped": {
+                    "description": "未扫描的文件数",
+                    "type": "integer"
                 },
-                "types": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "units": {
+                    "description": "扫描单元数",
+                    "type": "integer"
                 }
             }
         },
-        "models.BhaFunc": {
+        "models.VulnAffected": {
             "type": "object",
             "properties": {
-                "addr": {
-                    "description": "函数地址",
+                "end_excluding": {
+                    "description": "结束版本(不含)",
                     "type": "string"
                 },
-                "file_arch": {
-                    "description": "二进制文件架构",
+                "end_including": {
+                    "description": "结束版本(含)",
                     "type": "string"
                 },
-                "file_id": {
-                    "description": "文件 id",
+                "product": {
+                    "description": "CPE product",
                     "type": "string"
                 },
-                "file_path": {
-                    "description": "文件路径",
+                "start_excluding": {
+                    "description": "起始版本(不含)",
                     "type": "string"
                 },
-                "fname": {
-                    "description": "检测文件函数名称",
+                "start_including": {
+                    "description": "起始版本(含)",
                     "type": "string"
                 },
-                "id": {
-                    "description": "id",
+                "vendor": {
+                    "description": "CPE vendor",
                     "type": "string"
                 },
-                "task_id": {
-                    "description": "任务id",
+                "version": {
+                    "description": "受影响的版本",
                     "type": "string"
                 }
             }
         },
-        "models.BhaParams": {
+        "models.VulnSummary": {
             "type": "object",
             "properties": {
-                "algorithm": {
-                    "description": "检测算法 sfs,ssfs,bsd",
-                    "type": "string"
+                "critical": {
+                    "description": "严重",
+                    "type": "integer"
                 },
-                "detection_method": {
-                    "description": "检测方式 fast, intelligent",
-                    "type": "string"
+                "high": {
+                    "description": "高危",
+                    "type": "integer"
                 },
-                "model_id": {
-                    "description": "模型id",
-                    "type": "string"
+                "kev": {
+                    "description": "在CISA KEV目录中",
+                    "type": "integer"
+                },
+                "low": {
+                    "description": "低危",
+                    "type": "integer"
+                },
+                "medium": {
+                    "description": "中危",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "CVE总数",
+                    "type": "integer"
+                },
+                "unknown": {
+                    "description": "未知，包括漏洞库中不存在的CVE",
+                    "type": "integer"
                 }
             }
         },
-        "models.SastParams": {
+        "models.Vulnerability": {
             "type": "object",
             "properties": {
-                "extra_params": {
+                "affected": {
+                    "description": "受影响的产品及版本，取自NVD CPE配置",
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.VulnAffected"
                     }
                 },
-                "lang": {
+                "cve": {
+                    "description": "CVE编号",
                     "type": "string"
                 },
-                "rule_range_ids": {
-                    "description": "规则检测范围id",
+                "cvss": {
+                    "description": "各版本CVSS评分",
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.CVSS"
                     }
                 },
-                "rules": {
-                    "description": "自定义规则",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                }
-            }
-        },
-        "models.ScaParams": {
-            "type": "object",
-            "properties": {
-                "--optional-feature": {
+                "cwes": {
+                    "description": "CWE",
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
-                "reachability_analysis": {
+                "description": {
+                    "description": "描述(英文)",
+                    "type": "string"
+                },
+                "epss": {
+                    "description": "EPSS分数",
+                    "type": "number"
+                },
+                "epss_percentile": {
+                    "description": "EPSS百分位",
+                    "type": "number"
+                },
+                "kev": {
+                    "description": "是否在CISA KEV目录中",
                     "type": "boolean"
+                },
+                "kev_date_added": {
+                    "description": "加入KEV目录的时间",
+                    "type": "string"
+                },
+                "last_modified": {
+                    "description": "最后修改时间",
+                    "type": "string"
+                },
+                "published": {
+                    "description": "发布时间",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "CVSS基础分数，取优先版本的CVSS评分",
+                    "type": "number"
+                },
+                "severity": {
+                    "description": "严重等级，取优先版本的CVSS评分",
+                    "type": "string"
                 }
             }
         }
@@ -55,6 +55,8 @@ func NewHttpHandler(kit *kit.Kit) http.Handler {
 			GET("", taskHandler.Detail).
 			DELETE("", taskHandler.Delete).
 			POST("/terminate", taskHandler.Terminate).
+			GET("/files", taskHandler.ListFile).
+			GET("/files/tree", taskHandler.FileTree).
 			GET("/:type/log", taskHandler.LogFile).
 			GET("/:type/asm_file", taskHandler.ASMFile)
 	}
@@ -65,10 +67,31 @@ func NewHttpHandler(kit *kit.Kit) http.Handler {
 
 		// task
 		v1Router.Group("/bha/task").
+			GET("/:task_id/files", bhaHandler.ListFile).
+			GET("/:task_id/abis", bhaHandler.ListAbi).
+			GET("/:task_id/cves", bhaHandler.ListCVE).
 			GET("/:task_id/file/funcs", bhaHandler.ListFunc).
 			GET("/:task_id/file/func_results", bhaHandler.ListFuncResult).
+			GET("/:task_id/file/func_results/export", bhaHandler.ExportFuncResult).
+			PUT("/:task_id/file/func_results/triage", bhaHandler.BulkTriageFuncResult).
+			PUT("/:task_id/file/func_results/:id/triage", bhaHandler.TriageFuncResult).
 			GET("/:task_id/report", bhaHandler.GetReport)
 
+		// search
+		v1Router.GET("/bha/search", bhaHandler.Search)
+
+		// diff
+		v1Router.GET("/bha/diff", bhaHandler.Diff)
+
+		// suppression
+		v1Router.Group("/bha/suppressions").
+			GET("", bhaHandler.ListSuppression).
+			POST("", bhaHandler.CreateSuppression).
+			GET("/export", bhaHandler.ExportSuppression).
+			POST("/import", bhaHandler.ImportSuppression).
+			PUT("/:id", bhaHandler.UpdateSuppression).
+			DELETE("/:id", bhaHandler.DeleteSuppression)
+
 		// model
 		v1Router.Group("/bha/model").
 			POST("", bhaHandler.UploadModel).
@@ -77,6 +100,20 @@ func NewHttpHandler(kit *kit.Kit) http.Handler {
 			DELETE("/:model_id", bhaHandler.DeleteModel)
 	}
 
+	// sca
+	{
+		scaHandler := v1.NewSca(base)
+
+		v1Router.GET("/sca/task/:task_id/components", scaHandler.ListComponent)
+	}
+
+	// vulnerability
+	{
+		vulnHandler := v1.NewVulnerability(base)
+
+		v1Router.GET("/vulnerabilities/:cve", vulnHandler.Detail)
+	}
+
 	// 调试模式时
 	if kit.Config.DebugMode {
 		// pprof
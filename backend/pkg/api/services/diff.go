@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+
+	"bin-vul-inspector/app/kit"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/mongo"
+	"bin-vul-inspector/pkg/report"
+)
+
+type BhaDiff struct {
+	*kit.Kit
+}
+
+func NewBhaDiff(kit *kit.Kit) *BhaDiff {
+	return &BhaDiff{
+		Kit: kit,
+	}
+}
+
+// Diff 对比两个任务的漏洞匹配结果，unchanged 为 false 时不包含未变化的匹配，suppressed 为 false 时不包含被抑制的匹配
+func (svc *BhaDiff) Diff(ctx context.Context, base, head *models.Task, threshold float64, unchanged, suppressed bool) (*models.BhaDiff, error) {
+	files := mongo.NewBhaFile(svc.Mongo)
+	baseFiles, err := files.FindByTaskId(ctx, base.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	headFiles, err := files.FindByTaskId(ctx, head.TaskId)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := report.NewDiffBuilder(baseFiles, headFiles, threshold)
+	results := mongo.NewBhaFuncResult(svc.Mongo)
+	err = results.EachFinding(ctx, base.TaskId, suppressed, func(f *models.BhaFinding) error {
+		builder.AddBase(f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = results.EachFinding(ctx, head.TaskId, suppressed, func(f *models.BhaFinding) error {
+		builder.AddHead(f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.BhaDiff{
+		Base:      diffTask(base),
+		Head:      diffTask(head),
+		Threshold: threshold,
+	}
+	diff.Items, diff.Summary = builder.Build(unchanged)
+
+	cves := make([]string, 0, len(diff.Items))
+	for _, item := range diff.Items {
+		cves = append(cves, item.CVE)
+	}
+	vulns, err := NewVulnerability(svc.Kit).Lookup(ctx, cves)
+	if err != nil {
+		return nil, err
+	}
+	for i := range diff.Items {
+		diff.Items[i].Vuln = vulns[diff.Items[i].CVE]
+	}
+	return diff, nil
+}
+
+func diffTask(task *models.Task) models.BhaDiffTask {
+	return models.BhaDiffTask{
+		TaskId:    task.TaskId,
+		Name:      task.Name,
+		FileHash:  task.FileHash,
+		CreatedAt: task.CreatedAt,
+	}
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"archive/zip"
-	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
 	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -14,6 +16,7 @@ import (
 	"path/filepath"
 
 	"bin-vul-inspector/pkg/constant"
+	"bin-vul-inspector/pkg/models"
 	"bin-vul-inspector/pkg/utils"
 )
 
@@ -40,8 +43,13 @@ func WithSizeLimit(size int64) FormOption {
 
 type UploadFile struct {
 	Path string
-	Hash string
+	Hash string // SHA-256
+	SHA1 string
+	MD5  string
 	Size int64
+
+	Package string             // 安装包类型 apk, aab, aar, jar，不是安装包时为空
+	Binary  *models.BinaryInfo // 可执行文件的预分析结果，不是可执行文件时为空
 }
 
 func (svc *Form) UploadFile(req *http.Request, key, dir string) (*UploadFile, error) {
@@ -134,42 +142,28 @@ func (svc *Form) uploadFile(file multipart.File, fileHeader *multipart.FileHeade
 		}
 
 		var f *os.File
-		f, err = os.OpenFile(filepathAbs, os.O_RDWR|os.O_CREATE, 0644)
+		f, err = os.OpenFile(filepathAbs, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 		if err != nil {
 			return nil, fmt.Errorf("保存上传文件路径打开失败, %w", err)
 		}
 		defer func() { _ = f.Close() }()
 
-		if _, err = io.Copy(f, file); err != nil {
+		// 写入文件的同时计算hash及大小
+		sha256Hash, sha1Hash, md5Hash := sha256.New(), sha1.New(), md5.New()
+		r := io.TeeReader(file, io.MultiWriter(sha256Hash, sha1Hash, md5Hash))
+		if uploadFile.Size, err = io.Copy(f, r); err != nil {
 			return nil, fmt.Errorf("保存上传文件失败, %w", err)
 		}
+		uploadFile.Hash = hex.EncodeToString(sha256Hash.Sum(nil))
+		uploadFile.SHA1 = hex.EncodeToString(sha1Hash.Sum(nil))
+		uploadFile.MD5 = hex.EncodeToString(md5Hash.Sum(nil))
+
 		{
 			_, err = file.Seek(0, io.SeekStart)
 			if err != nil {
 				return nil, fmt.Errorf("重置文件指针错误, %w", err)
 			}
 		}
-
-		// 获取文件hash
-		{
-			r := bufio.NewReader(f)
-			hash := sha256.New()
-			_, err = io.Copy(hash, r)
-			if err != nil {
-				return nil, fmt.Errorf("计算上传文件SHA-256哈希失败, %w", err)
-			}
-			uploadFile.Hash = fmt.Sprintf("%x", hash.Sum(nil))
-		}
-
-		// 获取文件大小
-		{
-			var stat os.FileInfo
-			stat, err = f.Stat()
-			if err != nil {
-				return nil, fmt.Errorf("获取文件信息失败, %w", err)
-			}
-			uploadFile.Size = stat.Size()
-		}
 	}
 
 	return uploadFile, nil
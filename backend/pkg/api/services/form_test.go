@@ -0,0 +1,42 @@
+package services_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bin-vul-inspector/pkg/api/services"
+)
+
+func TestForm_UploadFile(t *testing.T) {
+	content := []byte("The quick brown fox jumps over the lazy dog")
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("upload_file", "fox.bin")
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	upload, err := services.NewForm().UploadFile(req, "upload_file", t.TempDir())
+	require.NoError(t, err)
+
+	assert.Equal(t, "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592", upload.Hash)
+	assert.Equal(t, "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12", upload.SHA1)
+	assert.Equal(t, "9e107d9d372bb6826bd81d3542a419d6", upload.MD5)
+	assert.Equal(t, int64(len(content)), upload.Size)
+
+	saved, err := os.ReadFile(upload.Path)
+	require.NoError(t, err)
+	assert.Equal(t, content, saved)
+}
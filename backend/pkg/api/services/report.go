@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"bin-vul-inspector/app/kit"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/mongo"
+	"bin-vul-inspector/pkg/report"
+)
+
+type BhaReport struct {
+	*kit.Kit
+}
+
+func NewBhaReport(kit *kit.Kit) *BhaReport {
+	return &BhaReport{
+		Kit: kit,
+	}
+}
+
+// vulns 任务匹配到的CVE的漏洞信息
+func (svc *BhaReport) vulns(ctx context.Context, taskId string) (report.Vulns, error) {
+	cves, err := mongo.NewBhaFuncResult(svc.Mongo).DistinctCVEs(ctx, taskId, true)
+	if err != nil {
+		return nil, err
+	}
+	return NewVulnerability(svc.Kit).Lookup(ctx, cves)
+}
+
+// SARIF 生成 SARIF 2.1.0 报告
+func (svc *BhaReport) SARIF(ctx context.Context, taskId, version string) (*report.SARIF, error) {
+	vulns, err := svc.vulns(ctx, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := report.NewSARIFBuilder(version, vulns)
+	err = mongo.NewBhaFuncResult(svc.Mongo).EachFinding(ctx, taskId, true, func(f *models.BhaFinding) error {
+		builder.Add(f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return builder.Build(), nil
+}
+
+// CycloneDX 生成 CycloneDX 1.5 SBOM 及 VEX
+func (svc *BhaReport) CycloneDX(ctx context.Context, task *models.Task, version string) (*report.CycloneDX, error) {
+	vulns, err := svc.vulns(ctx, task.TaskId)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := report.NewCycloneDXBuilder(version, task, vulns)
+
+	files, err := mongo.NewBhaFile(svc.Mongo).FindByTaskId(ctx, task.TaskId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		builder.AddFile(&files[i])
+	}
+
+	err = mongo.NewBhaFuncResult(svc.Mongo).EachFinding(ctx, task.TaskId, true, func(f *models.BhaFinding) error {
+		builder.Add(f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return builder.Build(), nil
+}
+
+// HTML 生成单文件HTML报告
+func (svc *BhaReport) HTML(ctx context.Context, task *models.Task, version string, w io.Writer) error {
+	vulns, err := svc.vulns(ctx, task.TaskId)
+	if err != nil {
+		return err
+	}
+
+	meta := report.HTMLMeta{
+		TaskId:    task.TaskId,
+		Name:      task.Name,
+		FileHash:  task.FileHash,
+		FileSize:  task.FileSize,
+		CreatedAt: task.CreatedAt,
+	}
+	if p := task.Detail.BhaParams; p != nil {
+		meta.DetectionMethod = p.DetectionMethod
+		meta.Algorithm = p.Algorithm
+		meta.TopN = p.TopN
+		meta.MinimumSim = float64(p.MinimumSim)
+		if p.ModelId != "" {
+			model, err := mongo.NewBhaModel(svc.Mongo).FindById(ctx, p.ModelId)
+			if err != nil {
+				return err
+			}
+			if model != nil {
+				meta.Model = model.Name
+			}
+		}
+	}
+
+	builder := report.NewHTMLBuilder(version, meta, vulns)
+
+	files, err := mongo.NewBhaFile(svc.Mongo).FindByTaskId(ctx, task.TaskId)
+	if err != nil {
+		return err
+	}
+	for i := range files {
+		builder.AddFile(&files[i])
+	}
+
+	err = mongo.NewBhaFuncResult(svc.Mongo).EachFuncMatches(ctx, task.TaskId, func(m *models.BhaFuncMatches) error {
+		builder.AddFunc(&m.Func, m.Results)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return builder.Render(w)
+}
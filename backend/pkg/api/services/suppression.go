@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"path"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"bin-vul-inspector/app/kit"
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/mongo"
+	"bin-vul-inspector/pkg/pointer"
+)
+
+type BhaSuppression struct {
+	*kit.Kit
+}
+
+func NewBhaSuppression(kit *kit.Kit) *BhaSuppression {
+	return &BhaSuppression{
+		Kit: kit,
+	}
+}
+
+// FromFuncResults 根据研判结果创建抑制规则，返回新增的规则数
+//
+// 规则限定在任务所属项目内，相似分数上限为研判时的分数，后续扫描中相似度更高的匹配仍会展示
+// 研判的结果同时标记命中的规则，列表与导出不再包含这些结果
+func (svc *BhaSuppression) FromFuncResults(ctx context.Context, task *models.Task, ids []primitive.ObjectID, req dto.BhaTriageReq) (int64, error) {
+	results, err := mongo.NewBhaFuncResult(svc.Mongo).FindByIds(ctx, task.TaskId, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	funcIds := make([]primitive.ObjectID, 0, len(results))
+	for _, r := range results {
+		funcIds = append(funcIds, mongo.ObjectID(r.FuncId))
+	}
+	funcs, err := mongo.NewBhaFunc(svc.Mongo).FindByIds(ctx, funcIds)
+	if err != nil {
+		return 0, err
+	}
+	funcMap := make(map[string]*models.BhaFunc, len(funcs))
+	for i := range funcs {
+		funcMap[funcs[i].Id.Hex()] = &funcs[i]
+	}
+
+	list := make([]models.BhaSuppression, 0, len(results))
+	for _, r := range results {
+		fn, ok := funcMap[r.FuncId]
+		if !ok || r.CVE == "" {
+			continue
+		}
+
+		rule := models.BhaSuppression{
+			FileName:      path.Base(fn.FilePath),
+			FName:         fn.FName,
+			CVE:           r.CVE,
+			MinSim:        0,
+			MaxSim:        r.Sim,
+			Project:       task.Name,
+			Status:        pointer.PAny(req.Status),
+			Justification: pointer.PAny(req.Justification),
+			Comment:       req.Comment,
+			Author:        req.Author,
+		}
+		// 无函数名时按地址匹配
+		if rule.FName == "" {
+			rule.Addr = models.NormalizeAddr(fn.Addr)
+		}
+		list = append(list, rule)
+	}
+
+	suppressions := mongo.NewBhaSuppression(svc.Mongo)
+	inserted, _, err := suppressions.Upsert(ctx, list)
+	if err != nil {
+		return 0, err
+	}
+
+	// 与扫描结果入库时一致，按规则匹配研判的结果并标记命中的规则
+	rules, err := suppressions.FindForTask(ctx, task.Name, task.FileHash)
+	if err != nil {
+		return 0, err
+	}
+	index := models.NewBhaSuppressionIndex(rules)
+	matched := make(map[string][]primitive.ObjectID)
+	for _, r := range results {
+		fn, ok := funcMap[r.FuncId]
+		if !ok {
+			continue
+		}
+		if rule := index.Match(fn.FilePath, fn.FName, fn.Addr, r.CVE, r.Sim); rule != nil {
+			matched[rule.Id.Hex()] = append(matched[rule.Id.Hex()], r.Id)
+		}
+	}
+	for suppressionId, ids := range matched {
+		if err = mongo.NewBhaFuncResult(svc.Mongo).SetSuppression(ctx, task.TaskId, ids, suppressionId); err != nil {
+			return 0, err
+		}
+	}
+	return inserted, nil
+}
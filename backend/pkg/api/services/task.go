@@ -21,7 +21,9 @@ import (
 	"bin-vul-inspector/pkg/models"
 	"bin-vul-inspector/pkg/mongo"
 	"bin-vul-inspector/pkg/pointer"
+	"bin-vul-inspector/pkg/unpack"
 	"bin-vul-inspector/pkg/utils"
+	"bin-vul-inspector/pkg/utils/archive"
 )
 
 type Task struct {
@@ -80,8 +82,12 @@ func (svc *Task) Create(ctx context.Context, params dto.TaskCreateReq) (err erro
 		switch task.Mode {
 		case models.TaskModeUpload:
 			task.FileHash = params.FileHash
+			task.FileSHA1 = params.FileSHA1
+			task.FileMD5 = params.FileMD5
 			task.FileSize = params.FileSize
 			task.FilePath = params.FilePath
+			task.Package = params.Package
+			task.Binary = params.Binary
 		}
 
 		tasks = append(tasks, task)
@@ -130,6 +136,9 @@ func (svc *Task) ClearTasks(ctx context.Context, tasks []models.Task) (err error
 	if err = svc.DeleteGenerated(ctx, taskIds); err != nil {
 		return err
 	}
+	if err = mongo.NewTaskFile(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
+		return fmt.Errorf("delete task_files error, %w", err)
+	}
 
 	// 删除任务文件
 	uniqueTaskIds := utils.UniqueSlice(taskIds)
@@ -150,9 +159,6 @@ func (svc *Task) DeleteGenerated(ctx context.Context, taskIds []string) (err err
 	// if err = mongo.NewScaProduct(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
 	// 	return fmt.Errorf("delete sca_products error, %w", err)
 	// }
-	// if err = mongo.NewScaComponent(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
-	// 	return fmt.Errorf("delete sca_components error, %w", err)
-	// }
 	// if err = mongo.NewScaFix(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
 	// 	return fmt.Errorf("delete sca_fixes error, %w", err)
 	// }
@@ -170,16 +176,31 @@ func (svc *Task) DeleteGenerated(ctx context.Context, taskIds []string) (err err
 	// 	return fmt.Errorf("delete sast_vulnerabilities error, %w", err)
 	// }
 
-	// bha
-	{
-		if err = mongo.NewBhaFuncResult(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
-			return fmt.Errorf("delete bha_func_results error, %w", err)
-		}
-		if err = mongo.NewBhaFunc(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
-			return fmt.Errorf("delete bha_funcs error, %w", err)
-		}
+	if err = svc.DeleteScaGenerated(ctx, taskIds); err != nil {
+		return err
 	}
+	return svc.DeleteBhaGenerated(ctx, taskIds)
+}
+
+// DeleteScaGenerated 删除 sca 扫描结果
+func (svc *Task) DeleteScaGenerated(ctx context.Context, taskIds []string) error {
+	if err := mongo.NewScaComponent(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
+		return fmt.Errorf("delete sca_components error, %w", err)
+	}
+	return nil
+}
 
+// DeleteBhaGenerated 删除 bha 扫描结果，同一任务的 sca 结果不受影响
+func (svc *Task) DeleteBhaGenerated(ctx context.Context, taskIds []string) (err error) {
+	if err = mongo.NewBhaFuncResult(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
+		return fmt.Errorf("delete bha_func_results error, %w", err)
+	}
+	if err = mongo.NewBhaFunc(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
+		return fmt.Errorf("delete bha_funcs error, %w", err)
+	}
+	if err = mongo.NewBhaFile(svc.Mongo).DeleteByTaskIds(ctx, taskIds); err != nil {
+		return fmt.Errorf("delete bha_files error, %w", err)
+	}
 	return nil
 }
 
@@ -247,6 +268,11 @@ func (svc *Task) BhaResultFile(ctx context.Context, taskId string) (string, func
 	return svc.getFile(ctx, filePath)
 }
 
+func (svc *Task) ScaResultFile(ctx context.Context, taskId string) (string, func(), error) {
+	filePath := filepath.Join(constant.TaskScaResultPath(taskId), constant.ScaResultFile)
+	return svc.getFile(ctx, filePath)
+}
+
 func (svc *Task) GetLogFile(ctx context.Context, taskId, taskType string) (string, func(), error) {
 	filePath := filepath.Join(
 		constant.TaskPath(taskId),
@@ -266,11 +292,71 @@ func (svc *Task) GetAsmFile(ctx context.Context, taskId, taskType string) (strin
 }
 
 func (svc *Task) GetSourcePackage(req *http.Request, params *dto.TaskCreateReq, dir string) (uploadFile *UploadFile, err error) {
-	if params.Source == models.TaskSourceWeb {
-		return NewForm().UploadFile(req, "upload_file", dir)
+	if params.Source != models.TaskSourceWeb {
+		return nil, NewError(dto.StatusSaveFileErr, fmt.Sprintf("此来源(%s)无法获取文件压缩包", params.Source))
+	}
+
+	if uploadFile, err = NewForm().UploadFile(req, "upload_file", dir); err != nil {
+		return nil, err
 	}
 
-	return nil, NewError(dto.StatusSaveFileErr, fmt.Sprintf("此来源(%s)无法获取文件压缩包", params.Source))
+	// 识别 APK、AAB、AAR、JAR 等安装包，扫描时仅提取其中各 ABI 的 native 库
+	if kind, err := archive.Identify(uploadFile.Path); err == nil && kind == archive.Zip {
+		if uploadFile.Package, err = unpack.Package(uploadFile.Path); err != nil {
+			return nil, fmt.Errorf("读取安装包错误, %w", err)
+		}
+	}
+	return uploadFile, nil
+}
+
+// uploadSniffSize 创建任务时查找固件镜像文件系统头的范围，完整的解包及分析在扫描时进行
+const uploadSniffSize = 16 << 20
+
+// CheckUpload 在创建任务时按文件头识别上传文件，记录可执行文件的格式、架构等信息
+// 不是可执行文件、压缩包、安装包或固件镜像的文件直接拒绝，bha 扫描时还拒绝不支持的架构，避免扫描时才失败
+// 仅读取文件头，符号表、依赖库等由扫描任务解析
+func (svc *Task) CheckUpload(params *dto.TaskCreateReq, uploadFile *UploadFile) error {
+	isBha := utils.Contains(params.Types, constant.TypeBha)
+	isSca := utils.Contains(params.Types, constant.TypeSca)
+	if !isBha && !isSca {
+		return nil
+	}
+
+	kind, err := archive.Identify(uploadFile.Path)
+	if err != nil {
+		return fmt.Errorf("识别上传文件类型错误, %w", err)
+	}
+	if (isBha && params.Bha.Input == bha.ContainerInput) || (isSca && params.Sca.Input == bha.ContainerInput) {
+		if !unpack.IsTar(kind) {
+			return NewError(dto.StatusUnsupportedFile, "容器镜像必须为 docker save 或 OCI 镜像布局的 tar 包")
+		}
+		return nil
+	}
+	if kind != "" {
+		return nil
+	}
+
+	info, err := unpack.Sniff(uploadFile.Path)
+	if errors.Is(err, unpack.ErrNotBinary) {
+		image, err := unpack.DetectImage(uploadFile.Path, uploadSniffSize)
+		if err != nil {
+			return fmt.Errorf("识别上传文件类型错误, %w", err)
+		}
+		if image == "" {
+			return NewError(dto.StatusUnsupportedFile, "上传文件不是可执行文件(ELF、PE、Mach-O)、压缩包或固件镜像")
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("识别上传文件类型错误, %w", err)
+	}
+
+	// 组件识别不依赖 bha server，不限制架构
+	if arches := svc.Config.Bha.GetArches(); isBha && !utils.Contains(arches, info.Arch) {
+		return NewError(dto.StatusUnsupportedFile, fmt.Sprintf("不支持的架构 %s, 仅支持 %s", info.Arch, arches))
+	}
+	uploadFile.Binary = info
+	return nil
 }
 
 func (svc *Task) TerminateTask(ctx context.Context, taskId string) error {
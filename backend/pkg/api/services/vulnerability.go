@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"bin-vul-inspector/app/kit"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/mongo"
+	"bin-vul-inspector/pkg/utils"
+	"bin-vul-inspector/pkg/vulndb"
+)
+
+const vulnImportBatchSize = 1000
+
+type Vulnerability struct {
+	*kit.Kit
+}
+
+func NewVulnerability(kit *kit.Kit) *Vulnerability {
+	return &Vulnerability{
+		Kit: kit,
+	}
+}
+
+// VulnImportOption 离线漏洞库导入文件
+type VulnImportOption struct {
+	NVD  []string // NVD JSON 2.0 数据源文件，支持gzip压缩
+	KEV  string   // CISA KEV CSV
+	EPSS string   // EPSS CSV，支持gzip压缩
+}
+
+// VulnImportStat 导入统计
+type VulnImportStat struct {
+	NVD  int
+	KEV  int
+	EPSS int
+}
+
+// Import 从本地文件导入离线漏洞库，重复导入时更新已有数据
+func (svc *Vulnerability) Import(ctx context.Context, option VulnImportOption) (stat VulnImportStat, err error) {
+	collection := mongo.NewVulnerability(svc.Mongo)
+
+	for _, path := range option.NVD {
+		var n int
+		n, err = importFile(path, vulndb.ParseNVD, func(v *models.Vulnerability) models.Vulnerability { return *v },
+			func(batch []models.Vulnerability) error { return collection.UpsertNVD(ctx, batch) })
+		if err != nil {
+			return stat, fmt.Errorf("import nvd feed %s error, %w", path, err)
+		}
+		stat.NVD += n
+		svc.Logger.Infof("imported %d vulnerabilities from %s", n, path)
+	}
+
+	if option.KEV != "" {
+		// KEV目录需整体写入，以便取消已移出目录的漏洞的标记
+		var kevs []models.VulnKEV
+		if err = parseFile(option.KEV, vulndb.ParseKEV, func(v models.VulnKEV) error {
+			kevs = append(kevs, v)
+			return nil
+		}); err != nil {
+			return stat, fmt.Errorf("import kev %s error, %w", option.KEV, err)
+		}
+		if err = collection.UpdateKEV(ctx, kevs); err != nil {
+			return stat, fmt.Errorf("import kev %s error, %w", option.KEV, err)
+		}
+		stat.KEV = len(kevs)
+		svc.Logger.Infof("imported %d kev entries from %s", stat.KEV, option.KEV)
+	}
+
+	if option.EPSS != "" {
+		stat.EPSS, err = importFile(option.EPSS, vulndb.ParseEPSS, func(v models.VulnEPSS) models.VulnEPSS { return v },
+			func(batch []models.VulnEPSS) error { return collection.UpdateEPSS(ctx, batch) })
+		if err != nil {
+			return stat, fmt.Errorf("import epss %s error, %w", option.EPSS, err)
+		}
+		svc.Logger.Infof("imported %d epss scores from %s", stat.EPSS, option.EPSS)
+	}
+
+	return stat, nil
+}
+
+// parseFile 打开数据源文件并解析
+func parseFile[T any](path string, parse func(io.Reader, func(T) error) error, handler func(T) error) error {
+	f, err := vulndb.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	return parse(f, handler)
+}
+
+// importFile 解析数据源文件并分批写入
+func importFile[T, D any](path string, parse func(io.Reader, func(T) error) error, convert func(T) D, write func([]D) error) (int, error) {
+	var total int
+	batch := make([]D, 0, vulnImportBatchSize)
+	err := parseFile(path, parse, func(v T) error {
+		batch = append(batch, convert(v))
+		total++
+		if len(batch) < vulnImportBatchSize {
+			return nil
+		}
+		err := write(batch)
+		batch = batch[:0]
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(batch) > 0 {
+		if err = write(batch); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// Lookup 查询漏洞信息 cve -> vulnerability，漏洞库中不存在的CVE不包含在结果中
+func (svc *Vulnerability) Lookup(ctx context.Context, cves []string) (map[string]*models.Vulnerability, error) {
+	result := make(map[string]*models.Vulnerability)
+
+	cves = utils.UniqueSlice(cves)
+	if len(cves) == 0 {
+		return result, nil
+	}
+
+	list, err := mongo.NewVulnerability(svc.Mongo).FindByCVEs(ctx, cves)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		result[list[i].CVE] = &list[i]
+	}
+	return result, nil
+}
+
+// EnrichFuncResults 补充函数相似性对比结果的漏洞信息
+func (svc *Vulnerability) EnrichFuncResults(ctx context.Context, list []models.BhaFuncResult) error {
+	cves := make([]string, 0, len(list))
+	for i := range list {
+		if list[i].CVE != "" {
+			cves = append(cves, list[i].CVE)
+		}
+	}
+
+	vulns, err := svc.Lookup(ctx, cves)
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		list[i].Vuln = vulns[list[i].CVE]
+	}
+	return nil
+}
+
+// EnrichSearchHits 补充跨任务检索结果的漏洞信息
+func (svc *Vulnerability) EnrichSearchHits(ctx context.Context, list []models.BhaSearchHit) error {
+	cves := make([]string, 0, len(list))
+	for i := range list {
+		if list[i].CVE != "" {
+			cves = append(cves, list[i].CVE)
+		}
+	}
+
+	vulns, err := svc.Lookup(ctx, cves)
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		list[i].Vuln = vulns[list[i].CVE]
+	}
+	return nil
+}
+
+// TaskSummary 统计任务匹配到的CVE的严重等级
+func (svc *Vulnerability) TaskSummary(ctx context.Context, taskId string) (*models.VulnSummary, error) {
+	cves, err := mongo.NewBhaFuncResult(svc.Mongo).DistinctCVEs(ctx, taskId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	vulns, err := svc.Lookup(ctx, cves)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.VulnSummary{}
+	for _, cve := range cves {
+		summary.Add(vulns[cve])
+	}
+	return summary, nil
+}
@@ -1,7 +1,12 @@
 package v1
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -12,6 +17,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"bin-vul-inspector/cmd/version"
 	"bin-vul-inspector/pkg/api/services"
 	"bin-vul-inspector/pkg/api/v1/dto"
 	"bin-vul-inspector/pkg/bha"
@@ -20,6 +26,8 @@ import (
 	"bin-vul-inspector/pkg/models"
 	"bin-vul-inspector/pkg/mongo"
 	"bin-vul-inspector/pkg/pointer"
+	"bin-vul-inspector/pkg/report"
+	"bin-vul-inspector/pkg/unpack"
 	"bin-vul-inspector/pkg/utils"
 	"bin-vul-inspector/pkg/utils/archive"
 )
@@ -34,16 +42,100 @@ func NewBha(base *Base) *Bha {
 	}
 }
 
-// ListFunc
+// ListFile
 //
 //	@tags		BhaTask
-//	@summary	func 检测文件函数列表
-//	@router		/bha/task/{task_id}/file/funcs [get]
+//	@summary	file 检测文件列表
+//	@router		/bha/task/{task_id}/files [get]
 //	@Param		task_id		path		string	true	"task_id"
 //	@Param		page		query		int		true	"页码"	minimum(1)	default(1)
 //	@Param		page_size	query		int		true	"页大小"	minimum(1)	default(20)
-//	@Param		q			query		string	false	"关键字查询"
-//	@success	200			{object}	dto.Response{data=dto.ListResponse[models.BhaFunc]}
+//	@Param		q			query		string	false	"关键字查询, 文件路径"
+//	@Param		abi			query		string	false	"安装包中 native 库的 ABI"
+//	@success	200			{object}	dto.Response{data=dto.ListResponse[models.BhaFile]}
+func (h *Bha) ListFile(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaFileListReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusParamInvalid)
+			return
+		}
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	// 查询
+	total, list, err := mongo.NewBhaFile(h.Mongo).ListFile(ctx, params)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.Success(ctx, dto.ListResponse[models.BhaFile]{
+		Count: total,
+		List:  utils.NotNull(list),
+	})
+}
+
+// ListAbi
+//
+//	@tags			BhaTask
+//	@summary		abi 安装包各 ABI 的扫描结果统计
+//	@description	上传 APK、AAB、AAR、JAR 等安装包时按 ABI 分组统计 native 库的扫描结果，其他任务为空
+//	@router			/bha/task/{task_id}/abis [get]
+//	@Param			task_id	path		string	true	"task_id"
+//	@success		200		{object}	dto.Response{data=[]models.BhaAbiSummary}
+func (h *Bha) ListAbi(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaAbiListReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusParamInvalid)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	list, err := mongo.NewBhaFile(h.Mongo).AbiSummary(ctx, params.TaskId)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	for i := range list {
+		list[i].Arch = unpack.AbiArch(list[i].Abi)
+	}
+
+	h.Success(ctx, utils.NotNull(list))
+}
+
+// ListFunc
+//
+//	@tags		BhaTask
+//	@summary	func 检测文件函数列表
+//	@router		/bha/task/{task_id}/file/funcs [get]
+//	@Param		task_id			path		string	true	"task_id"
+//	@Param		page			query		int		true	"页码"	minimum(1)	default(1)
+//	@Param		page_size		query		int		true	"页大小"	minimum(1)	default(20)
+//	@Param		file_id			query		string	false	"文件 id"
+//	@Param		q				query		string	false	"关键字查询"
+//	@Param		triage_status	query		string	false	"存在该研判状态的匹配结果"	Enums(untriaged,confirmed,false_positive,wont_fix)
+//	@Param		assignee		query		string	false	"存在该处理人的匹配结果"
+//	@Param		suppressed		query		bool	false	"研判条件是否包含被抑制规则命中的结果"
+//	@success	200				{object}	dto.Response{data=dto.ListResponse[models.BhaFunc]}
 func (h *Bha) ListFunc(ctx *gin.Context) {
 	var err error
 
@@ -82,13 +174,16 @@ func (h *Bha) ListFunc(ctx *gin.Context) {
 //	@tags		BhaTask
 //	@summary	func result 函数相似性对比结果
 //	@router		/bha/task/{task_id}/file/func_results [get]
-//	@Param		task_id		path		string	true	"task_id"
-//	@Param		page		query		int		true	"页码"	minimum(1)	default(1)
-//	@Param		page_size	query		int		true	"页大小"	minimum(1)	default(20)
-//	@Param		func_id		query		string	true	"func id"
-//	@Param		top_n		query		string	false	"topN"
-//	@Param		q			query		string	false	"关键字查询"
-//	@success	200			{object}	dto.Response{data=dto.ListResponse[models.BhaFunc]}
+//	@Param		task_id			path		string	true	"task_id"
+//	@Param		page			query		int		true	"页码"	minimum(1)	default(1)
+//	@Param		page_size		query		int		true	"页大小"	minimum(1)	default(20)
+//	@Param		func_id			query		string	true	"func id"
+//	@Param		top_n			query		string	false	"topN"
+//	@Param		q				query		string	false	"关键字查询"
+//	@Param		triage_status	query		string	false	"研判状态"	Enums(untriaged,confirmed,false_positive,wont_fix)
+//	@Param		assignee		query		string	false	"处理人"
+//	@Param		suppressed		query		bool	false	"是否包含被抑制规则命中的结果"
+//	@success	200				{object}	dto.Response{data=dto.ListResponse[models.BhaFuncResult]}
 func (h *Bha) ListFuncResult(ctx *gin.Context) {
 	var err error
 
@@ -115,6 +210,10 @@ func (h *Bha) ListFuncResult(ctx *gin.Context) {
 		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
 		return
 	}
+	if err = services.NewVulnerability(h.Kit).EnrichFuncResults(ctx, list); err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
 
 	h.Success(ctx, dto.ListResponse[models.BhaFuncResult]{
 		Count: total,
@@ -122,26 +221,427 @@ func (h *Bha) ListFuncResult(ctx *gin.Context) {
 	})
 }
 
+// TriageFuncResult 研判函数相似性对比结果
+//
+//	@tags			BhaTask
+//	@summary		研判函数相似性对比结果
+//	@router			/bha/task/{task_id}/file/func_results/{id}/triage [put]
+//	@description	仅更新传入的字段，comment 非空时追加评论，suppress 为 true 时同时创建抑制规则
+//	@accept			application/json
+//	@produce		application/json
+//	@Param			task_id	path		string				true	"task_id"
+//	@Param			id		path		string				true	"func result id"
+//	@Param			body	body		dto.BhaTriageReq	true	"研判信息"
+//	@success		200		{object}	dto.Response{data=models.BhaFuncResult}
+func (h *Bha) TriageFuncResult(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaFuncResultTriageReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusParamInvalid)
+			return
+		}
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	id, err := mongo.ObjectIDWithError(params.Id)
+	if err != nil {
+		h.Fail(ctx, dto.StatusDataNotFound)
+		return
+	}
+
+	results := mongo.NewBhaFuncResult(h.Mongo)
+	matched, err := results.UpdateTriage(ctx, params.TaskId, []primitive.ObjectID{id}, params.BhaTriageReq)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	if matched == 0 {
+		h.Fail(ctx, dto.StatusDataNotFound)
+		return
+	}
+	if params.Suppress {
+		if _, err = h.suppress(ctx, params.TaskId, []primitive.ObjectID{id}, params.BhaTriageReq); err != nil {
+			h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+			return
+		}
+	}
+
+	result, err := results.FindById(ctx, params.TaskId, params.Id)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	h.Success(ctx, result)
+}
+
+// BulkTriageFuncResult 批量研判函数相似性对比结果
+//
+//	@tags			BhaTask
+//	@summary		批量研判函数相似性对比结果
+//	@router			/bha/task/{task_id}/file/func_results/triage [put]
+//	@description	仅更新传入的字段，comment 非空时为每个结果追加评论，suppress 为 true 时同时创建抑制规则，单次最多1000个
+//	@accept			application/json
+//	@produce		application/json
+//	@Param			task_id	path		string							true	"task_id"
+//	@Param			body	body		dto.BhaFuncResultBulkTriageReq	true	"研判信息"
+//	@success		200		{object}	dto.Response{data=dto.BhaTriageRes}
+func (h *Bha) BulkTriageFuncResult(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaFuncResultBulkTriageReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusParamInvalid)
+			return
+		}
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(params.Ids))
+	for _, v := range params.Ids {
+		id, err := mongo.ObjectIDWithError(v)
+		if err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, fmt.Sprintf("id %s 无效", v))
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	matched, err := mongo.NewBhaFuncResult(h.Mongo).UpdateTriage(ctx, params.TaskId, ids, params.BhaTriageReq)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	res := dto.BhaTriageRes{Matched: matched}
+	if params.Suppress && matched > 0 {
+		if res.Suppressed, err = h.suppress(ctx, params.TaskId, ids, params.BhaTriageReq); err != nil {
+			h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+			return
+		}
+	}
+	h.Success(ctx, res)
+}
+
+// suppress 根据研判结果创建抑制规则
+func (h *Bha) suppress(ctx *gin.Context, taskId string, ids []primitive.ObjectID, req dto.BhaTriageReq) (int64, error) {
+	task, err := mongo.NewTask(h.Mongo).GetBhaTask(ctx, taskId)
+	if err != nil || task == nil {
+		return 0, err
+	}
+	return services.NewBhaSuppression(h.Kit).FromFuncResults(ctx, task, ids, req)
+}
+
+// ExportFuncResult 导出函数相似性对比结果
+//
+//	@tags			BhaTask
+//	@summary		导出函数相似性对比结果
+//	@router			/bha/task/{task_id}/file/func_results/export [get]
+//	@description	按函数导出全部匹配结果，每个匹配结果一行，包含函数及文件信息
+//	@description	- csv: 首行为列名，refs以空格分隔，无匹配结果的函数rank及sim为空
+//	@description	- ndjson: 每行一个json对象
+//	@produce		text/csv,application/x-ndjson
+//	@Param			task_id		path	string	true	"task_id"
+//	@Param			format		query	string	false	"导出格式"	Enums(csv,ndjson)	default(csv)
+//	@Param			top_n		query	int		false	"每个函数导出的匹配结果数"	maximum(500)
+//	@Param			q			query	string	false	"关键字查询, 匹配函数名称"
+//	@Param			min_sim		query	number	false	"最小相似分数"	minimum(0)	maximum(1)
+//	@Param			unmatched	query	bool	false	"是否导出无匹配结果的函数"
+//	@Param			suppressed	query	bool	false	"是否导出被抑制规则命中的结果"
+//	@success		200			{file}	file
+func (h *Bha) ExportFuncResult(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaFuncResultExportReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusTaskIdInvalid)
+			return
+		}
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	// 校验任务是否存在
+	task, err := mongo.NewTask(h.Mongo).GetBhaTask(ctx, params.TaskId)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	if task == nil {
+		h.Fail(ctx, dto.StatusDataNotFound)
+		return
+	}
+
+	w := bufio.NewWriterSize(ctx.Writer, 32*1024)
+	var (
+		rows        report.RowWriter
+		contentType string
+	)
+	switch params.Format {
+	case dto.BhaExportFormatNDJSON:
+		rows, contentType = report.NewNDJSONRowWriter(w), constant.MineTypeNDJson
+	default:
+		rows, contentType = report.NewCSVRowWriter(w), constant.MineTypeCsv
+	}
+
+	filename := fmt.Sprintf("bin-vul-inspector-%s-func-results.%s", task.TaskId, params.Format)
+	ctx.Header(constant.HeaderDisposition, fmt.Sprintf("attachment; filename=%s", url.QueryEscape(filename)))
+	ctx.Header(constant.HeaderContentType, contentType)
+	ctx.Status(http.StatusOK)
+
+	// 响应已开始，出错时只能中断输出
+	err = mongo.NewBhaFuncResult(h.Mongo).EachFuncRow(ctx, params, rows.Write)
+	if err == nil {
+		err = rows.Flush()
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		h.Logger.Errorf("export %s error, %v", filename, err)
+	}
+}
+
+// ListCVE
+//
+//	@tags			BhaTask
+//	@summary		cve 按CVE聚合的检测结果
+//	@description	按 CVE/Purl/Version 聚合函数相似性对比结果，并补充漏洞库中的漏洞信息
+//	@router			/bha/task/{task_id}/cves [get]
+//	@Param			task_id		path		string	true	"task_id"
+//	@Param			page		query		int		true	"页码"	minimum(1)	default(1)
+//	@Param			page_size	query		int		true	"页大小"	minimum(1)	default(20)
+//	@Param			q			query		string	false	"关键字查询, CVE编号或purl"
+//	@Param			sort_by		query		string	false	"排序方式"	Enums(sim,severity,epss)	default(sim)
+//	@Param			suppressed	query		bool	false	"是否包含被抑制规则命中的结果"
+//	@success		200			{object}	dto.Response{data=dto.ListResponse[models.BhaCVE]}
+func (h *Bha) ListCVE(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaCVEListReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusParamInvalid)
+			return
+		}
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	// 查询
+	total, list, err := mongo.NewBhaFuncResult(h.Mongo).ListCVE(ctx, params)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.Success(ctx, dto.ListResponse[models.BhaCVE]{
+		Count: total,
+		List:  utils.NotNull(list),
+	})
+}
+
+// Search
+//
+//	@tags			BhaTask
+//	@summary		search 跨任务检索检测结果
+//	@description	按 CVE、purl、版本、函数名称、相似分数范围检索所有任务的函数相似性对比结果
+//	@description	cve、purl、fname 至少填写一个
+//	@router			/bha/search [get]
+//	@Param			page		query		int		true	"页码"	minimum(1)	default(1)
+//	@Param			page_size	query		int		true	"页大小"	minimum(1)	default(20)
+//	@Param			cve			query		string	false	"CVE编号"
+//	@Param			purl		query		string	false	"purl, 前缀匹配"
+//	@Param			version		query		string	false	"版本"
+//	@Param			fname		query		string	false	"函数名称, 匹配检测文件函数或匹配文件函数"
+//	@Param			min_sim		query		number	false	"最小相似分数"
+//	@Param			max_sim		query		number	false	"最大相似分数"
+//	@Param			suppressed	query		bool	false	"是否包含被抑制规则命中的结果"
+//	@success		200			{object}	dto.Response{data=dto.ListResponse[models.BhaSearchHit]}
+func (h *Bha) Search(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaSearchReq
+	{
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	// 查询
+	total, list, err := mongo.NewBhaFuncResult(h.Mongo).Search(ctx, params)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	if err = services.NewVulnerability(h.Kit).EnrichSearchHits(ctx, list); err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.Success(ctx, dto.ListResponse[models.BhaSearchHit]{
+		Count: total,
+		List:  utils.NotNull(list),
+	})
+}
+
+// Diff 对比两个任务
+//
+//	@tags			BhaTask
+//	@summary		对比两个任务的漏洞匹配结果
+//	@router			/bha/diff [get]
+//	@description	对比同一固件不同版本的扫描结果，文件按路径或唯一的文件名匹配，函数按函数名或地址匹配
+//	@description	- fixed: 基准任务中存在，对比任务中消失，可能已修复
+//	@description	- introduced: 对比任务中新增
+//	@description	- changed: 相似分数变化超过阈值
+//	@description	- unchanged: 相似分数变化未超过阈值，unchanged=true 时返回
+//	@description	format 为 sarif 或 html 时以附件形式返回，sarif 以 baselineState 标记变化
+//	@produce		application/json
+//	@Param			base		query		string	true	"基准任务id"
+//	@Param			head		query		string	true	"对比任务id"
+//	@Param			threshold	query		number	false	"相似分数变化阈值"	minimum(0)	maximum(1)	default(0.05)
+//	@Param			unchanged	query		bool	false	"是否包含未变化的匹配"
+//	@Param			suppressed	query		bool	false	"是否包含被抑制规则命中的匹配"
+//	@Param			format		query		string	false	"结果格式"	Enums(json,sarif,html)	default(json)
+//	@success		200			{object}	dto.Response{data=models.BhaDiff}
+func (h *Bha) Diff(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaDiffReq
+	{
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	tasks := make([]*models.Task, 0, 2)
+	for _, taskId := range []string{params.Base, params.Head} {
+		task, err := mongo.NewTask(h.Mongo).GetBhaTask(ctx, taskId)
+		if err != nil {
+			h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+			return
+		}
+		if task == nil {
+			h.FailMsg(ctx, dto.StatusDataNotFound, fmt.Sprintf("任务 %s 不存在", taskId))
+			return
+		}
+		if task.Status != models.TaskStatusFinished {
+			h.FailMsg(ctx, dto.StatusParamInvalid, fmt.Sprintf("任务 %s 未完成", taskId))
+			return
+		}
+		tasks = append(tasks, task)
+	}
+	base, head := tasks[0], tasks[1]
+
+	diff, err := services.NewBhaDiff(h.Kit).Diff(ctx, base, head, pointer.PAny(params.Threshold), params.Unchanged, params.Suppressed)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("bin-vul-inspector-%s-%s", base.TaskId, head.TaskId)
+	switch params.Format {
+	case dto.BhaDiffFormatSARIF:
+		vulns := make(report.Vulns)
+		builder := report.NewSARIFBuilder(version.Version, vulns)
+		for i := range diff.Items {
+			item := &diff.Items[i]
+			if item.Vuln != nil {
+				vulns[item.CVE] = item.Vuln
+			}
+			builder.AddDiff(item)
+		}
+		h.jsonReport(ctx, filename+".sarif", constant.MineTypeSarifJson, builder.Build())
+	case dto.BhaDiffFormatHTML:
+		h.htmlFile(ctx, filename+".html", func(w io.Writer) error {
+			return report.RenderDiffHTML(w, version.Version, diff)
+		})
+	default:
+		h.Success(ctx, diff)
+	}
+}
+
 // GetReport 获取报告
 //
 //	@tags			BhaTask
 //	@summary		获取报告
 //	@router			/bha/task/{task_id}/report [get]
 //	@description	获取报告
+//	@description	- zip: 原始扫描结果 bha-result.json 压缩包
+//	@description	- sarif: SARIF 2.1.0，每个检测文件函数与CVE的匹配为一条结果
+//	@description	- cyclonedx: CycloneDX 1.5 SBOM及VEX，根据匹配结果推断每个检测文件内嵌的组件
+//	@description	- html: 单文件HTML报告，包含任务信息、漏洞统计、文件及函数匹配明细，可离线查看
 //	@produce		application/octet-stream
 //	@param			task_id	path	string	true	"task_id"
+//	@param			format	query	string	false	"报告格式"	Enums(zip,sarif,cyclonedx,html)	default(zip)
 //	@success		200		{file}	file
 func (h *Bha) GetReport(ctx *gin.Context) {
 	var err error
 
-	taskId := ctx.Param("task_id")
-	if taskId == "" {
-		h.Fail(ctx, dto.StatusTaskIdInvalid)
-		return
+	var params dto.BhaReportReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusTaskIdInvalid)
+			return
+		}
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
 	}
 
 	// 校验任务是否存在
-	task, err := mongo.NewTask(h.Mongo).GetBhaTask(ctx, taskId)
+	task, err := mongo.NewTask(h.Mongo).GetBhaTask(ctx, params.TaskId)
 	if err != nil {
 		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
 		return
@@ -151,6 +651,20 @@ func (h *Bha) GetReport(ctx *gin.Context) {
 		return
 	}
 
+	switch params.Format {
+	case dto.BhaReportFormatSARIF:
+		h.sarifReport(ctx, task)
+	case dto.BhaReportFormatCycloneDX:
+		h.cycloneDXReport(ctx, task)
+	case dto.BhaReportFormatHTML:
+		h.htmlReport(ctx, task)
+	default:
+		h.zipReport(ctx, task)
+	}
+}
+
+// zipReport 原始扫描结果压缩包
+func (h *Bha) zipReport(ctx *gin.Context, task *models.Task) {
 	file, remove, err := services.NewTask(h.Kit).BhaResultFile(ctx, task.TaskId)
 	if err != nil {
 		h.FailMsg(ctx, dto.StatusInternalError, err.Error())
@@ -182,6 +696,57 @@ func (h *Bha) GetReport(ctx *gin.Context) {
 	h.File(ctx, exportPath)
 }
 
+// sarifReport SARIF 2.1.0 报告
+func (h *Bha) sarifReport(ctx *gin.Context, task *models.Task) {
+	sarif, err := services.NewBhaReport(h.Kit).SARIF(ctx, task.TaskId, version.Version)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.jsonReport(ctx, fmt.Sprintf("bin-vul-inspector-%s.sarif", task.TaskId), constant.MineTypeSarifJson, sarif)
+}
+
+// cycloneDXReport CycloneDX 1.5 SBOM及VEX
+func (h *Bha) cycloneDXReport(ctx *gin.Context, task *models.Task) {
+	bom, err := services.NewBhaReport(h.Kit).CycloneDX(ctx, task, version.Version)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.jsonReport(ctx, fmt.Sprintf("bin-vul-inspector-%s.cdx.json", task.TaskId), constant.MineTypeCycloneDXJson, bom)
+}
+
+// htmlReport 单文件HTML报告
+func (h *Bha) htmlReport(ctx *gin.Context, task *models.Task) {
+	h.htmlFile(ctx, fmt.Sprintf("bin-vul-inspector-%s.html", task.TaskId), func(w io.Writer) error {
+		return services.NewBhaReport(h.Kit).HTML(ctx, task, version.Version, w)
+	})
+}
+
+// htmlFile 以附件形式返回HTML报告，生成完成后再输出，以便返回生成过程中的错误
+func (h *Bha) htmlFile(ctx *gin.Context, filename string, render func(w io.Writer) error) {
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	ctx.Header(constant.HeaderDisposition, fmt.Sprintf("attachment; filename=%s", url.QueryEscape(filename)))
+	ctx.Data(http.StatusOK, constant.MineTypeHtml, buf.Bytes())
+}
+
+// jsonReport 以附件形式返回json报告
+func (h *Bha) jsonReport(ctx *gin.Context, filename, contentType string, data any) {
+	ctx.Header(constant.HeaderDisposition, fmt.Sprintf("attachment; filename=%s", url.QueryEscape(filename)))
+	ctx.Header(constant.HeaderContentType, contentType)
+	ctx.Status(http.StatusOK)
+	if err := json.NewEncoder(ctx.Writer).Encode(data); err != nil {
+		h.Logger.Errorf("write %s error, %v", filename, err)
+	}
+}
+
 // UploadModel 上传模型
 //
 //	@tags		BhaModel
@@ -0,0 +1,255 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+
+	"bin-vul-inspector/pkg/api/services"
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/constant"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/mongo"
+	"bin-vul-inspector/pkg/utils"
+)
+
+// ListSuppression 抑制规则列表
+//
+//	@tags		BhaSuppression
+//	@summary	抑制规则列表
+//	@router		/bha/suppressions [get]
+//	@Param		page		query		int		true	"页码"	minimum(1)	default(1)
+//	@Param		page_size	query		int		true	"页大小"	minimum(1)	default(20)
+//	@Param		q			query		string	false	"关键字查询, CVE、函数名称、文件名"
+//	@Param		project		query		string	false	"项目"
+//	@Param		file_hash	query		string	false	"文件hash"
+//	@success	200			{object}	dto.Response{data=dto.ListResponse[models.BhaSuppression]}
+func (h *Bha) ListSuppression(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaSuppressionListReq
+	{
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	total, list, err := mongo.NewBhaSuppression(h.Mongo).List(ctx, params)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.Success(ctx, dto.ListResponse[models.BhaSuppression]{
+		Count: total,
+		List:  utils.NotNull(list),
+	})
+}
+
+// CreateSuppression 新增抑制规则
+//
+//	@tags			BhaSuppression
+//	@summary		新增抑制规则
+//	@router			/bha/suppressions [post]
+//	@description	规则仅应用于之后入库的扫描结果
+//	@accept			application/json
+//	@produce		application/json
+//	@Param			body	body		dto.BhaSuppressionReq	true	"抑制规则"
+//	@success		200		{object}	dto.Response{data=dto.CreateRes}
+func (h *Bha) CreateSuppression(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaSuppressionReq
+	{
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	id, err := mongo.NewBhaSuppression(h.Mongo).Create(ctx, params.Model())
+	if err != nil {
+		if mongo.IsDuplicateKey(err) {
+			h.FailMsg(ctx, dto.StatusErrDb, "已经存在相同条件的抑制规则")
+			return
+		}
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.Success(ctx, dto.CreateRes{Id: id})
+}
+
+// UpdateSuppression 更新抑制规则
+//
+//	@tags		BhaSuppression
+//	@summary	更新抑制规则
+//	@router		/bha/suppressions/{id} [put]
+//	@accept		application/json
+//	@produce	application/json
+//	@Param		id		path		string					true	"规则id"
+//	@Param		body	body		dto.BhaSuppressionReq	true	"抑制规则"
+//	@success	200		{object}	dto.Response{data=models.BhaSuppression}
+func (h *Bha) UpdateSuppression(ctx *gin.Context) {
+	var err error
+
+	var params dto.BhaSuppressionUpdateReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusParamInvalid)
+			return
+		}
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	suppressions := mongo.NewBhaSuppression(h.Mongo)
+	ok, err := suppressions.UpdateById(ctx, params.Id, params.Model())
+	if err != nil {
+		if mongo.IsDuplicateKey(err) {
+			h.FailMsg(ctx, dto.StatusErrDb, "已经存在相同条件的抑制规则")
+			return
+		}
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	if !ok {
+		h.Fail(ctx, dto.StatusDataNotFound)
+		return
+	}
+
+	m, err := suppressions.FindById(ctx, params.Id)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	h.Success(ctx, m)
+}
+
+// DeleteSuppression 删除抑制规则
+//
+//	@tags			BhaSuppression
+//	@summary		删除抑制规则
+//	@router			/bha/suppressions/{id} [delete]
+//	@description	已入库的扫描结果保持抑制状态
+//	@produce		application/json
+//	@Param			id	path		string	true	"规则id"
+//	@success		200	{object}	dto.Response
+func (h *Bha) DeleteSuppression(ctx *gin.Context) {
+	var err error
+
+	id := ctx.Param("id")
+	if id == "" {
+		h.Fail(ctx, dto.StatusParamInvalid)
+		return
+	}
+
+	suppressions := mongo.NewBhaSuppression(h.Mongo)
+	m, err := suppressions.FindById(ctx, id)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	if m == nil {
+		h.Fail(ctx, dto.StatusDataNotFound)
+		return
+	}
+
+	if err = suppressions.Delete(ctx, id); err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	h.Success(ctx, nil)
+}
+
+// ExportSuppression 导出抑制规则文件
+//
+//	@tags		BhaSuppression
+//	@summary	导出抑制规则文件
+//	@router		/bha/suppressions/export [get]
+//	@produce	application/json
+//	@success	200	{object}	dto.BhaSuppressionFile
+func (h *Bha) ExportSuppression(ctx *gin.Context) {
+	list, err := mongo.NewBhaSuppression(h.Mongo).FindAll(ctx)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	file := dto.BhaSuppressionFile{
+		Version:      dto.BhaSuppressionFileVersion,
+		Suppressions: make([]dto.BhaSuppressionReq, 0, len(list)),
+	}
+	for i := range list {
+		file.Suppressions = append(file.Suppressions, dto.NewBhaSuppressionReq(&list[i]))
+	}
+	h.jsonReport(ctx, "bin-vul-inspector-suppressions.json", constant.MineTypeJson, file)
+}
+
+// ImportSuppression 导入抑制规则文件
+//
+//	@tags			BhaSuppression
+//	@summary		导入抑制规则文件
+//	@router			/bha/suppressions/import [post]
+//	@description	按匹配条件合并，已存在的规则更新研判状态、依据及原因
+//	@accept			multipart/form-data
+//	@produce		application/json
+//	@Param			upload_file	formData	file	true	"抑制规则文件"
+//	@success		200			{object}	dto.Response{data=dto.BhaSuppressionImportRes}
+func (h *Bha) ImportSuppression(ctx *gin.Context) {
+	upload, err := services.NewForm().UploadFile(ctx.Request, "upload_file", "")
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	defer func() { _ = os.RemoveAll(upload.Path) }()
+
+	var file dto.BhaSuppressionFile
+	{
+		data, err := os.ReadFile(upload.Path)
+		if err != nil {
+			h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+			return
+		}
+		if err = json.Unmarshal(data, &file); err != nil {
+			h.FailMsg(ctx, dto.StatusErrJson, fmt.Sprintf("抑制规则文件格式错误, %s", err))
+			return
+		}
+		if err = file.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	list := make([]models.BhaSuppression, 0, len(file.Suppressions))
+	for i := range file.Suppressions {
+		list = append(list, file.Suppressions[i].Model())
+	}
+
+	inserted, updated, err := mongo.NewBhaSuppression(h.Mongo).Upsert(ctx, list)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	h.Success(ctx, dto.BhaSuppressionImportRes{Inserted: inserted, Updated: updated})
+}
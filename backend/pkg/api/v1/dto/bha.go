@@ -14,17 +14,55 @@ import (
 	"bin-vul-inspector/pkg/utils"
 )
 
-type BhaFuncListReq struct {
+type BhaFileListReq struct {
 	PageParam
 
 	TaskId string `json:"task_id" uri:"task_id"` // task id
-	Q      string `json:"q" form:"q"`            // 关键字查询
+	Q      string `json:"q" form:"q"`            // 关键字查询, 文件路径
+	Abi    string `json:"abi" form:"abi"`        // 安装包中 native 库的 ABI
+}
+
+func (req *BhaFileListReq) Validate() error {
+	if req.TaskId == "" {
+		return errors.New("task_id不能为空")
+	}
+
+	if err := req.PageParam.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type BhaAbiListReq struct {
+	TaskId string `json:"task_id" uri:"task_id"` // task id
+}
+
+func (req *BhaAbiListReq) Validate() error {
+	if req.TaskId == "" {
+		return errors.New("task_id不能为空")
+	}
+	return nil
+}
+
+type BhaFuncListReq struct {
+	PageParam
+	BhaTriageFilter
+
+	TaskId string `json:"task_id" uri:"task_id"`  // task id
+	FileId string `json:"file_id" form:"file_id"` // 文件 id
+	Q      string `json:"q" form:"q"`             // 关键字查询
+
+	Suppressed bool `json:"suppressed" form:"suppressed"` // 研判条件是否包含被抑制规则命中的结果
 }
 
 func (req *BhaFuncListReq) Validate() error {
 	if req.TaskId == "" {
 		return errors.New("task_id不能为空")
 	}
+	if err := req.BhaTriageFilter.Validate(); err != nil {
+		return err
+	}
 
 	if err := req.PageParam.Validate(); err != nil {
 		return err
@@ -35,11 +73,14 @@ func (req *BhaFuncListReq) Validate() error {
 
 type BhaFuncResultListReq struct {
 	PageParam
+	BhaTriageFilter
 
 	TaskId string `json:"task_id" uri:"task_id"`  // task id
 	FuncId string `json:"func_id" form:"func_id"` // bhaFunc id
 	TopN   *uint  `json:"top_n" form:"top_n"`     // TopN
 	Q      string `json:"q" form:"q"`             // 关键字查询, 函数名称
+
+	Suppressed bool `json:"suppressed" form:"suppressed"` // 是否包含被抑制规则命中的结果
 }
 
 func (req *BhaFuncResultListReq) Validate() error {
@@ -52,6 +93,9 @@ func (req *BhaFuncResultListReq) Validate() error {
 	if !pointer.IsNil(req.TopN) && pointer.PAny(req.TopN) > 100 {
 		return errors.New("topN不能超过100")
 	}
+	if err := req.BhaTriageFilter.Validate(); err != nil {
+		return err
+	}
 
 	if err := req.PageParam.Validate(); err != nil {
 		return err
@@ -60,6 +104,304 @@ func (req *BhaFuncResultListReq) Validate() error {
 	return nil
 }
 
+// BhaTriageFilter 按研判信息过滤
+type BhaTriageFilter struct {
+	TriageStatus string `json:"triage_status" form:"triage_status"` // 研判状态
+	Assignee     string `json:"assignee" form:"assignee"`           // 处理人
+}
+
+func (req *BhaTriageFilter) Validate() error {
+	if req.TriageStatus != "" && !utils.Contains(models.TriageStatuses(), req.TriageStatus) {
+		return fmt.Errorf("研判状态必须为 %s", models.TriageStatuses())
+	}
+	return nil
+}
+
+// BhaTriageReq 研判，仅更新非空字段，comment 非空时追加评论
+type BhaTriageReq struct {
+	Status        *string `json:"status"`        // 研判状态
+	Justification *string `json:"justification"` // 研判依据，空字符串表示清除
+	Assignee      *string `json:"assignee"`      // 处理人，空字符串表示清除
+	Comment       string  `json:"comment"`       // 评论
+	Author        string  `json:"author"`        // 评论人
+	Suppress      bool    `json:"suppress"`      // 同时创建抑制规则，应用于同一项目的后续扫描
+}
+
+func (req *BhaTriageReq) Validate() error {
+	if req.Status == nil && req.Justification == nil && req.Assignee == nil && req.Comment == "" {
+		return errors.New("status、justification、assignee、comment不能同时为空")
+	}
+	if req.Status != nil && !utils.Contains(models.TriageStatuses(), *req.Status) {
+		return fmt.Errorf("研判状态必须为 %s", models.TriageStatuses())
+	}
+	if req.Justification != nil && *req.Justification != "" && !utils.Contains(models.TriageJustifications(), *req.Justification) {
+		return fmt.Errorf("研判依据必须为 %s", models.TriageJustifications())
+	}
+	if req.Assignee != nil && utf8.RuneCountInString(*req.Assignee) > 64 {
+		return errors.New("处理人长度不能超过64")
+	}
+	if req.Comment != "" && req.Author == "" {
+		return errors.New("评论人不能为空")
+	}
+	if utf8.RuneCountInString(req.Comment) > 2000 {
+		return errors.New("评论长度不能超过2000")
+	}
+	if req.Suppress && (req.Status == nil || !utils.Contains(models.SuppressionStatuses(), *req.Status)) {
+		return fmt.Errorf("创建抑制规则时研判状态必须为 %s", models.SuppressionStatuses())
+	}
+
+	return nil
+}
+
+type BhaFuncResultTriageReq struct {
+	BhaTriageReq
+
+	TaskId string `json:"-" uri:"task_id"` // task id
+	Id     string `json:"-" uri:"id"`      // bha func result id
+}
+
+func (req *BhaFuncResultTriageReq) Validate() error {
+	if req.TaskId == "" {
+		return errors.New("task_id不能为空")
+	}
+	if req.Id == "" {
+		return errors.New("id不能为空")
+	}
+
+	return req.BhaTriageReq.Validate()
+}
+
+// BhaTriageBulkMax 批量研判的最大数量
+const BhaTriageBulkMax = 1000
+
+type BhaFuncResultBulkTriageReq struct {
+	BhaTriageReq
+
+	TaskId string   `json:"-" uri:"task_id"` // task id
+	Ids    []string `json:"ids"`             // bha func result id
+}
+
+func (req *BhaFuncResultBulkTriageReq) Validate() error {
+	if req.TaskId == "" {
+		return errors.New("task_id不能为空")
+	}
+	if len(req.Ids) == 0 {
+		return errors.New("ids不能为空")
+	}
+	if len(req.Ids) > BhaTriageBulkMax {
+		return fmt.Errorf("ids数量不能超过%d", BhaTriageBulkMax)
+	}
+
+	return req.BhaTriageReq.Validate()
+}
+
+type BhaTriageRes struct {
+	Matched    int64 `json:"matched"`    // 匹配到的结果数
+	Suppressed int64 `json:"suppressed"` // 新增的抑制规则数
+}
+
+// 函数匹配结果导出格式
+const (
+	BhaExportFormatCSV    = "csv"    // CSV
+	BhaExportFormatNDJSON = "ndjson" // 每行一个json对象
+)
+
+func BhaExportFormats() []string {
+	return []string{BhaExportFormatCSV, BhaExportFormatNDJSON}
+}
+
+type BhaFuncResultExportReq struct {
+	TaskId    string   `json:"task_id" uri:"task_id"`      // task id
+	Format    string   `json:"format" form:"format"`       // 导出格式
+	TopN      *uint    `json:"top_n" form:"top_n"`         // 每个函数导出的匹配结果数
+	Q         string   `json:"q" form:"q"`                 // 关键字查询, 匹配函数名称
+	MinSim    *float64 `json:"min_sim" form:"min_sim"`     // 最小相似分数
+	Unmatched bool     `json:"unmatched" form:"unmatched"` // 是否导出无匹配结果的函数
+
+	Suppressed bool `json:"suppressed" form:"suppressed"` // 是否导出被抑制规则命中的结果
+}
+
+func (req *BhaFuncResultExportReq) Validate() error {
+	req.Format = strings.ToLower(req.Format)
+
+	if req.TaskId == "" {
+		return errors.New("task_id不能为空")
+	}
+	if req.Format == "" {
+		req.Format = BhaExportFormatCSV
+	}
+	if !utils.Contains(BhaExportFormats(), req.Format) {
+		return fmt.Errorf("导出格式必须为 %s", BhaExportFormats())
+	}
+	// 与扫描时允许的 top_n 一致，保证存储的结果均可导出
+	if maxTopN := bha.MaxTopNOfAlgorithms(); !pointer.IsNil(req.TopN) && pointer.PAny(req.TopN) > maxTopN {
+		return fmt.Errorf("topN不能超过%d", maxTopN)
+	}
+	if !pointer.IsNil(req.MinSim) && (pointer.PAny(req.MinSim) < 0 || pointer.PAny(req.MinSim) > 1) {
+		return errors.New("min_sim 必须在 0~1 之间")
+	}
+
+	return nil
+}
+
+// CVE聚合结果排序方式
+const (
+	BhaCVESortBySim      = "sim"      // 最高相似分数
+	BhaCVESortBySeverity = "severity" // CVSS分数
+	BhaCVESortByEPSS     = "epss"     // EPSS分数
+)
+
+func BhaCVESortBys() []string {
+	return []string{BhaCVESortBySim, BhaCVESortBySeverity, BhaCVESortByEPSS}
+}
+
+type BhaCVEListReq struct {
+	PageParam
+
+	TaskId string `json:"task_id" uri:"task_id"`  // task id
+	Q      string `json:"q" form:"q"`             // 关键字查询, CVE编号或purl
+	SortBy string `json:"sort_by" form:"sort_by"` // 排序方式 sim, severity, epss
+
+	Suppressed bool `json:"suppressed" form:"suppressed"` // 是否包含被抑制规则命中的结果
+}
+
+func (req *BhaCVEListReq) Validate() error {
+	if req.TaskId == "" {
+		return errors.New("task_id不能为空")
+	}
+	if req.SortBy == "" {
+		req.SortBy = BhaCVESortBySim
+	}
+	if !utils.Contains(BhaCVESortBys(), req.SortBy) {
+		return fmt.Errorf("排序方式必须为 %s", BhaCVESortBys())
+	}
+
+	if err := req.PageParam.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type BhaSearchReq struct {
+	PageParam
+
+	CVE     string   `json:"cve" form:"cve"`         // CVE编号
+	Purl    string   `json:"purl" form:"purl"`       // purl, 前缀匹配
+	Version string   `json:"version" form:"version"` // 版本
+	FName   string   `json:"fname" form:"fname"`     // 函数名称, 匹配检测文件函数或匹配文件函数
+	MinSim  *float64 `json:"min_sim" form:"min_sim"` // 最小相似分数
+	MaxSim  *float64 `json:"max_sim" form:"max_sim"` // 最大相似分数
+
+	Suppressed bool `json:"suppressed" form:"suppressed"` // 是否包含被抑制规则命中的结果
+}
+
+func (req *BhaSearchReq) Validate() error {
+	req.CVE = strings.ToUpper(strings.TrimSpace(req.CVE))
+	req.Purl = strings.TrimSpace(req.Purl)
+	req.Version = strings.TrimSpace(req.Version)
+	req.FName = strings.TrimSpace(req.FName)
+
+	if req.CVE == "" && req.Purl == "" && req.FName == "" {
+		return errors.New("cve、purl、fname 至少填写一个")
+	}
+	if !pointer.IsNil(req.MinSim) && (pointer.PAny(req.MinSim) < 0 || pointer.PAny(req.MinSim) > 1) {
+		return errors.New("min_sim 必须在 0~1 之间")
+	}
+	if !pointer.IsNil(req.MaxSim) && (pointer.PAny(req.MaxSim) < 0 || pointer.PAny(req.MaxSim) > 1) {
+		return errors.New("max_sim 必须在 0~1 之间")
+	}
+	if !pointer.IsNil(req.MinSim) && !pointer.IsNil(req.MaxSim) && pointer.PAny(req.MinSim) > pointer.PAny(req.MaxSim) {
+		return errors.New("min_sim 不能大于 max_sim")
+	}
+
+	if err := req.PageParam.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// 报告格式
+const (
+	BhaReportFormatZip       = "zip"       // 原始扫描结果压缩包
+	BhaReportFormatSARIF     = "sarif"     // SARIF 2.1.0
+	BhaReportFormatCycloneDX = "cyclonedx" // CycloneDX 1.5 SBOM及VEX
+	BhaReportFormatHTML      = "html"      // 单文件HTML报告
+)
+
+func BhaReportFormats() []string {
+	return []string{BhaReportFormatZip, BhaReportFormatSARIF, BhaReportFormatCycloneDX, BhaReportFormatHTML}
+}
+
+type BhaReportReq struct {
+	TaskId string `json:"task_id" uri:"task_id"` // task id
+	Format string `json:"format" form:"format"`  // 报告格式
+}
+
+func (req *BhaReportReq) Validate() error {
+	req.Format = strings.ToLower(req.Format)
+
+	if req.TaskId == "" {
+		return errors.New("task_id不能为空")
+	}
+	if req.Format == "" {
+		req.Format = BhaReportFormatZip
+	}
+	if !utils.Contains(BhaReportFormats(), req.Format) {
+		return fmt.Errorf("报告格式必须为 %s", BhaReportFormats())
+	}
+
+	return nil
+}
+
+// 任务对比结果格式
+const (
+	BhaDiffFormatJSON  = "json"  // 接口响应
+	BhaDiffFormatSARIF = "sarif" // SARIF 2.1.0，以 baselineState 标记变化
+	BhaDiffFormatHTML  = "html"  // 单文件HTML报告
+
+	BhaDiffDefaultThreshold = 0.05 // 默认相似分数变化阈值
+)
+
+func BhaDiffFormats() []string {
+	return []string{BhaDiffFormatJSON, BhaDiffFormatSARIF, BhaDiffFormatHTML}
+}
+
+type BhaDiffReq struct {
+	Base       string   `json:"base" form:"base"`             // 基准任务id
+	Head       string   `json:"head" form:"head"`             // 对比任务id
+	Threshold  *float64 `json:"threshold" form:"threshold"`   // 相似分数变化阈值
+	Unchanged  bool     `json:"unchanged" form:"unchanged"`   // 是否包含未变化的匹配
+	Suppressed bool     `json:"suppressed" form:"suppressed"` // 是否包含被抑制规则命中的匹配
+	Format     string   `json:"format" form:"format"`         // 结果格式
+}
+
+func (req *BhaDiffReq) Validate() error {
+	req.Format = strings.ToLower(req.Format)
+
+	if req.Base == "" || req.Head == "" {
+		return errors.New("base和head不能为空")
+	}
+	if req.Base == req.Head {
+		return errors.New("base和head不能为同一任务")
+	}
+	if pointer.IsNil(req.Threshold) {
+		req.Threshold = pointer.Of(BhaDiffDefaultThreshold)
+	}
+	if pointer.PAny(req.Threshold) < 0 || pointer.PAny(req.Threshold) > 1 {
+		return errors.New("threshold 必须在 0~1 之间")
+	}
+	if req.Format == "" {
+		req.Format = BhaDiffFormatJSON
+	}
+	if !utils.Contains(BhaDiffFormats(), req.Format) {
+		return fmt.Errorf("结果格式必须为 %s", BhaDiffFormats())
+	}
+
+	return nil
+}
+
 type BhaModelUploadReq struct {
 	Name string `json:"name" form:"name"` // 模型名称
 	Type string `json:"type" form:"type"` // 模型类型
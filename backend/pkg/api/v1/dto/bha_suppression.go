@@ -0,0 +1,165 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/pointer"
+	"bin-vul-inspector/pkg/utils"
+)
+
+type BhaSuppressionListReq struct {
+	PageParam
+
+	Q        string `json:"q" form:"q"`                 // 关键字查询, CVE、函数名称、文件名
+	Project  string `json:"project" form:"project"`     // 项目
+	FileHash string `json:"file_hash" form:"file_hash"` // 文件hash
+}
+
+func (req *BhaSuppressionListReq) Validate() error {
+	return req.PageParam.Validate()
+}
+
+// BhaSuppressionReq 抑制规则，同时作为导出文件中的规则格式
+type BhaSuppressionReq struct {
+	FileName      string   `json:"file_name"`     // 文件名，不含目录，为空时匹配全部文件
+	FName         string   `json:"fname"`         // 检测文件函数名称
+	Addr          string   `json:"addr"`          // 函数地址
+	CVE           string   `json:"cve"`           // CVE编号
+	MinSim        *float64 `json:"min_sim"`       // 最小相似分数，默认0
+	MaxSim        *float64 `json:"max_sim"`       // 最大相似分数，默认1
+	Project       string   `json:"project"`       // 适用的项目，即任务名称，为空时不限
+	FileHash      string   `json:"file_hash"`     // 适用的文件hash，为空时不限
+	Status        string   `json:"status"`        // 命中后的研判状态，默认 false_positive
+	Justification string   `json:"justification"` // 研判依据
+	Comment       string   `json:"comment"`       // 原因
+	Author        string   `json:"author"`        // 创建人
+}
+
+func (req *BhaSuppressionReq) Validate() error {
+	req.CVE = strings.ToUpper(strings.TrimSpace(req.CVE))
+	req.FileName = strings.TrimSpace(req.FileName)
+	req.FName = strings.TrimSpace(req.FName)
+	req.Addr = strings.TrimSpace(req.Addr)
+
+	if req.CVE == "" {
+		return errors.New("cve不能为空")
+	}
+	if req.FName == "" && req.Addr == "" {
+		return errors.New("fname和addr不能同时为空")
+	}
+	if strings.ContainsAny(req.FileName, `/\`) {
+		return errors.New("file_name不能包含目录")
+	}
+	if req.Addr != "" {
+		req.Addr = models.NormalizeAddr(req.Addr)
+	}
+
+	if pointer.IsNil(req.MinSim) {
+		req.MinSim = pointer.Of(0.0)
+	}
+	if pointer.IsNil(req.MaxSim) {
+		req.MaxSim = pointer.Of(1.0)
+	}
+	if pointer.PAny(req.MinSim) < 0 || pointer.PAny(req.MinSim) > 1 {
+		return errors.New("min_sim 必须在 0~1 之间")
+	}
+	if pointer.PAny(req.MaxSim) < 0 || pointer.PAny(req.MaxSim) > 1 {
+		return errors.New("max_sim 必须在 0~1 之间")
+	}
+	if pointer.PAny(req.MinSim) > pointer.PAny(req.MaxSim) {
+		return errors.New("min_sim 不能大于 max_sim")
+	}
+
+	if req.Status == "" {
+		req.Status = models.TriageFalsePositive
+	}
+	if !utils.Contains(models.SuppressionStatuses(), req.Status) {
+		return fmt.Errorf("研判状态必须为 %s", models.SuppressionStatuses())
+	}
+	if req.Justification != "" && !utils.Contains(models.TriageJustifications(), req.Justification) {
+		return fmt.Errorf("研判依据必须为 %s", models.TriageJustifications())
+	}
+	if utf8.RuneCountInString(req.Comment) > 2000 {
+		return errors.New("原因长度不能超过2000")
+	}
+
+	return nil
+}
+
+// Model 转换为抑制规则，需先调用 Validate
+func (req *BhaSuppressionReq) Model() models.BhaSuppression {
+	return models.BhaSuppression{
+		FileName:      req.FileName,
+		FName:         req.FName,
+		Addr:          req.Addr,
+		CVE:           req.CVE,
+		MinSim:        pointer.PAny(req.MinSim),
+		MaxSim:        pointer.PAny(req.MaxSim),
+		Project:       req.Project,
+		FileHash:      req.FileHash,
+		Status:        req.Status,
+		Justification: req.Justification,
+		Comment:       req.Comment,
+		Author:        req.Author,
+	}
+}
+
+func NewBhaSuppressionReq(m *models.BhaSuppression) BhaSuppressionReq {
+	return BhaSuppressionReq{
+		FileName:      m.FileName,
+		FName:         m.FName,
+		Addr:          m.Addr,
+		CVE:           m.CVE,
+		MinSim:        pointer.Of(m.MinSim),
+		MaxSim:        pointer.Of(m.MaxSim),
+		Project:       m.Project,
+		FileHash:      m.FileHash,
+		Status:        m.Status,
+		Justification: m.Justification,
+		Comment:       m.Comment,
+		Author:        m.Author,
+	}
+}
+
+type BhaSuppressionUpdateReq struct {
+	BhaSuppressionReq
+
+	Id string `json:"-" uri:"id"` // 规则id
+}
+
+func (req *BhaSuppressionUpdateReq) Validate() error {
+	if req.Id == "" {
+		return errors.New("id不能为空")
+	}
+	return req.BhaSuppressionReq.Validate()
+}
+
+// BhaSuppressionFileVersion 抑制规则文件格式版本
+const BhaSuppressionFileVersion = 1
+
+// BhaSuppressionFile 抑制规则导出文件
+type BhaSuppressionFile struct {
+	Version      int                 `json:"version"`      // 文件格式版本
+	Suppressions []BhaSuppressionReq `json:"suppressions"` // 规则
+}
+
+func (req *BhaSuppressionFile) Validate() error {
+	if req.Version != BhaSuppressionFileVersion {
+		return fmt.Errorf("不支持的文件格式版本 %d", req.Version)
+	}
+	for i := range req.Suppressions {
+		if err := req.Suppressions[i].Validate(); err != nil {
+			return fmt.Errorf("suppressions[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+type BhaSuppressionImportRes struct {
+	Inserted int64 `json:"inserted"` // 新增的规则数
+	Updated  int64 `json:"updated"`  // 已存在并更新的规则数
+}
@@ -0,0 +1,21 @@
+package dto_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/bha"
+	"bin-vul-inspector/pkg/pointer"
+)
+
+func TestBhaFuncResultExportReq_Validate_TopN(t *testing.T) {
+	// 扫描时允许的最大 top_n 均可导出
+	maxTopN := bha.MaxTopN(bha.BSDAlgorithm)
+	req := &dto.BhaFuncResultExportReq{TaskId: "t1", TopN: pointer.Of(maxTopN)}
+	assert.NoError(t, req.Validate())
+
+	req = &dto.BhaFuncResultExportReq{TaskId: "t1", TopN: pointer.Of(maxTopN + 1)}
+	assert.Error(t, req.Validate())
+}
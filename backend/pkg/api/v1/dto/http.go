@@ -20,6 +20,7 @@ const (
 	StatusTaskIdInvalid            = 1520
 	StatusTaskNotMatchImageProject = 1521
 	StatusErrDecryptFile           = 1522
+	StatusUnsupportedFile          = 1523
 
 	StatusRequestBodyLarge         = 1600
 	StatusErrContentRangeHeader    = 1601
@@ -125,6 +126,7 @@ var statusText = map[int]string{
 	StatusTaskIdInvalid:            "参数taskId不能为空",
 	StatusTaskNotMatchImageProject: "不能扫描非镜像项目",
 	StatusErrDecryptFile:           "打开加密文件错误",
+	StatusUnsupportedFile:          "不支持扫描的文件",
 
 	StatusRequestBodyLarge:         "请求数据太大",
 	StatusErrContentRangeHeader:    "Content-Range请求头格式错误",
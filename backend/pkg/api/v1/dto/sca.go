@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"errors"
+)
+
+type ScaComponentListReq struct {
+	PageParam
+
+	TaskId     string `json:"task_id" uri:"task_id"`        // task id
+	Q          string `json:"q" form:"q"`                   // 关键字查询, 组件名称或文件路径
+	Vulnerable bool   `json:"vulnerable" form:"vulnerable"` // 仅返回存在漏洞的组件
+}
+
+func (req *ScaComponentListReq) Validate() error {
+	if req.TaskId == "" {
+		return errors.New("task_id不能为空")
+	}
+
+	if err := req.PageParam.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -42,10 +42,27 @@ func (req *TaskScanParams) Validate() error {
 		return fmt.Errorf("任务参数解析错误, %w", err)
 	}
 
+	// validate sca params
+	if utils.Contains(req.Types, constant.TypeSca) {
+		if req.Sca.Input == "" {
+			req.Sca.Input = bha.FileInput
+		}
+		if !utils.Contains(bha.Inputs(), req.Sca.Input) {
+			return fmt.Errorf("输入类型必须为 %s", bha.Inputs())
+		}
+	}
+
 	// validate bha params
 	if utils.Contains(req.Types, constant.TypeBha) {
 		req.Bha.Algorithm = strings.ToLower(req.Bha.Algorithm)
 
+		if req.Bha.Input == "" {
+			req.Bha.Input = bha.FileInput
+		}
+		if !utils.Contains(bha.Inputs(), req.Bha.Input) {
+			return fmt.Errorf("输入类型必须为 %s", bha.Inputs())
+		}
+
 		if !utils.Contains(bha.DetectMethods(), req.Bha.DetectionMethod) {
 			return fmt.Errorf("检测方式必须为 %s", bha.DetectMethods())
 		}
@@ -60,6 +77,19 @@ func (req *TaskScanParams) Validate() error {
 				return errors.New("必须选择模型")
 			}
 		}
+
+		// 未指定时使用算法默认值，保证任务中记录实际生效的参数
+		if req.Bha.TopN == 0 {
+			req.Bha.TopN = bha.DefaultTopN(req.Bha.Algorithm)
+		}
+		if maxTopN := bha.MaxTopN(req.Bha.Algorithm); req.Bha.TopN > maxTopN {
+			return fmt.Errorf("%s 算法 top_n 不能大于 %d", req.Bha.Algorithm, maxTopN)
+		}
+		if req.Bha.MinimumSim < 0 || req.Bha.MinimumSim > 1 {
+			return errors.New("minimum_sim 必须在 0~1 之间")
+		}
+		// 扫描时根据模型文件记录
+		req.Bha.ModelHash = ""
 	}
 
 	return nil
@@ -67,8 +97,12 @@ func (req *TaskScanParams) Validate() error {
 
 type UploadFile struct {
 	FilePath string
-	FileHash string
+	FileHash string // SHA-256
+	FileSHA1 string
+	FileMD5  string
 	FileSize int64
+	Package  string             // 安装包类型 apk, aab, aar, jar，不是安装包时为空
+	Binary   *models.BinaryInfo // 可执行文件的预分析结果，不是可执行文件时为空
 }
 
 type TaskCreateOption func(req *TaskCreateReq)
@@ -163,6 +197,8 @@ type TaskListItem struct {
 	Desc         string    `json:"desc" bson:"description"`
 	FilePath     string    `json:"file_path" bson:"file_path"`
 	FileHash     string    `json:"file_hash" bson:"file_hash"`
+	FileSHA1     string    `json:"file_sha1" bson:"file_sha1"`
+	FileMD5      string    `json:"file_md5" bson:"file_md5"`
 	FileSize     int       `json:"file_size" bson:"file_size"`
 	Types        []string  `json:"types" bson:"types"`
 	Status       string    `json:"status" bson:"status"`
@@ -180,6 +216,14 @@ type TaskListItem struct {
 
 type TaskDetail struct {
 	TaskListItem
+	Progress   *models.TaskProgress `json:"progress,omitempty"`    // bha扫描进度
+	Backend    string               `json:"backend,omitempty"`     // 执行bha扫描的后端
+	Package    string               `json:"package,omitempty"`     // 上传的安装包类型 apk, aab, aar, jar
+	Binary     *models.BinaryInfo   `json:"binary,omitempty"`      // 上传的可执行文件的预分析结果，扫描前仅包含格式、架构等文件头信息
+	CachedFrom string               `json:"cached_from,omitempty"` // 复用扫描结果的任务id
+	Unpack     *models.TaskUnpack   `json:"unpack,omitempty"`      // 上传压缩包的解包统计
+
+	Vulnerabilities *models.VulnSummary `json:"vulnerabilities,omitempty"` // bha匹配到的CVE严重等级统计
 }
 
 type TaskLogFileReq struct {
@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/utils"
+)
+
+// 文件树中的目录节点类型
+const TaskFileDir = "dir"
+
+type TaskFileListReq struct {
+	PageParam
+
+	TaskId  string `json:"task_id" uri:"task_id"`  // task id
+	Type    string `json:"type" form:"type"`       // 文件类型 binary, archive, image, other
+	Skipped *bool  `json:"skipped" form:"skipped"` // true 仅未扫描的文件，false 仅扫描单元
+	Q       string `json:"q" form:"q"`             // 关键字查询, 文件路径
+}
+
+func (req *TaskFileListReq) Validate() error {
+	if req.TaskId == "" {
+		return errors.New("task_id不能为空")
+	}
+	if req.Type != "" && !utils.Contains(TaskFileTypes(), req.Type) {
+		return fmt.Errorf("文件类型必须为%s", TaskFileTypes())
+	}
+
+	return req.PageParam.Validate()
+}
+
+func TaskFileTypes() []string {
+	return []string{models.TaskFileBinary, models.TaskFileArchive, models.TaskFileImage, models.TaskFileOther}
+}
+
+// TaskFileTree 上传压缩包的解包文件树
+type TaskFileTree struct {
+	Unpack *models.TaskUnpack `json:"unpack"` // 解包统计，上传文件不是压缩包时为空
+	Root   *TaskFileNode      `json:"root"`   // 根目录
+}
+
+// TaskFileNode 文件树节点，嵌套压缩包及固件镜像的节点同时包含其解压出的文件
+type TaskFileNode struct {
+	Name     string          `json:"name"`
+	Path     string          `json:"path"`
+	Type     string          `json:"type"` // dir, binary, archive, image, other
+	Size     int64           `json:"size,omitempty"`
+	Format   string          `json:"format,omitempty"`
+	Skipped  string          `json:"skipped,omitempty"` // 未扫描的原因
+	Children []*TaskFileNode `json:"children,omitempty"`
+}
+
+// NewTaskFileTree 按路径将解包出的文件组织为树，子节点按名称排序
+func NewTaskFileTree(files []models.TaskFile) *TaskFileNode {
+	root := &TaskFileNode{Type: TaskFileDir}
+	index := map[string]*TaskFileNode{"": root}
+
+	for i := range files {
+		parent := root
+		parts := strings.Split(files[i].Path, "/")
+		for j := range parts {
+			p := strings.Join(parts[:j+1], "/")
+			node, ok := index[p]
+			if !ok {
+				node = &TaskFileNode{Name: parts[j], Path: p, Type: TaskFileDir}
+				index[p] = node
+				parent.Children = append(parent.Children, node)
+			}
+			parent = node
+		}
+
+		parent.Type = files[i].Type
+		parent.Size = files[i].Size
+		parent.Format = files[i].Format
+		parent.Skipped = files[i].Skipped
+	}
+
+	sortTaskFileNode(root)
+	return root
+}
+
+func sortTaskFileNode(node *TaskFileNode) {
+	sort.Slice(node.Children, func(i, j int) bool {
+		return node.Children[i].Name < node.Children[j].Name
+	})
+	for _, child := range node.Children {
+		sortTaskFileNode(child)
+	}
+}
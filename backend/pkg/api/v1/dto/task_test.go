@@ -0,0 +1,78 @@
+package dto_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/bha"
+	"bin-vul-inspector/pkg/constant"
+)
+
+func TestTaskScanParams_Validate_TopN(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		method    string
+		topN      uint
+		want      uint
+		wantErr   bool
+	}{
+		{algorithm: bha.SFSAlgorithm, method: bha.FastDetectMethod, topN: 0, want: 1},
+		{algorithm: bha.SFSAlgorithm, method: bha.FastDetectMethod, topN: 10, want: 10},
+		{algorithm: bha.SFSAlgorithm, method: bha.FastDetectMethod, topN: 11, wantErr: true},
+		{algorithm: bha.SSFSAlgorithm, method: bha.IntelligentDetectMethod, topN: 0, want: 100},
+		{algorithm: bha.SSFSAlgorithm, method: bha.IntelligentDetectMethod, topN: 500, want: 500},
+		{algorithm: bha.SSFSAlgorithm, method: bha.IntelligentDetectMethod, topN: 501, wantErr: true},
+		{algorithm: bha.BSDAlgorithm, method: bha.IntelligentDetectMethod, topN: 0, want: 100},
+		{algorithm: bha.BSDAlgorithm, method: bha.IntelligentDetectMethod, topN: 200, want: 200},
+		{algorithm: bha.BSDAlgorithm, method: bha.IntelligentDetectMethod, topN: 501, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%d", tt.algorithm, tt.topN), func(t *testing.T) {
+			req := &dto.TaskScanParams{
+				Types: []string{constant.TypeBha},
+				Extra: fmt.Sprintf(`{"bha":{"detection_method":%q,"algorithm":%q,"model_id":"m1","top_n":%d}}`, tt.method, tt.algorithm, tt.topN),
+			}
+			err := req.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, req.Bha.TopN)
+		})
+	}
+
+	// 默认值不能超过上限
+	for _, algorithm := range bha.Algorithms() {
+		assert.LessOrEqual(t, bha.DefaultTopN(algorithm), bha.MaxTopN(algorithm), algorithm)
+	}
+	assert.Greater(t, bha.MaxTopN(bha.BSDAlgorithm), bha.DefaultTopN(bha.BSDAlgorithm))
+	assert.Greater(t, bha.MaxTopN(bha.SSFSAlgorithm), bha.DefaultTopN(bha.SSFSAlgorithm))
+}
+
+func TestTaskScanParams_Validate_MinimumSim(t *testing.T) {
+	for _, sim := range []float64{-0.1, 1.1} {
+		req := &dto.TaskScanParams{
+			Types: []string{constant.TypeBha},
+			Extra: fmt.Sprintf(`{"bha":{"detection_method":"fast","algorithm":"sfs","minimum_sim":%v}}`, sim),
+		}
+		assert.Error(t, req.Validate(), sim)
+	}
+}
+
+func TestTaskScanParams_Validate_ScaInput(t *testing.T) {
+	req := &dto.TaskScanParams{Types: []string{constant.TypeSca}, Extra: `{}`}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, bha.FileInput, req.Sca.Input)
+
+	req = &dto.TaskScanParams{Types: []string{constant.TypeSca}, Extra: `{"sca":{"input":"container"}}`}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, bha.ContainerInput, req.Sca.Input)
+
+	req = &dto.TaskScanParams{Types: []string{constant.TypeSca}, Extra: `{"sca":{"input":"rootfs"}}`}
+	assert.Error(t, req.Validate())
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/mongo"
+	"bin-vul-inspector/pkg/utils"
+)
+
+type Sca struct {
+	*Base
+}
+
+func NewSca(base *Base) *Sca {
+	return &Sca{
+		Base: base,
+	}
+}
+
+// ListComponent
+//
+//	@tags			ScaTask
+//	@summary		component 组件列表
+//	@description	根据版本字符串、SONAME 及符号版本识别的组件，按名称及版本聚合，CVE 由漏洞库中 NVD 的 CPE 配置按版本关联
+//	@router			/sca/task/{task_id}/components [get]
+//	@Param			task_id		path		string	true	"task_id"
+//	@Param			page		query		int		true	"页码"	minimum(1)	default(1)
+//	@Param			page_size	query		int		true	"页大小"	minimum(1)	default(20)
+//	@Param			q			query		string	false	"关键字查询, 组件名称或文件路径"
+//	@Param			vulnerable	query		bool	false	"仅返回存在漏洞的组件"
+//	@success		200			{object}	dto.Response{data=dto.ListResponse[models.ScaComponent]}
+func (h *Sca) ListComponent(ctx *gin.Context) {
+	var err error
+
+	var params dto.ScaComponentListReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusParamInvalid)
+			return
+		}
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	// 查询
+	total, list, err := mongo.NewScaComponent(h.Mongo).ListComponent(ctx, params)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.Success(ctx, dto.ListResponse[models.ScaComponent]{
+		Count: total,
+		List:  utils.NotNull(list),
+	})
+}
@@ -37,6 +37,12 @@ func NewTask(base *Base) *Task {
 //	@description	#### mode为上传扫描时(默认):
 //	@description	- types,必填参数
 //	@description	- extra,按type分别校验
+//	@description	- extra.bha.top_n,可选,每个函数保留的候选结果数,默认 sfs 1 其他 100,最大 sfs 10 其他 500
+//	@description	- extra.bha.minimum_sim,可选,最小相似度 0~1,默认 0
+//	@description	- extra.bha.no_cache,可选,为 true 时不复用相同文件及参数的扫描结果,默认 false
+//	@description	- extra.bha.input,可选,输入类型 file 可执行文件、压缩包或固件镜像,container 为 docker save 或 OCI 镜像布局的 tar 包,默认 file
+//	@description	- extra.sca.input,可选,输入类型,取值同 extra.bha.input,默认 file
+//	@description	- bha、sca 扫描时按文件头识别上传文件,不是可执行文件、压缩包、安装包或固件镜像,或 bha 扫描时可执行文件架构不支持时返回 1523
 //	@description
 //	@router		/tasks [post]
 //	@accept		multipart/form-data
@@ -47,7 +53,7 @@ func NewTask(base *Base) *Task {
 //	@Param		source		formData	string		false	"来源"	Enums(web)	default(web)
 //	@Param		types		formData	[]string	true	"任务模式"	Enums(bha)	collectionFormat(multi)
 //	@Param		upload_file	formData	file		false	"文件"
-//	@Param		extra		formData	string		false	"任务配置(默认值仅方便swagger中输入)"	default({"bha":{"detection_method": "fast", "algorithm":"SFS","model_id": ""}})
+//	@Param		extra		formData	string		false	"任务配置(默认值仅方便swagger中输入)"	default({"bha":{"detection_method": "fast", "algorithm":"SFS","model_id": "", "top_n": 1, "minimum_sim": 0}})
 //	@success	200			{object}	dto.Response{data=dto.TaskCreateRes}
 func (h *Task) Create(ctx *gin.Context) {
 	var err error
@@ -81,6 +87,10 @@ func (h *Task) Create(ctx *gin.Context) {
 		defer func() {
 			_ = os.RemoveAll(filepath.Dir(uploadFile.Path))
 		}()
+		if err = services.NewTask(h.Kit).CheckUpload(&params, uploadFile); err != nil {
+			h.Error(ctx, err)
+			return
+		}
 
 		// 上传文件至MinIO
 		p := constant.TaskUploadPath(params.TaskId, filepath.Base(uploadFile.Path))
@@ -92,7 +102,11 @@ func (h *Task) Create(ctx *gin.Context) {
 
 		params.UploadFile.FilePath = p
 		params.UploadFile.FileHash = uploadFile.Hash
+		params.UploadFile.FileSHA1 = uploadFile.SHA1
+		params.UploadFile.FileMD5 = uploadFile.MD5
 		params.UploadFile.FileSize = uploadFile.Size
+		params.UploadFile.Package = uploadFile.Package
+		params.UploadFile.Binary = uploadFile.Binary
 	}
 
 	// 保存数据
@@ -186,8 +200,117 @@ func (h *Task) Detail(ctx *gin.Context) {
 		return
 	}
 
+	detail := dto.TaskDetail{TaskListItem: list[0]}
+
+	// bha扫描进度及执行扫描的后端
+	if utils.Contains(detail.Types, constant.TypeBha) {
+		var m *models.Task
+		if m, err = mongo.NewTask(h.Mongo).GetBhaTask(ctx, taskId); err != nil {
+			h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+			return
+		}
+		if m != nil {
+			detail.Progress = m.Progress
+			detail.Backend = m.Backend
+			detail.Package = m.Package
+			detail.Binary = m.Binary
+			detail.CachedFrom = m.CachedFrom
+			detail.Unpack = m.Unpack
+		}
+		if detail.Vulnerabilities, err = services.NewVulnerability(h.Kit).TaskSummary(ctx, taskId); err != nil {
+			h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+			return
+		}
+	}
+
 	// 返回结果
-	h.Success(ctx, dto.TaskDetail{TaskListItem: list[0]})
+	h.Success(ctx, detail)
+}
+
+// ListFile 解包文件列表
+//
+//	@tags			Task
+//	@summary		解包文件列表
+//	@description	上传压缩包解包出的文件，skipped 为未扫描的原因
+//	@router			/tasks/{task_id}/files [get]
+//	@produce		application/json
+//	@Param			task_id		path		string	true	"task_id"
+//	@Param			page		query		int		true	"页码"	minimum(1)	default(1)
+//	@Param			page_size	query		int		true	"页大小"	minimum(1)	default(20)
+//	@Param			type		query		string	false	"文件类型"	Enums(binary, archive, other)
+//	@Param			skipped		query		bool	false	"true 仅未扫描的文件，false 仅扫描单元"
+//	@Param			q			query		string	false	"关键字查询, 文件路径"
+//	@success		200			{object}	dto.Response{data=dto.ListResponse[models.TaskFile]}
+func (h *Task) ListFile(ctx *gin.Context) {
+	var err error
+
+	var params dto.TaskFileListReq
+	{
+		if err = ctx.ShouldBindUri(&params); err != nil {
+			h.Fail(ctx, dto.StatusParamInvalid)
+			return
+		}
+		if err = ctx.ShouldBind(&params); err != nil {
+			h.ErrorParseFormData(ctx, err)
+			return
+		}
+		// 参数验证
+		if err = params.Validate(); err != nil {
+			h.FailMsg(ctx, dto.StatusParamInvalid, err.Error())
+			return
+		}
+	}
+
+	total, list, err := mongo.NewTaskFile(h.Mongo).List(ctx, params)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.Success(ctx, dto.ListResponse[models.TaskFile]{
+		Count: total,
+		List:  utils.NotNull(list),
+	})
+}
+
+// FileTree 解包文件树
+//
+//	@tags			Task
+//	@summary		解包文件树
+//	@description	上传压缩包解包出的文件树，嵌套压缩包的节点包含其解压出的文件
+//	@router			/tasks/{task_id}/files/tree [get]
+//	@produce		application/json
+//	@Param			task_id	path		string	true	"task_id"
+//	@success		200		{object}	dto.Response{data=dto.TaskFileTree}
+func (h *Task) FileTree(ctx *gin.Context) {
+	var err error
+
+	taskId := ctx.Param("task_id")
+	if taskId == "" {
+		h.Fail(ctx, dto.StatusTaskIdInvalid)
+		return
+	}
+
+	task, err := mongo.NewTask(h.Mongo).GetBhaTask(ctx, taskId)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	if task == nil {
+		h.Fail(ctx, dto.StatusDataNotFound)
+		return
+	}
+
+	files, err := mongo.NewTaskFile(h.Mongo).FindByTaskId(ctx, taskId)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+
+	h.Success(ctx, dto.TaskFileTree{
+		Unpack: task.Unpack,
+		Root:   dto.NewTaskFileTree(files),
+	})
 }
 
 // Delete 删除任务
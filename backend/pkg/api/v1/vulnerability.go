@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/mongo"
+)
+
+type Vulnerability struct {
+	*Base
+}
+
+func NewVulnerability(base *Base) *Vulnerability {
+	return &Vulnerability{
+		Base: base,
+	}
+}
+
+// Detail 漏洞详情
+//
+//	@tags		Vulnerability
+//	@summary	漏洞详情
+//	@router		/vulnerabilities/{cve} [get]
+//	@produce	application/json
+//	@Param		cve	path		string	true	"CVE编号"
+//	@success	200	{object}	dto.Response{data=models.Vulnerability}
+func (h *Vulnerability) Detail(ctx *gin.Context) {
+	cve := strings.ToUpper(strings.TrimSpace(ctx.Param("cve")))
+	if cve == "" {
+		h.Fail(ctx, dto.StatusParamInvalid)
+		return
+	}
+
+	vuln, err := mongo.NewVulnerability(h.Mongo).FindByCVE(ctx, cve)
+	if err != nil {
+		h.FailMsg(ctx, dto.StatusErrDb, err.Error())
+		return
+	}
+	if vuln == nil {
+		h.Fail(ctx, dto.StatusDataNotFound)
+		return
+	}
+
+	h.Success(ctx, vuln)
+}
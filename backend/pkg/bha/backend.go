@@ -0,0 +1,66 @@
+package bha
+
+import (
+	"context"
+
+	"bin-vul-inspector/pkg/client"
+	"bin-vul-inspector/pkg/client/bhaserver"
+)
+
+// 扫描后端类型
+const (
+	BackendHTTP       = "http"       // 独立部署的bha server
+	BackendSubprocess = "subprocess" // 本地子进程
+)
+
+func Backends() []string {
+	return []string{BackendHTTP, BackendSubprocess}
+}
+
+// Backend bha扫描后端
+// 扫描结束时需在 ScanReq.OutputDir 下写入 output.json, log.txt, output_asm.txt 及 status 文件
+type Backend interface {
+	Name() string
+	Scan(ctx context.Context, params bhaserver.ScanReq) (id string, err error)
+	Status(ctx context.Context, id string) (*bhaserver.ScanStatus, error)
+	Terminate(ctx context.Context, id string) error
+	Health(ctx context.Context) error
+}
+
+var (
+	_ Backend = (*HTTPBackend)(nil)
+	_ Backend = (*SubprocessBackend)(nil)
+)
+
+// HTTPBackend 通过http调用bha server
+type HTTPBackend struct {
+	apiUrl string
+	client *bhaserver.Client
+}
+
+func NewHTTPBackend(apiUrl string, options ...client.Option) *HTTPBackend {
+	return &HTTPBackend{
+		apiUrl: apiUrl,
+		client: bhaserver.NewClient(apiUrl, options...),
+	}
+}
+
+func (b *HTTPBackend) Name() string {
+	return b.apiUrl
+}
+
+func (b *HTTPBackend) Scan(_ context.Context, params bhaserver.ScanReq) (string, error) {
+	return b.client.Scan(params)
+}
+
+func (b *HTTPBackend) Status(_ context.Context, id string) (*bhaserver.ScanStatus, error) {
+	return b.client.Status(id)
+}
+
+func (b *HTTPBackend) Terminate(_ context.Context, id string) error {
+	return b.client.Terminate(id)
+}
+
+func (b *HTTPBackend) Health(ctx context.Context) error {
+	return b.client.Health(ctx)
+}
@@ -0,0 +1,351 @@
+package bha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	stdminio "github.com/minio/minio-go/v7"
+
+	"bin-vul-inspector/pkg/client/bhaserver"
+	"bin-vul-inspector/pkg/minio"
+	"bin-vul-inspector/pkg/utils"
+)
+
+const (
+	statusRunning = "running"
+
+	subprocessStatusTTL = time.Hour // 已结束且未被查询的扫描状态的保留时长
+)
+
+var errSubprocessTerminated = errors.New("terminate")
+
+// SubprocessBackend 以本地子进程的方式执行扫描，输出文件布局与bha server一致
+//
+// 命令行参数支持以下占位符:
+//
+//	{type}        检测算法 sfs,ssfs,bsd
+//	{input}       扫描目标的本地路径
+//	{arch}        扫描目标的架构，未指定时为空
+//	{output}      结果文件 output.json 的本地路径
+//	{output_dir}  输出目录的本地路径，可写入 output_asm.txt
+//	{model}       模型文件的本地路径
+//	{model_md5}   模型文件md5
+//	{top_n}       topN
+//	{minimum_sim} 最小相似度
+//
+// 结果文件可为 bha-result.json 格式，也可为 bsd/eval_cli.py 输出的 {"funcs": [...]}，后者转换后上传
+type SubprocessBackend struct {
+	command     []string         // 命令行
+	workdir     string           // 子进程工作目录
+	minioClient *stdminio.Client // minio client
+	scans       sync.Map         // 扫描 id -> *subprocessScan，结束的扫描保留至状态被查询
+}
+
+type subprocessScan struct {
+	sync.Mutex
+	cancel     context.CancelCauseFunc
+	status     bhaserver.ScanStatus
+	finishedAt time.Time // 结束时间，运行中为零值
+}
+
+type SubprocessOption func(*SubprocessBackend)
+
+func WithWorkdir(workdir string) SubprocessOption {
+	return func(b *SubprocessBackend) {
+		b.workdir = workdir
+	}
+}
+
+func NewSubprocessBackend(command []string, minioClient *stdminio.Client, opts ...SubprocessOption) *SubprocessBackend {
+	b := &SubprocessBackend{
+		command:     command,
+		minioClient: minioClient,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *SubprocessBackend) Name() string {
+	return BackendSubprocess
+}
+
+func (b *SubprocessBackend) Scan(_ context.Context, params bhaserver.ScanReq) (string, error) {
+	if len(b.command) == 0 {
+		return "", errors.New("bha subprocess command is empty")
+	}
+	if b.minioClient == nil {
+		return "", errors.New("bha invalid minio client")
+	}
+
+	b.expire()
+	id := utils.GenerateUUID()
+
+	// 扫描生命周期独立于调用方，由超时时间及Terminate控制
+	ctx, cancel := context.WithCancelCause(context.Background())
+	scan := &subprocessScan{
+		cancel: cancel,
+		status: bhaserver.ScanStatus{Id: id, Status: statusRunning},
+	}
+	b.scans.Store(id, scan)
+
+	go func() {
+		defer cancel(nil)
+
+		status, msg := b.run(ctx, params)
+
+		scan.Lock()
+		defer scan.Unlock()
+		scan.status.Status = status
+		scan.status.Msg = msg
+		scan.finishedAt = time.Now()
+		if status == StatusSuccessful {
+			scan.status.Progress = 100
+		}
+	}()
+
+	return id, nil
+}
+
+func (b *SubprocessBackend) Status(_ context.Context, id string) (*bhaserver.ScanStatus, error) {
+	value, ok := b.scans.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("bha scan %s not found", id)
+	}
+
+	scan := value.(*subprocessScan)
+	scan.Lock()
+	defer scan.Unlock()
+
+	// 结束状态被查询后释放
+	if !scan.finishedAt.IsZero() {
+		b.scans.Delete(id)
+	}
+	status := scan.status
+	return &status, nil
+}
+
+// expire 释放结束后长时间未被查询的扫描状态
+func (b *SubprocessBackend) expire() {
+	b.scans.Range(func(key, value any) bool {
+		scan := value.(*subprocessScan)
+		scan.Lock()
+		defer scan.Unlock()
+		if !scan.finishedAt.IsZero() && time.Since(scan.finishedAt) > subprocessStatusTTL {
+			b.scans.Delete(key)
+		}
+		return true
+	})
+}
+
+func (b *SubprocessBackend) Terminate(_ context.Context, id string) error {
+	value, ok := b.scans.Load(id)
+	if !ok {
+		return fmt.Errorf("bha scan %s not found", id)
+	}
+
+	value.(*subprocessScan).cancel(errSubprocessTerminated)
+	return nil
+}
+
+func (b *SubprocessBackend) Health(_ context.Context) error {
+	if len(b.command) == 0 {
+		return errors.New("bha subprocess command is empty")
+	}
+	if _, err := exec.LookPath(b.command[0]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// run 执行扫描并上传输出文件，返回扫描状态
+func (b *SubprocessBackend) run(ctx context.Context, params bhaserver.ScanReq) (status string, msg string) {
+	status = StatusSuccessful
+
+	tmpDir, err := utils.MkdirTemp()
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to create a temporary directory, %s", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	outputDir := filepath.Join(tmpDir, "output")
+	logFile, err := utils.NewFile(filepath.Join(outputDir, defaultOutputLog)).Create()
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to create log file, %s", err)
+	}
+
+	if err = b.exec(ctx, tmpDir, outputDir, logFile, params); err != nil {
+		switch {
+		case errors.Is(context.Cause(ctx), errSubprocessTerminated):
+			status = StatusTerminated
+		case errors.Is(err, context.DeadlineExceeded):
+			status = StatusTimeout
+		default:
+			status = StatusFailed
+		}
+		msg = err.Error()
+		_, _ = fmt.Fprintln(logFile, msg)
+	}
+	_ = logFile.Close()
+
+	if err = b.upload(outputDir, params, status, msg); err != nil {
+		return StatusFailed, err.Error()
+	}
+	return status, msg
+}
+
+func (b *SubprocessBackend) exec(ctx context.Context, tmpDir, outputDir string, logFile io.Writer, params bhaserver.ScanReq) error {
+	if params.Timeout > 0 {
+		var cancel func()
+
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(params.Timeout)*time.Minute)
+		defer cancel()
+	}
+
+	client := minio.New(b.minioClient, minio.WithBucket(params.OssBucket))
+
+	input := filepath.Join(tmpDir, "input", path.Base(params.InputPath))
+	if err := client.FGetObject(ctx, params.InputPath, input); err != nil {
+		return fmt.Errorf("download scan target failed, err: %w", err)
+	}
+
+	var model string
+	if params.ModelPath != "" {
+		model = filepath.Join(tmpDir, "model", path.Base(params.ModelPath))
+		if err := client.FGetObject(ctx, params.ModelPath, model); err != nil {
+			return fmt.Errorf("download model failed, err: %w", err)
+		}
+	}
+
+	output := filepath.Join(outputDir, defaultOutputJson)
+	args := ExpandCommand(b.command, params, input, output, outputDir, model)
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = b.workdir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("run bha subprocess failed, err: %w", err)
+	}
+
+	if !utils.FileExists(output) {
+		return fmt.Errorf("bha subprocess did not write %s", defaultOutputJson)
+	}
+	return convertEvalOutput(output, params)
+}
+
+// ExpandCommand 替换命令行中的占位符，input、output、outputDir、model 为本地路径
+func ExpandCommand(command []string, params bhaserver.ScanReq, input, output, outputDir, model string) []string {
+	replacer := strings.NewReplacer(
+		"{type}", params.Type,
+		"{input}", input,
+		"{arch}", params.Arch,
+		"{output}", output,
+		"{output_dir}", outputDir,
+		"{model}", model,
+		"{model_md5}", params.ModelMD5,
+		"{top_n}", strconv.FormatUint(uint64(params.TopN), 10),
+		"{minimum_sim}", strconv.FormatFloat(float64(params.MinimumSim), 'f', -1, 32),
+	)
+
+	args := make([]string, len(command))
+	for i := range command {
+		args[i] = replacer.Replace(command[i])
+	}
+	return args
+}
+
+// evalOutput bsd/eval_cli.py 的输出，仅包含单个文件的函数，结果按相似度降序
+type evalOutput struct {
+	Funcs []struct {
+		Addr    string `json:"addr"`
+		FName   string `json:"fname"`
+		Results []struct {
+			Sim     float64 `json:"sim"`
+			Name    string  `json:"name"`
+			CveUuid string  `json:"cve_uuid"`
+		} `json:"results"`
+	} `json:"funcs"`
+}
+
+// convertEvalOutput 将 eval_cli.py 的输出转换为 bha-result.json 格式，并按 top_n、minimum_sim 过滤结果
+// 结果文件已是 bha-result.json 格式(根为数组)时不处理
+func convertEvalOutput(name string, params bhaserver.ScanReq) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil
+	}
+
+	var out evalOutput
+	if err = json.Unmarshal(data, &out); err != nil {
+		return fmt.Errorf("parse bha subprocess output failed, err: %w", err)
+	}
+
+	file := Result{
+		FileId:   "0",
+		FilePath: path.Base(params.InputPath),
+		FileArch: params.Arch,
+		FuncS:    make([]Func, 0, len(out.Funcs)),
+	}
+	for _, f := range out.Funcs {
+		fn := Func{Addr: f.Addr, FName: f.FName, Results: make([]FuncResult, 0, len(f.Results))}
+		for _, r := range f.Results {
+			if r.Sim < float64(params.MinimumSim) || (params.TopN > 0 && uint(len(fn.Results)) >= params.TopN) {
+				break
+			}
+			fn.Results = append(fn.Results, FuncResult{FName: r.Name, CVE: r.CveUuid, Sim: r.Sim, Refs: make([]string, 0)})
+		}
+		file.FuncS = append(file.FuncS, fn)
+	}
+	return utils.SaveJsonFile(name, []Result{file}, false)
+}
+
+// upload 上传输出文件，最后写入status文件
+func (b *SubprocessBackend) upload(outputDir string, params bhaserver.ScanReq, status, msg string) error {
+	ctx := context.Background()
+	client := minio.New(b.minioClient, minio.WithBucket(params.OssBucket))
+
+	for _, name := range []string{defaultOutputJson, defaultOutputLog, defaultOutputAsm} {
+		p := filepath.Join(outputDir, name)
+
+		// 保证executor重命名输出文件时文件存在
+		if !utils.FileExists(p) {
+			if err := utils.SaveFile(p, nil); err != nil {
+				return err
+			}
+		}
+		if _, err := client.FPutObject(ctx, path.Join(params.OutputDir, name), p); err != nil {
+			return fmt.Errorf("upload %s failed, err: %w", name, err)
+		}
+	}
+
+	statusFile := filepath.Join(outputDir, statusFilename)
+	body := map[string]string{"status": status, "msg": msg}
+	if err := utils.SaveJsonFile(statusFile, body, false); err != nil {
+		return err
+	}
+	if _, err := client.FPutObject(ctx, path.Join(params.OutputDir, statusFilename), statusFile); err != nil {
+		return fmt.Errorf("upload %s failed, err: %w", statusFilename, err)
+	}
+	return nil
+}
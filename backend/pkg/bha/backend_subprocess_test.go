@@ -0,0 +1,151 @@
+package bha_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	stdminio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bin-vul-inspector/pkg/bha"
+	"bin-vul-inspector/pkg/client/bhaserver"
+)
+
+// fakeS3 仅支持单个对象的 HEAD、GET、PUT
+type fakeS3 struct {
+	sync.Mutex
+	objects map[string][]byte // /bucket/object -> data
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		s.objects[r.URL.Path] = data
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodHead, http.MethodGet:
+		data, ok := s.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *fakeS3) object(name string) []byte {
+	s.Lock()
+	defer s.Unlock()
+	return s.objects[name]
+}
+
+func newFakeMinio(t *testing.T) (*fakeS3, *stdminio.Client) {
+	s3 := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewTLSServer(s3)
+	t.Cleanup(server.Close)
+
+	client, err := stdminio.New(strings.TrimPrefix(server.URL, "https://"), &stdminio.Options{
+		Creds:     credentials.NewStaticV4("access", "secret", ""),
+		Secure:    true,
+		Region:    "us-east-1",
+		Transport: server.Client().Transport,
+	})
+	require.NoError(t, err)
+	return s3, client
+}
+
+func TestExpandCommand(t *testing.T) {
+	params := bhaserver.ScanReq{Type: "bsd", Arch: "arm64", ModelMD5: "abc", TopN: 10, MinimumSim: 0.75}
+	args := bha.ExpandCommand(
+		[]string{"scan", "--type={type}", "{input}", "{arch}", "-o", "{output}", "{output_dir}/asm", "{model}", "{model_md5}", "{top_n}", "{minimum_sim}"},
+		params, "/tmp/in/a.so", "/tmp/out/output.json", "/tmp/out", "/tmp/model/m.bin",
+	)
+	assert.Equal(t, []string{"scan", "--type=bsd", "/tmp/in/a.so", "arm64", "-o", "/tmp/out/output.json", "/tmp/out/asm", "/tmp/model/m.bin", "abc", "10", "0.75"}, args)
+}
+
+func TestSubprocessBackend_Scan(t *testing.T) {
+	s3, client := newFakeMinio(t)
+	s3.objects["/bucket/tasks/1/a.so"] = []byte("\x7fELF")
+
+	// 输出 eval_cli.py 格式的结果，日志中打印占位符
+	script := `printf '%s' '{"funcs":[{"addr":"1000","fname":"main","results":[` +
+		`{"sim":0.9,"name":"f1","cve_uuid":"CVE-2014-0160"},{"sim":0.8,"name":"f2","cve_uuid":"CVE-2016-2105"},` +
+		`{"sim":0.7,"name":"f3","cve_uuid":"CVE-2016-2106"},{"sim":0.1,"name":"f4","cve_uuid":"CVE-2016-2107"}]}]}' > "$1"; echo "$2 $3"`
+	backend := bha.NewSubprocessBackend([]string{"sh", "-c", script, "sh", "{output}", "{type}", "{arch}"}, client)
+
+	params := bhaserver.ScanReq{
+		Type:       "bsd",
+		OssBucket:  "bucket",
+		InputPath:  "tasks/1/a.so",
+		Arch:       "arm",
+		OutputDir:  "tasks/1/bha",
+		TopN:       2,
+		MinimumSim: 0.5,
+	}
+	id, err := backend.Scan(context.Background(), params)
+	require.NoError(t, err)
+
+	var status *bhaserver.ScanStatus
+	require.Eventually(t, func() bool {
+		status, err = backend.Status(context.Background(), id)
+		return err == nil && status.Status != "running"
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.Equal(t, bha.StatusSuccessful, status.Status, status.Msg)
+	assert.Equal(t, float64(100), status.Progress)
+
+	// 结束状态查询后释放
+	_, err = backend.Status(context.Background(), id)
+	assert.Error(t, err)
+
+	assert.Contains(t, string(s3.object("/bucket/tasks/1/bha/status")), `"status":"successful"`)
+	assert.Contains(t, string(s3.object("/bucket/tasks/1/bha/log.txt")), "bsd arm")
+	assert.NotNil(t, s3.object("/bucket/tasks/1/bha/output_asm.txt"))
+
+	var files []*bha.Result
+	var funcs []*bha.Func
+	require.NoError(t, bha.DecodeResult(bytes.NewReader(s3.object("/bucket/tasks/1/bha/output.json")), &collector{files: &files, funcs: &funcs}))
+	if assert.Len(t, files, 1) && assert.Len(t, funcs, 1) {
+		assert.Equal(t, "a.so", files[0].FilePath)
+		assert.Equal(t, "arm", files[0].FileArch)
+		assert.Equal(t, "main", funcs[0].FName)
+		assert.Equal(t, []bha.FuncResult{
+			{FName: "f1", CVE: "CVE-2014-0160", Sim: 0.9, Refs: []string{}},
+			{FName: "f2", CVE: "CVE-2016-2105", Sim: 0.8, Refs: []string{}},
+		}, funcs[0].Results)
+	}
+}
+
+type collector struct {
+	files *[]*bha.Result
+	funcs *[]*bha.Func
+}
+
+func (c *collector) HandleFunc(_ *bha.Result, fn *bha.Func) error {
+	*c.funcs = append(*c.funcs, fn)
+	return nil
+}
+
+func (c *collector) HandleFile(file *bha.Result) error {
+	*c.files = append(*c.files, file)
+	return nil
+}
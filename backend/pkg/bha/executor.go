@@ -12,7 +12,6 @@ import (
 	stdminio "github.com/minio/minio-go/v7"
 	"go.uber.org/multierr"
 
-	"bin-vul-inspector/pkg/client"
 	"bin-vul-inspector/pkg/client/bhaserver"
 	"bin-vul-inspector/pkg/constant"
 	"bin-vul-inspector/pkg/minio"
@@ -23,24 +22,42 @@ const (
 	defaultOutputJson = "output.json"
 	defaultOutputLog  = "log.txt"
 	defaultOutputAsm  = "output_asm.txt"
+	statusFilename    = "status"
 
 	ResultJsonFilename = "bha-result.json"
 )
 
+const (
+	defaultPollInterval  = 2 * time.Second  // 不支持对象通知时的轮询间隔
+	fallbackPollInterval = 30 * time.Second // 已监听对象通知时的兜底轮询间隔
+	listenWarmup         = 10 * time.Second // 开始监听后仍按短间隔轮询的时长，监听建立前结束的扫描不会收到通知
+	progressInterval     = 10 * time.Second // 查询扫描进度的间隔
+)
+
 type Executor struct {
 	algorithm   string           // sfs,ssfs,bsd
 	ossBucket   string           // name of the oss bucket
 	inputPath   string           // path of the scan target
+	arch        string           // architecture of the scan target, empty for auto detection
 	outputDir   string           // directory where the scan results will be stored
 	modelPath   string           // path of the model file
 	modelMD5    string           // md5	of the model file, for integrity verification
 	topN        uint             // number of top results to return from the scan(default is 100)
 	MinimumSim  float32          // minimumSim
-	apiUrl      string           // bha api url
+	pool        *Pool            // bha scan backends
+	backend     Backend          // backend which runs the scan
 	minioClient *stdminio.Client // minio client
 	timeout     time.Duration    // timeout
+	onProgress  ProgressFunc     // callback of scan progress
+	onAssign    AssignFunc       // callback when the scan is assigned to a backend
 }
 
+// ProgressFunc 扫描进度回调
+type ProgressFunc func(status bhaserver.ScanStatus)
+
+// AssignFunc 扫描请求被分配至后端时回调，参数为后端名称
+type AssignFunc func(backend string)
+
 type Option func(*Executor)
 
 func WithAlgorithm(algorithm string) Option {
@@ -49,6 +66,13 @@ func WithAlgorithm(algorithm string) Option {
 	}
 }
 
+// WithArch 指定扫描目标的架构，如安装包中 native 库的 ABI 对应的架构
+func WithArch(arch string) Option {
+	return func(executor *Executor) {
+		executor.arch = arch
+	}
+}
+
 func WithOssBucket(ossBucket string) Option {
 	return func(executor *Executor) {
 		executor.ossBucket = ossBucket
@@ -85,14 +109,27 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
-func NewExecutor(inputPath string, outputDir string, apiUrl string, minioClient *stdminio.Client, opts ...Option) (*Executor, error) {
+func WithProgress(f ProgressFunc) Option {
+	return func(executor *Executor) {
+		executor.onProgress = f
+	}
+}
+
+// WithAssign 扫描开始前即可记录执行扫描的后端
+func WithAssign(f AssignFunc) Option {
+	return func(executor *Executor) {
+		executor.onAssign = f
+	}
+}
+
+func NewExecutor(inputPath string, outputDir string, pool *Pool, minioClient *stdminio.Client, opts ...Option) (*Executor, error) {
 	executor := &Executor{
 		algorithm:   SFSAlgorithm,
 		ossBucket:   minio.Bucket,
 		inputPath:   inputPath,
 		outputDir:   outputDir,
 		topN:        1,
-		apiUrl:      apiUrl,
+		pool:        pool,
 		minioClient: minioClient,
 	}
 
@@ -119,8 +156,8 @@ func (executor *Executor) validate() (err error) {
 	if executor.outputDir == "" {
 		return fmt.Errorf("bha invalid outputDir")
 	}
-	if executor.apiUrl == "" {
-		return fmt.Errorf("bha invalid apiUrl")
+	if executor.pool == nil {
+		return fmt.Errorf("bha invalid backend pool")
 	}
 
 	if executor.minioClient == nil {
@@ -148,11 +185,11 @@ func (executor *Executor) Run(ctx context.Context) (err error) {
 		defer cancel()
 	}
 
-	bhaClient := bhaserver.NewClient(executor.apiUrl, client.WithInsecureSkipVerify(true))
 	params := bhaserver.ScanReq{
 		Type:       executor.algorithm,
 		OssBucket:  executor.ossBucket,
 		InputPath:  executor.inputPath,
+		Arch:       executor.arch,
 		OutputDir:  executor.outputDir,
 		ModelPath:  executor.modelPath,
 		ModelMD5:   executor.modelMD5,
@@ -161,54 +198,134 @@ func (executor *Executor) Run(ctx context.Context) (err error) {
 		Timeout:    uint(executor.timeout.Minutes()),
 	}
 
-	id, err := bhaClient.Scan(params)
+	// 优先使用MinIO对象通知，轮询仅作为兜底
+	// 在发送扫描请求前开始监听，避免错过快速结束的扫描
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	notify := executor.watchStatus(watchCtx)
+
+	backend, id, err := executor.pool.Scan(ctx, params)
 	if err != nil {
 		return fmt.Errorf("send scan request failed, err: %s", err)
 	}
+	executor.backend = backend
+	defer executor.pool.Release(backend)
+	if executor.onAssign != nil {
+		executor.onAssign(backend.Name())
+	}
 
-	var data []byte
-	timer := time.NewTimer(2 * time.Second)
-	defer timer.Stop()
+	// 监听在后台建立，期间按短间隔轮询，之后退化为兜底轮询
+	ticker := time.NewTicker(defaultPollInterval)
+	defer ticker.Stop()
+	warmup := time.NewTimer(listenWarmup)
+	defer warmup.Stop()
+	progressTicker := time.NewTicker(progressInterval)
+	defer progressTicker.Stop()
 
 	for {
 		select {
-		case <-timer.C:
-			data, err = minio.New(executor.minioClient).GetObjectBytes(ctx, path.Join(executor.outputDir, "status"))
-			if err != nil || data == nil {
-				break
+		case <-progressTicker.C:
+			executor.reportProgress(ctx, id)
+			continue
+		case <-warmup.C:
+			if notify != nil {
+				ticker.Reset(fallbackPollInterval)
 			}
-
-			var body struct {
-				Status string `json:"status"`
-				Msg    string `json:"msg"`
+			continue
+		case _, ok := <-notify:
+			if !ok {
+				// 对象存储不支持通知时，退化为短间隔轮询
+				notify = nil
+				ticker.Reset(defaultPollInterval)
+				continue
 			}
-
-			if err = json.Unmarshal(data, &body); err != nil {
-				return err
-			}
-
-			if utils.Contains(Status(), body.Status) {
-				// 更改输出文件名
-				taskPath := filepath.ToSlash(executor.outputDir)
-				if err = multierr.Combine(
-					minio.New(executor.minioClient).Rename(ctx, path.Join(taskPath, ResultJsonFilename), path.Join(taskPath, defaultOutputJson)),
-					minio.New(executor.minioClient).Rename(ctx, path.Join(taskPath, constant.TaskLogFile), path.Join(taskPath, defaultOutputLog)),
-					minio.New(executor.minioClient).Rename(ctx, path.Join(taskPath, constant.TaskAsmFile), path.Join(taskPath, defaultOutputAsm)),
-				); err != nil {
-					return fmt.Errorf("rename bha output file failed, err: %s", err)
-				}
-				return nil
-			}
-
+		case <-ticker.C:
 		case <-ctx.Done():
 			// receive terminate signal
 			if context.Cause(ctx).Error() == "terminate" {
-				_ = bhaClient.Terminate(id)
+				_ = executor.backend.Terminate(context.Background(), id)
 				return errors.New("bha scan receive terminate signal")
 			}
 			return errors.New("bha scan timeout")
 		}
 
-		timer.Reset(2 * time.Second)
+		var finished bool
+		if finished, err = executor.finished(ctx); err != nil {
+			return err
+		}
+		if finished {
+			return executor.renameOutput(ctx)
+		}
+	}
+}
+
+// reportProgress 查询扫描进度并回调，查询失败时忽略
+func (executor *Executor) reportProgress(ctx context.Context, id string) {
+	if executor.onProgress == nil {
+		return
+	}
+
+	status, err := executor.backend.Status(ctx, id)
+	if err != nil || status == nil {
+		return
+	}
+	executor.onProgress(*status)
+}
+
+// watchStatus 监听status文件的写入事件
+// 监听结束(如对象存储不支持bucket通知)时关闭返回的channel
+func (executor *Executor) watchStatus(ctx context.Context) <-chan struct{} {
+	notify := make(chan struct{}, 1)
+
+	go func() {
+		defer close(notify)
+
+		prefix := path.Join(executor.outputDir, statusFilename)
+		for info := range minio.New(executor.minioClient, minio.WithBucket(executor.ossBucket)).ListenObjectCreated(ctx, prefix, statusFilename) {
+			if info.Err != nil {
+				return
+			}
+			if len(info.Records) == 0 {
+				continue
+			}
+			select {
+			case notify <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return notify
+}
+
+// finished 读取status文件，判断扫描是否结束
+func (executor *Executor) finished(ctx context.Context) (bool, error) {
+	data, err := minio.New(executor.minioClient, minio.WithBucket(executor.ossBucket)).GetObjectBytes(ctx, path.Join(executor.outputDir, statusFilename))
+	if err != nil || data == nil {
+		return false, nil
+	}
+
+	var body struct {
+		Status string `json:"status"`
+		Msg    string `json:"msg"`
 	}
+	if err = json.Unmarshal(data, &body); err != nil {
+		return false, err
+	}
+
+	return utils.Contains(Status(), body.Status), nil
+}
+
+// renameOutput 更改输出文件名
+func (executor *Executor) renameOutput(ctx context.Context) error {
+	taskPath := filepath.ToSlash(executor.outputDir)
+	client := minio.New(executor.minioClient, minio.WithBucket(executor.ossBucket))
+	if err := multierr.Combine(
+		client.Rename(ctx, path.Join(taskPath, ResultJsonFilename), path.Join(taskPath, defaultOutputJson)),
+		client.Rename(ctx, path.Join(taskPath, constant.TaskLogFile), path.Join(taskPath, defaultOutputLog)),
+		client.Rename(ctx, path.Join(taskPath, constant.TaskAsmFile), path.Join(taskPath, defaultOutputAsm)),
+	); err != nil {
+		return fmt.Errorf("rename bha output file failed, err: %s", err)
+	}
+	return nil
 }
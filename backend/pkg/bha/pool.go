@@ -0,0 +1,131 @@
+package bha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"bin-vul-inspector/pkg/client/bhaserver"
+)
+
+const (
+	defaultHealthCheckTimeout = 5 * time.Second
+)
+
+// Pool 扫描后端池
+// 定期检查各后端健康状态，扫描时优先分发至负载最低的健康后端，失败时重试其他后端
+type Pool struct {
+	sync.Mutex
+	members []*poolMember
+}
+
+type poolMember struct {
+	backend Backend
+	healthy bool // 最近一次健康检查结果
+	running int  // 正在执行的扫描数
+}
+
+func NewPool(backends ...Backend) *Pool {
+	pool := &Pool{}
+	for _, backend := range backends {
+		// 首次健康检查前默认可用
+		pool.members = append(pool.members, &poolMember{backend: backend, healthy: true})
+	}
+	return pool
+}
+
+// RunHealthCheck 定期健康检查，直到ctx结束
+func (pool *Pool) RunHealthCheck(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pool.CheckHealth(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckHealth 并发检查所有后端的健康状态
+func (pool *Pool) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, m := range pool.members {
+		wg.Add(1)
+		go func(m *poolMember) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, defaultHealthCheckTimeout)
+			defer cancel()
+
+			err := m.backend.Health(ctx)
+			pool.Lock()
+			defer pool.Unlock()
+			m.healthy = err == nil
+		}(m)
+	}
+	wg.Wait()
+}
+
+// candidates 按 健康优先、负载从低到高 排序的后端
+func (pool *Pool) candidates() []*poolMember {
+	pool.Lock()
+	defer pool.Unlock()
+
+	members := append([]*poolMember{}, pool.members...)
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].healthy != members[j].healthy {
+			return members[i].healthy
+		}
+		return members[i].running < members[j].running
+	})
+	return members
+}
+
+// Scan 分发扫描请求，返回实际执行扫描的后端
+// 扫描结束后需调用 Release 释放负载计数
+func (pool *Pool) Scan(ctx context.Context, params bhaserver.ScanReq) (backend Backend, id string, err error) {
+	if len(pool.members) == 0 {
+		return nil, "", errors.New("bha backend pool is empty")
+	}
+
+	var errs error
+	for _, m := range pool.candidates() {
+		if id, err = m.backend.Scan(ctx, params); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", m.backend.Name(), err))
+
+			pool.Lock()
+			m.healthy = false
+			pool.Unlock()
+			continue
+		}
+
+		pool.Lock()
+		m.running++
+		pool.Unlock()
+		return m.backend, id, nil
+	}
+
+	return nil, "", errs
+}
+
+// Release 释放后端负载计数
+func (pool *Pool) Release(backend Backend) {
+	pool.Lock()
+	defer pool.Unlock()
+
+	for _, m := range pool.members {
+		if m.backend == backend && m.running > 0 {
+			m.running--
+			return
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package bha_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bin-vul-inspector/pkg/bha"
+	"bin-vul-inspector/pkg/client/bhaserver"
+)
+
+type fakeBackend struct {
+	name string
+	down bool
+}
+
+func (b *fakeBackend) Name() string {
+	return b.name
+}
+
+func (b *fakeBackend) Scan(_ context.Context, _ bhaserver.ScanReq) (string, error) {
+	if b.down {
+		return "", errors.New("connection refused")
+	}
+	return b.name + "-scan", nil
+}
+
+func (b *fakeBackend) Status(_ context.Context, id string) (*bhaserver.ScanStatus, error) {
+	return &bhaserver.ScanStatus{Id: id}, nil
+}
+
+func (b *fakeBackend) Terminate(_ context.Context, _ string) error {
+	return nil
+}
+
+func (b *fakeBackend) Health(_ context.Context) error {
+	if b.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestPool_Scan(t *testing.T) {
+	ctx := context.Background()
+	a, b := &fakeBackend{name: "a"}, &fakeBackend{name: "b"}
+	pool := bha.NewPool(a, b)
+
+	// 负载最低的后端优先
+	first, _, err := pool.Scan(ctx, bhaserver.ScanReq{})
+	assert.NoError(t, err)
+	second, _, err := pool.Scan(ctx, bhaserver.ScanReq{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Name(), second.Name())
+
+	// 释放后重新可选
+	pool.Release(first)
+	third, _, err := pool.Scan(ctx, bhaserver.ScanReq{})
+	assert.NoError(t, err)
+	assert.Equal(t, first.Name(), third.Name())
+}
+
+func TestPool_ScanRetry(t *testing.T) {
+	ctx := context.Background()
+	a, b := &fakeBackend{name: "a", down: true}, &fakeBackend{name: "b"}
+	pool := bha.NewPool(a, b)
+
+	// a不可用时重试b
+	backend, id, err := pool.Scan(ctx, bhaserver.ScanReq{})
+	assert.NoError(t, err)
+	assert.Equal(t, "b", backend.Name())
+	assert.Equal(t, "b-scan", id)
+
+	// 全部不可用
+	b.down = true
+	pool.CheckHealth(ctx)
+	_, _, err = pool.Scan(ctx, bhaserver.ScanReq{})
+	assert.Error(t, err)
+}
+
+func TestExecutor_Assign(t *testing.T) {
+	_, client := newFakeMinio(t)
+	pool := bha.NewPool(&fakeBackend{name: "a", down: true}, &fakeBackend{name: "b"})
+
+	// 分配后端时立即回调，不等待扫描结束
+	var assigned []string
+	executor, err := bha.NewExecutor("tasks/1/a.so", "tasks/1/bha", pool, client,
+		bha.WithTimeout(200*time.Millisecond),
+		bha.WithAssign(func(backend string) { assigned = append(assigned, backend) }),
+	)
+	require.NoError(t, err)
+	assert.Error(t, executor.Run(context.Background()))
+	assert.Equal(t, []string{"b"}, assigned)
+}
@@ -0,0 +1,285 @@
+package bha
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ErrInvalidResult bha-result.json 格式错误
+var ErrInvalidResult = errors.New("invalid bha result")
+
+// ResultHandler 流式处理扫描结果
+// file 不包含 FuncS
+type ResultHandler interface {
+	HandleFunc(file *Result, fn *Func) error // 逐个函数处理
+	HandleFile(file *Result) error           // 文件的全部函数处理完成后调用
+}
+
+// DecodeResult 流式解析 bha-result.json，避免一次性加载整个文件
+//
+// 文件为 []Result，每个文件的 file_id 等字段需位于 funcs 之前，否则返回 ErrInvalidResult
+func DecodeResult(r io.Reader, handler ResultHandler) error {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '['); err != nil {
+		return invalidResult("root: %s", err)
+	}
+	for i := 0; dec.More(); i++ {
+		if err := decodeResultFile(dec, i, handler); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return invalidResult("root: %s", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return invalidResult("root: unexpected data after array")
+	}
+	return nil
+}
+
+func decodeResultFile(dec *json.Decoder, i int, handler ResultHandler) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return invalidResult("[%d]: %s", i, err)
+	}
+
+	file := &Result{}
+	var hasFuncs bool
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return invalidResult("[%d]: %s", i, err)
+		}
+
+		// 函数已随 funcs 逐个交给 handler，之后出现的文件字段无法生效
+		if hasFuncs && isFileField(key) {
+			return invalidResult("[%d]: %s must precede funcs", i, key)
+		}
+
+		switch key {
+		case "file_id":
+			err = dec.Decode(&file.FileId)
+		case "file_path":
+			err = dec.Decode(&file.FilePath)
+		case "file_arch":
+			err = dec.Decode(&file.FileArch)
+		case "image":
+			err = dec.Decode(&file.Image)
+		case "layer":
+			err = dec.Decode(&file.Layer)
+		case "abi":
+			err = dec.Decode(&file.Abi)
+		case "funcs":
+			if file.FileId == "" {
+				return invalidResult("[%d]: file_id is required before funcs", i)
+			}
+			hasFuncs = true
+			if err = decodeResultFuncs(dec, i, file, handler); err != nil {
+				return err
+			}
+			continue
+		default:
+			// 忽略未知字段
+			var raw json.RawMessage
+			err = dec.Decode(&raw)
+		}
+		if err != nil {
+			return invalidResult("[%d].%s: %s", i, key, err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return invalidResult("[%d]: %s", i, err)
+	}
+
+	if file.FileId == "" {
+		return invalidResult("[%d]: file_id is required", i)
+	}
+	if !hasFuncs {
+		return invalidResult("[%d]: funcs is required", i)
+	}
+	return handler.HandleFile(file)
+}
+
+func isFileField(key json.Token) bool {
+	switch key {
+	case "file_id", "file_path", "file_arch", "image", "layer", "abi":
+		return true
+	}
+	return false
+}
+
+func decodeResultFuncs(dec *json.Decoder, i int, file *Result, handler ResultHandler) error {
+	token, err := dec.Token()
+	if err != nil {
+		return invalidResult("[%d].funcs: %s", i, err)
+	}
+	// 无函数时bha server输出null
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return invalidResult("[%d].funcs: must be an array", i)
+	}
+
+	for j := 0; dec.More(); j++ {
+		var fn Func
+		if err = dec.Decode(&fn); err != nil {
+			return invalidResult("[%d].funcs[%d]: %s", i, j, err)
+		}
+		if fn.Addr == "" {
+			return invalidResult("[%d].funcs[%d]: addr is required", i, j)
+		}
+		if err = handler.HandleFunc(file, &fn); err != nil {
+			return err
+		}
+	}
+
+	if err = expectDelim(dec, ']'); err != nil {
+		return invalidResult("[%d].funcs: %s", i, err)
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("expected '%s', got EOF", want)
+		}
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected '%s', got %v", want, token)
+	}
+	return nil
+}
+
+func invalidResult(format string, args ...any) error {
+	return fmt.Errorf("%w, %s", ErrInvalidResult, fmt.Sprintf(format, args...))
+}
+
+// FileStats 统计单个文件的检测情况
+type FileStats struct {
+	FuncCount        int64   // 函数数
+	MatchedFuncCount int64   // 存在匹配结果的函数数
+	BestSim          float64 // 最高相似分数
+	cves             map[string]struct{}
+}
+
+func (s *FileStats) Add(fn *Func) {
+	s.FuncCount++
+	if len(fn.Results) > 0 {
+		s.MatchedFuncCount++
+	}
+
+	for _, r := range fn.Results {
+		if r.Sim > s.BestSim {
+			s.BestSim = r.Sim
+		}
+		if r.CVE != "" {
+			if s.cves == nil {
+				s.cves = make(map[string]struct{})
+			}
+			s.cves[r.CVE] = struct{}{}
+		}
+	}
+}
+
+// CVECount 匹配到的CVE数(去重)
+func (s *FileStats) CVECount() int64 {
+	return int64(len(s.cves))
+}
+
+// CVEs 匹配到的CVE(去重，升序)
+func (s *FileStats) CVEs() []string {
+	list := make([]string, 0, len(s.cves))
+	for cve := range s.cves {
+		list = append(list, cve)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// ResultEncoder 流式写入 bha-result.json，可作为 DecodeResult 的 ResultHandler 合并多个扫描结果
+type ResultEncoder struct {
+	w     io.Writer
+	files int  // 已写入的文件数
+	funcs int  // 当前文件已写入的函数数
+	open  bool // 当前文件是否已写入 funcs 之前的字段
+}
+
+func NewResultEncoder(w io.Writer) *ResultEncoder {
+	return &ResultEncoder{w: w}
+}
+
+func (e *ResultEncoder) HandleFunc(file *Result, fn *Func) error {
+	if err := e.openFile(file); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fn)
+	if err != nil {
+		return err
+	}
+	if e.funcs > 0 {
+		data = append([]byte{','}, data...)
+	}
+	e.funcs++
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *ResultEncoder) HandleFile(file *Result) error {
+	if err := e.openFile(file); err != nil {
+		return err
+	}
+	e.open = false
+	_, err := io.WriteString(e.w, "]}")
+	return err
+}
+
+// Close 结束根数组，不关闭底层 writer
+func (e *ResultEncoder) Close() error {
+	if e.files == 0 {
+		_, err := io.WriteString(e.w, "[]")
+		return err
+	}
+	_, err := io.WriteString(e.w, "]")
+	return err
+}
+
+// openFile 写入文件的 funcs 之前的字段
+func (e *ResultEncoder) openFile(file *Result) error {
+	if e.open {
+		return nil
+	}
+
+	header, err := json.Marshal(struct {
+		FileId   string `json:"file_id"`
+		FilePath string `json:"file_path"`
+		FileArch string `json:"file_arch"`
+		Image    string `json:"image,omitempty"`
+		Layer    string `json:"layer,omitempty"`
+		Abi      string `json:"abi,omitempty"`
+	}{file.FileId, file.FilePath, file.FileArch, file.Image, file.Layer, file.Abi})
+	if err != nil {
+		return err
+	}
+
+	prefix := "["
+	if e.files > 0 {
+		prefix = ","
+	}
+	// {"file_id":...,"file_arch":"..."} -> {"file_id":...,"file_arch":"...","funcs":[
+	data := append([]byte(prefix), header[:len(header)-1]...)
+	data = append(data, `,"funcs":[`...)
+
+	e.files++
+	e.funcs = 0
+	e.open = true
+	_, err = e.w.Write(data)
+	return err
+}
@@ -0,0 +1,97 @@
+package bha_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bin-vul-inspector/pkg/bha"
+)
+
+type resultCollector struct {
+	files []string
+	addrs []string
+}
+
+func (c *resultCollector) HandleFunc(_ *bha.Result, fn *bha.Func) error {
+	c.addrs = append(c.addrs, fn.Addr)
+	return nil
+}
+
+func (c *resultCollector) HandleFile(file *bha.Result) error {
+	c.files = append(c.files, file.FileId+":"+file.FileArch)
+	return nil
+}
+
+func TestDecodeResult(t *testing.T) {
+	data := `[
+		{"file_id": "f1", "file_path": "bin/a", "file_arch": "x86_64", "funcs": [
+			{"addr": "1000", "fname": "main", "results": [{"fname": "main", "sim": 0.9, "refs": []}]},
+			{"addr": "2000", "fname": "foo", "results": []}
+		]},
+		{"file_id": "f2", "file_path": "bin/b", "funcs": null, "version": 2}
+	]`
+
+	c := &resultCollector{}
+	err := bha.DecodeResult(strings.NewReader(data), c)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1000", "2000"}, c.addrs)
+	assert.Equal(t, []string{"f1:x86_64", "f2:"}, c.files)
+}
+
+func TestDecodeResult_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"not array":        `{"file_id": "f1"}`,
+		"missing file_id":  `[{"funcs": []}]`,
+		"missing funcs":    `[{"file_id": "f1"}]`,
+		"missing addr":     `[{"file_id": "f1", "funcs": [{"fname": "main"}]}]`,
+		"path after funcs": `[{"file_id": "f1", "funcs": [], "file_path": "bin/a"}]`,
+		"arch after funcs": `[{"file_id": "f1", "file_path": "bin/a", "funcs": null, "file_arch": "arm"}]`,
+		"abi after funcs":  `[{"file_id": "f1", "funcs": [{"addr": "1"}], "abi": "arm64-v8a"}]`,
+		"bad sim":          `[{"file_id": "f1", "funcs": [{"addr": "1", "results": [{"sim": "x"}]}]}]`,
+		"truncated":        `[{"file_id": "f1", "funcs": [{"addr": "1"}`,
+	}
+	for name, data := range cases {
+		err := bha.DecodeResult(strings.NewReader(data), &resultCollector{})
+		assert.ErrorIs(t, err, bha.ErrInvalidResult, name)
+	}
+}
+
+func TestResultEncoder(t *testing.T) {
+	data := `[
+		{"file_id": "f1", "file_path": "bin/a", "file_arch": "x86_64", "funcs": [
+			{"addr": "1000", "fname": "main", "results": [{"fname": "main", "sim": 0.9, "refs": []}]},
+			{"addr": "2000", "fname": "foo", "results": []}
+		]},
+		{"file_id": "f2", "file_path": "bin/b", "funcs": null, "version": 2}
+	]`
+
+	var buf strings.Builder
+	enc := bha.NewResultEncoder(&buf)
+	assert.NoError(t, bha.DecodeResult(strings.NewReader(data), enc))
+	assert.NoError(t, enc.Close())
+
+	c := &resultCollector{}
+	assert.NoError(t, bha.DecodeResult(strings.NewReader(buf.String()), c))
+	assert.Equal(t, []string{"1000", "2000"}, c.addrs)
+	assert.Equal(t, []string{"f1:x86_64", "f2:"}, c.files)
+
+	buf.Reset()
+	enc = bha.NewResultEncoder(&buf)
+	assert.NoError(t, enc.Close())
+	assert.Equal(t, "[]", buf.String())
+}
+
+func TestFileStats(t *testing.T) {
+	var s bha.FileStats
+	s.Add(&bha.Func{Addr: "1", Results: []bha.FuncResult{{CVE: "CVE-2016-2105", Sim: 0.8}, {CVE: "CVE-2014-0160", Sim: 0.9}}})
+	s.Add(&bha.Func{Addr: "2", Results: []bha.FuncResult{{CVE: "CVE-2014-0160", Sim: 0.7}, {Sim: 0.6}}})
+	s.Add(&bha.Func{Addr: "3"})
+
+	assert.Equal(t, int64(3), s.FuncCount)
+	assert.Equal(t, int64(2), s.MatchedFuncCount)
+	assert.Equal(t, 0.9, s.BestSim)
+	assert.Equal(t, int64(2), s.CVECount())
+	assert.Equal(t, []string{"CVE-2014-0160", "CVE-2016-2105"}, s.CVEs())
+}
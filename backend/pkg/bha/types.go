@@ -3,10 +3,13 @@ package bha
 // Result
 // bha server result
 type Result struct {
-	FileId   string `json:"file_id"`   // 文件id
-	FilePath string `json:"file_path"` // 文件路径
-	FileArch string `json:"file_arch"` // 二进制架构
-	FuncS    []Func `json:"funcs"`     // 函数集
+	FileId   string `json:"file_id"`         // 文件id
+	FilePath string `json:"file_path"`       // 文件路径
+	FileArch string `json:"file_arch"`       // 二进制架构
+	Image    string `json:"image,omitempty"` // 容器镜像 digest，由扫描单元合并时记录
+	Layer    string `json:"layer,omitempty"` // 引入该文件的镜像层 digest
+	Abi      string `json:"abi,omitempty"`   // 安装包中 native 库的 ABI
+	FuncS    []Func `json:"funcs"`           // 函数集
 }
 
 // Func
@@ -43,6 +46,16 @@ const (
 	BSDAlgorithm  = "bsd"
 )
 
+// 输入类型
+const (
+	FileInput      = "file"      // 可执行文件、压缩包或固件镜像
+	ContainerInput = "container" // docker save 或 OCI 镜像布局的 tar 包
+)
+
+func Inputs() []string {
+	return []string{FileInput, ContainerInput}
+}
+
 func DetectMethods() []string {
 	return []string{FastDetectMethod, IntelligentDetectMethod}
 }
@@ -59,6 +72,35 @@ func IntelligentDMAlgorithms() []string {
 	return []string{SSFSAlgorithm, BSDAlgorithm}
 }
 
+// DefaultTopN 算法默认topN
+func DefaultTopN(algorithm string) uint {
+	if algorithm == SFSAlgorithm {
+		return 1
+	}
+	return 100
+}
+
+// MaxTopN 算法允许的最大topN
+// sfs 为快速检测，默认只取最相似的 1 个候选，上限 10 避免快速检测产生大量低相似度结果、拖慢入库；
+// ssfs、bsd 为智能检测，上限需高于默认值，以便保留更多候选用于人工研判
+func MaxTopN(algorithm string) uint {
+	if algorithm == SFSAlgorithm {
+		return 10
+	}
+	return 500
+}
+
+// MaxTopNOfAlgorithms 各算法允许的最大topN中的最大值，用于不区分算法的查询
+func MaxTopNOfAlgorithms() uint {
+	var n uint
+	for _, algorithm := range Algorithms() {
+		if m := MaxTopN(algorithm); m > n {
+			n = m
+		}
+	}
+	return n
+}
+
 const (
 	StatusSuccessful = "successful"
 	StatusFailed     = "failed"
@@ -1,6 +1,9 @@
 package bhaserver
 
 import (
+	"context"
+	"net/url"
+
 	"bin-vul-inspector/pkg/client"
 )
 
@@ -32,16 +35,50 @@ func (c *Client) Scan(params ScanReq) (id string, err error) {
 	return body.Data.Id, nil
 }
 
+// Status 查询扫描状态、进度及当前阶段
+func (c *Client) Status(id string) (status *ScanStatus, err error) {
+	var body ScanStatusResp
+
+	u, err := c.FullUrl("/bha/scan/%s", url.PathEscape(id))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.HttpClient.R().SetError(&body).SetResult(&body).Get(u)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.CheckResponse(res, body.Response); err != nil {
+		return nil, err
+	}
+	return &body.Data, nil
+}
+
+// Health 健康检查
+func (c *Client) Health(ctx context.Context) (err error) {
+	var body client.Response
+
+	u, err := c.FullUrl("/bha/health")
+	if err != nil {
+		return err
+	}
+
+	res, err := c.HttpClient.R().SetContext(ctx).SetError(&body).SetResult(&body).Get(u)
+	if err != nil {
+		return err
+	}
+	return c.CheckResponse(res, body)
+}
+
 func (c *Client) Terminate(id string) (err error) {
 	var body client.Response
 
-	u, err := c.FullUrl("/bha/stop")
+	u, err := c.FullUrl("/bha/stop/%s", url.PathEscape(id))
 	if err != nil {
 		return err
 	}
 
 	res, err := c.HttpClient.R().
-		SetPathParams(map[string]string{"id": id}).
 		SetError(&body).
 		SetResult(&body).
 		Post(u)
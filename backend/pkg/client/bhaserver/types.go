@@ -5,15 +5,16 @@ import (
 )
 
 type ScanReq struct {
-	Type       string  `json:"type"`        // type of the scan, e.g., SFS/SSFS/BSD
-	OssBucket  string  `json:"oss_bucket"`  // name of the oss bucket
-	InputPath  string  `json:"input_path"`  // path of the scan target
-	OutputDir  string  `json:"output_dir"`  // directory where the scan results will be stored
-	ModelPath  string  `json:"model_path"`  // path of the model file
-	ModelMD5   string  `json:"model_md5"`   // md5	of the model file, for integrity verification
-	TopN       uint    `json:"top_n"`       // number of top results to return from the scan(default is 100)
-	MinimumSim float32 `json:"minimum_sim"` // minimum_sim
-	Timeout    uint    `json:"timeout"`     // time in minutes to wait before the scan times out (default is 60 minutes)
+	Type       string  `json:"type"`           // type of the scan, e.g., SFS/SSFS/BSD
+	OssBucket  string  `json:"oss_bucket"`     // name of the oss bucket
+	InputPath  string  `json:"input_path"`     // path of the scan target
+	Arch       string  `json:"arch,omitempty"` // architecture of the scan target, e.g., x86/x86_64/arm/arm64, empty for auto detection
+	OutputDir  string  `json:"output_dir"`     // directory where the scan results will be stored
+	ModelPath  string  `json:"model_path"`     // path of the model file
+	ModelMD5   string  `json:"model_md5"`      // md5	of the model file, for integrity verification
+	TopN       uint    `json:"top_n"`          // number of top results to return from the scan(default is 100)
+	MinimumSim float32 `json:"minimum_sim"`    // minimum_sim
+	Timeout    uint    `json:"timeout"`        // time in minutes to wait before the scan times out (default is 60 minutes)
 }
 
 type ScanResp struct {
@@ -22,3 +23,27 @@ type ScanResp struct {
 		Id string `json:"id"` // scan id
 	}
 }
+
+// 扫描阶段
+const (
+	PhaseDecompile = "decompile" // 反编译
+	PhaseEmbed     = "embed"     // 函数向量化
+	PhaseMatch     = "match"     // 相似性匹配
+)
+
+func Phases() []string {
+	return []string{PhaseDecompile, PhaseEmbed, PhaseMatch}
+}
+
+type ScanStatus struct {
+	Id       string  `json:"id"`       // scan id
+	Status   string  `json:"status"`   // scan status, e.g., running/successful/failed/terminated/timeout
+	Phase    string  `json:"phase"`    // current phase, e.g., decompile/embed/match
+	Progress float64 `json:"progress"` // percent progress, 0-100
+	Msg      string  `json:"msg"`      // message
+}
+
+type ScanStatusResp struct {
+	client.Response
+	Data ScanStatus `json:"data"`
+}
@@ -21,6 +21,8 @@ type App struct {
 	InsecureSkipVerify bool    `yaml:"insecureSkipVerify"`
 	Http               HTTP    `yaml:"http"`
 	Task               Task    `yaml:"task"`
+	Bha                BHA     `yaml:"bha"`
+	Sca                SCA     `yaml:"sca"`
 	MongoDB            MongoDB `yaml:"mongodb"`
 	Minio              Minio   `yaml:"minio"`
 	Nats               Nats    `yaml:"nats"`
@@ -84,6 +86,74 @@ func (t Task) GetBhaTimeout() time.Duration {
 	return t.BhaTimeout
 }
 
+type BHA struct {
+	Backend             string        `yaml:"backend"`             // 扫描后端 http, subprocess
+	Servers             []string      `yaml:"servers"`             // bha server地址列表，为空时使用gateway
+	HealthCheckInterval time.Duration `yaml:"healthCheckInterval"` // 健康检查间隔
+	Subprocess          Subprocess    `yaml:"subprocess"`          // 本地子进程扫描配置
+	Unpack              Unpack        `yaml:"unpack"`              // 压缩包解包配置
+	Arches              []string      `yaml:"arches"`              // 支持扫描的可执行文件架构
+}
+
+func (b BHA) GetArches() []string {
+	if len(b.Arches) == 0 {
+		return []string{"x86", "x86_64", "arm", "arm64", "mips", "mips64"}
+	}
+	return b.Arches
+}
+
+func (b BHA) GetHealthCheckInterval() time.Duration {
+	if b.HealthCheckInterval.Seconds() <= 0 {
+		return 30 * time.Second
+	}
+	return b.HealthCheckInterval
+}
+
+type Subprocess struct {
+	Command []string `yaml:"command"` // 命令行，支持占位符 {type} {input} {arch} {output} {output_dir} {model} {model_md5} {top_n} {minimum_sim}
+	Workdir string   `yaml:"workdir"` // 工作目录
+}
+
+// Unpack 上传压缩包及固件镜像的解包限制
+type Unpack struct {
+	MaxFileCount uint64 `yaml:"maxFileCount"` // 解压的文件总数上限
+	MaxFileSize  uint64 `yaml:"maxFileSize"`  // 解压的文件总大小上限，单位为字节
+	MaxDepth     int    `yaml:"maxDepth"`     // 嵌套压缩包及固件镜像的解压层数上限
+}
+
+func (u Unpack) GetMaxFileCount() uint64 {
+	if u.MaxFileCount == 0 {
+		return 100000
+	}
+	return u.MaxFileCount
+}
+
+func (u Unpack) GetMaxFileSize() uint64 {
+	if u.MaxFileSize == 0 {
+		return 10 << 30
+	}
+	return u.MaxFileSize
+}
+
+func (u Unpack) GetMaxDepth() int {
+	if u.MaxDepth <= 0 {
+		return 4
+	}
+	return u.MaxDepth
+}
+
+type SCA struct {
+	Signatures string `yaml:"signatures"` // 自定义特征库 JSON 文件，与内置特征库合并，同名组件以自定义为准
+	MinLength  int    `yaml:"minLength"`  // 提取可打印字符串的最小长度
+}
+
+func (s SCA) GetMinLength() int {
+	if s.MinLength <= 0 {
+		return 4
+	}
+	return s.MinLength
+}
+
 type MongoDB struct {
 	URI string `yaml:"uri"`
 }
@@ -2,6 +2,7 @@ package constant
 
 import (
 	"path/filepath"
+	"strconv"
 )
 
 const (
@@ -13,6 +14,8 @@ const (
 
 	TaskLogFile = "bin-vul-inspector.log"
 	TaskAsmFile = "bin-vul-inspector_asm.txt"
+
+	ScaResultFile = "sca_result.json"
 )
 
 func TaskTypes() []string {
@@ -27,6 +30,11 @@ func TaskUploadPath(taskId, filename string) string {
 	return filepath.Join(TaskPath(taskId), filename)
 }
 
+// TaskUnitPath 压缩包解包出的第 index 个扫描单元
+func TaskUnitPath(taskId string, index int, filename string) string {
+	return filepath.Join(TaskPath(taskId), "units", strconv.Itoa(index), filename)
+}
+
 func TaskScaResultPath(taskId string) string {
 	return filepath.Join(TaskPath(taskId), TypeSca)
 }
@@ -7,10 +7,15 @@ const (
 	HeaderTransferEncoding = "Content-Transfer-Encoding"
 
 	MineTypeJson           = "application/json"
+	MineTypeSarifJson      = "application/sarif+json"
+	MineTypeCycloneDXJson  = "application/vnd.cyclonedx+json"
 	MineTypeZip            = "application/zip"
 	MineTypeOctetStream    = "application/octet-stream"
 	MineTypeFormUrlencoded = "application/x-www-form-urlencoded"
 	MineTypeTextPlain      = "text/plain"
+	MineTypeHtml           = "text/html; charset=utf-8"
+	MineTypeCsv            = "text/csv; charset=utf-8"
+	MineTypeNDJson         = "application/x-ndjson"
 	MineBinary             = "binary"
 )
 
@@ -1,30 +1,72 @@
 package task
 
 import (
+	"bufio"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	stdminio "github.com/minio/minio-go/v7"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"bin-vul-inspector/app/kit"
 	"bin-vul-inspector/pkg/api/services"
 	"bin-vul-inspector/pkg/bha"
+	"bin-vul-inspector/pkg/client"
+	"bin-vul-inspector/pkg/client/bhaserver"
 	"bin-vul-inspector/pkg/constant"
 	"bin-vul-inspector/pkg/minio"
 	"bin-vul-inspector/pkg/models"
 	"bin-vul-inspector/pkg/mongo"
 	"bin-vul-inspector/pkg/pointer"
+	"bin-vul-inspector/pkg/unpack"
 	"bin-vul-inspector/pkg/utils"
 )
 
+const (
+	bhaFuncBatchSize       = 1000 // 函数批量写入条数
+	bhaFuncResultBatchSize = 5000 // 函数结果批量写入条数
+)
+
 type Bha struct {
 	*kit.Kit
+	backends *bha.Pool
 }
 
-func NewBHA(kit *kit.Kit) *Bha {
-	return &Bha{Kit: kit}
+func NewBHA(kit *kit.Kit, backends *bha.Pool) *Bha {
+	return &Bha{
+		Kit:      kit,
+		backends: backends,
+	}
+}
+
+// NewBackendPool 根据配置创建扫描后端池
+func NewBackendPool(kit *kit.Kit) *bha.Pool {
+	if kit.Config.Bha.Backend == bha.BackendSubprocess {
+		return bha.NewPool(bha.NewSubprocessBackend(
+			kit.Config.Bha.Subprocess.Command, kit.Minio,
+			bha.WithWorkdir(kit.Config.Bha.Subprocess.Workdir),
+		))
+	}
+
+	servers := kit.Config.Bha.Servers
+	if len(servers) == 0 {
+		servers = []string{kit.Config.Gateway}
+	}
+
+	var backends []bha.Backend
+	for _, server := range servers {
+		backends = append(backends, bha.NewHTTPBackend(server, client.WithInsecureSkipVerify(kit.Config.InsecureSkipVerify)))
+	}
+	return bha.NewPool(backends...)
 }
 
 func (t *Bha) startJob(ctx context.Context, task *models.Task) error {
@@ -57,63 +99,445 @@ func (t *Bha) startJob(ctx context.Context, task *models.Task) error {
 			modelMD5 = stat.ETag
 		}
 
-		topN = 100
-		minimumSim = 0
-		if task.Detail.BhaParams.Algorithm == bha.SFSAlgorithm {
-			topN = 1
+		// 兼容未记录topN的历史任务
+		topN = task.Detail.BhaParams.TopN
+		if topN == 0 {
+			topN = bha.DefaultTopN(task.Detail.BhaParams.Algorithm)
 		}
+		minimumSim = task.Detail.BhaParams.MinimumSim
+		task.Detail.BhaParams.ModelHash = modelMD5
+
+		cfg := t.Config.Bha.Unpack
+		task.Detail.BhaParams.MaxFileCount = cfg.GetMaxFileCount()
+		task.Detail.BhaParams.MaxFileSize = cfg.GetMaxFileSize()
+		task.Detail.BhaParams.MaxDepth = cfg.GetMaxDepth()
+		arches := append([]string(nil), t.Config.Bha.GetArches()...)
+		sort.Strings(arches)
+		task.Detail.BhaParams.Arches = arches
 	}
 
-	var executor *bha.Executor
-	{
-		executor, err = bha.NewExecutor(
-			task.FilePath, filepath.ToSlash(constant.TaskBhaResultPath(task.TaskId)), t.Config.Gateway, t.Minio,
-			bha.WithAlgorithm(task.Detail.BhaParams.Algorithm),
-			bha.WithOssBucket(minio.Bucket),
-			bha.WithModelPath(modelPath),
-			bha.WithModelMD5(modelMD5),
-			bha.WithTopN(topN),
-			bha.WithMinimumSim(minimumSim),
-			bha.WithTimeout(t.Config.Task.GetScaTimeout()),
-		)
+	// 相同文件及参数已扫描过时复用结果
+	if !task.Detail.BhaParams.NoCache && task.FileHash != "" {
+		ok, err := t.reuseResult(ctx, task)
 		if err != nil {
-			return err
+			t.Logger.Warnf("reuse bha result for task %s error, %v", task.TaskId, err)
 		}
-
-		if pointer.IsNil(task.Detail.BhaParams) {
-			return fmt.Errorf("bha params is nil")
+		if ok {
+			return nil
 		}
 	}
 
-	if err = executor.Run(ctx); err != nil {
+	if pointer.IsNil(task.Detail.BhaParams) {
+		return fmt.Errorf("bha params is nil")
+	}
+
+	// 压缩包解包为多个扫描单元
+	units, err := t.unpack(ctx, task)
+	if err != nil {
 		return err
 	}
 
 	resultPath := filepath.ToSlash(constant.TaskBhaResultPath(task.TaskId))
+	opts := []bha.Option{
+		bha.WithAlgorithm(task.Detail.BhaParams.Algorithm),
+		bha.WithOssBucket(minio.Bucket),
+		bha.WithModelPath(modelPath),
+		bha.WithModelMD5(modelMD5),
+		bha.WithTopN(topN),
+		bha.WithMinimumSim(minimumSim),
+		bha.WithTimeout(t.Config.Task.GetScaTimeout()),
+	}
+
+	if len(units) == 0 {
+		// 非压缩包直接扫描上传文件
+		if task.Binary != nil {
+			opts = append(opts, bha.WithArch(task.Binary.Arch))
+		}
+		err = t.scan(ctx, task, task.FilePath, resultPath, append(opts, bha.WithProgress(t.progressFunc(ctx, task, 0, 1)), bha.WithAssign(t.assignFunc(ctx, task, nil)))...)
+	} else {
+		err = t.scanUnits(ctx, task, units, opts...)
+	}
+	if err != nil {
+		return err
+	}
+
 	task.Result = resultPath
 
 	return nil
 }
 
-func (t *Bha) processResult(ctx context.Context, task *models.Task) error {
-	jsonFile, remove, err := services.NewTask(t.Kit).BhaResultFile(ctx, task.TaskId)
+// scan 扫描单个文件，结果保存至 outputDir
+func (t *Bha) scan(ctx context.Context, task *models.Task, inputPath, outputDir string, opts ...bha.Option) error {
+	executor, err := bha.NewExecutor(inputPath, outputDir, t.backends, t.Minio, opts...)
 	if err != nil {
 		return err
 	}
-	defer func() { remove() }()
 
-	data, err := utils.ReadFileBytes(jsonFile)
+	return executor.Run(ctx)
+}
+
+// unpack 解包上传的压缩包或固件镜像，上传其中的可执行文件作为扫描单元，并记录解包出的全部文件
+// 上传文件不是压缩包且不包含可识别的文件系统时返回空
+func (t *Bha) unpack(ctx context.Context, task *models.Task) ([]models.TaskFile, error) {
+	tempDir, err := utils.MkdirTemp()
 	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory, %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	client := minio.New(t.Minio)
+	src := filepath.Join(tempDir, filepath.Base(task.FilePath))
+	if err = client.FGetObject(ctx, task.FilePath, src); err != nil {
+		return nil, fmt.Errorf("failed to FGetObject error, %w", err)
+	}
+
+	cfg := t.Config.Bha.Unpack
+	unpacker := unpack.New(
+		unpack.WithMaxFileCount(cfg.GetMaxFileCount()),
+		unpack.WithMaxFileSize(cfg.GetMaxFileSize()),
+		unpack.WithMaxDepth(cfg.GetMaxDepth()),
+	)
+	var res *unpack.Result
+	if task.Detail.BhaParams.Input == bha.ContainerInput {
+		res, err = unpacker.UnpackContainer(src, filepath.Join(tempDir, "unpack"))
+	} else {
+		res, err = unpacker.Unpack(src, filepath.Join(tempDir, "unpack"))
+	}
+	if err != nil {
+		task.ErrMsg = "解压文件失败"
+		if errors.Is(err, unpack.ErrNotContainer) {
+			task.ErrMsg = "不是有效的容器镜像(docker save 或 OCI 镜像布局的 tar 包)"
+		}
+		return nil, fmt.Errorf("unpack %s error, %w", task.FilePath, err)
+	}
+	if res.Archive == "" {
+		task.Unpack = nil
+		// 创建任务时仅识别了文件头，解析失败时保留文件头信息
+		if info, err := unpack.Analyze(src); err == nil {
+			task.Binary = info
+		}
+		return nil, nil
+	}
+
+	stats := &models.TaskUnpack{Archive: res.Archive, Image: res.Image}
+	files := make([]models.TaskFile, 0, len(res.Files))
+	var units []models.TaskFile
+	arches := t.Config.Bha.GetArches()
+	for i := range res.Files {
+		file := res.Files[i].TaskFile
+		file.TaskId = task.TaskId
+		if file.IsUnit() {
+			// 预分析扫描单元，跳过不支持的架构
+			if file.Binary, err = unpack.Analyze(res.Files[i].LocalPath); err != nil {
+				file.Skipped = fmt.Sprintf("parse executable error, %s", err)
+			} else if !utils.Contains(arches, file.Binary.Arch) {
+				file.Skipped = fmt.Sprintf("unsupported architecture (%s)", file.Binary.Arch)
+			} else if file.Arch == "" {
+				file.Arch = file.Binary.Arch
+			}
+		}
+		if file.IsUnit() {
+			file.Object = filepath.ToSlash(constant.TaskUnitPath(task.TaskId, len(units), path.Base(file.Path)))
+			if _, err = client.FPutObject(ctx, file.Object, res.Files[i].LocalPath); err != nil {
+				return nil, fmt.Errorf("upload %s error, %w", file.Path, err)
+			}
+			units = append(units, file)
+		}
+		if file.Skipped != "" {
+			stats.Skipped++
+		}
+		files = append(files, file)
+	}
+	stats.Files = int64(len(files))
+	stats.Units = int64(len(units))
+	task.Unpack = stats
+
+	taskFiles := mongo.NewTaskFile(t.Mongo)
+	if err = taskFiles.DeleteByTaskIds(ctx, []string{task.TaskId}); err != nil {
+		return nil, fmt.Errorf("delete task_files error, %w", err)
+	}
+	if err = taskFiles.InsertMany(ctx, files); err != nil {
+		return nil, fmt.Errorf("insert task_files error, %w", err)
+	}
+
+	if len(units) == 0 {
+		task.ErrMsg = "压缩包或固件镜像中未找到可执行文件(ELF、PE、Mach-O)"
+		if res.Image != nil {
+			task.ErrMsg = "容器镜像中未找到可执行文件(ELF、PE、Mach-O)"
+		}
+		if task.Package != "" {
+			task.ErrMsg = "安装包中未找到 native 库"
+		}
+		return nil, fmt.Errorf("no executable found in %s", task.FilePath)
+	}
+	t.Logger.Infof("task %s unpacked %d files from %s, %d units to scan", task.TaskId, stats.Files, stats.Archive, stats.Units)
+	return units, nil
+}
+
+// scanUnits 逐个扫描解包出的扫描单元，并将各单元的结果、日志合并为任务的扫描结果
+func (t *Bha) scanUnits(ctx context.Context, task *models.Task, units []models.TaskFile, opts ...bha.Option) error {
+	resultPath := filepath.ToSlash(constant.TaskBhaResultPath(task.TaskId))
+	outputDirs := make([]string, len(units))
+	for i := range units {
+		outputDirs[i] = path.Join(resultPath, "units", strconv.Itoa(i))
+		unitOpts := append(opts[:len(opts):len(opts)],
+			bha.WithArch(units[i].Arch),
+			bha.WithProgress(t.progressFunc(ctx, task, i, len(units))),
+			bha.WithAssign(t.assignFunc(ctx, task, &units[i])),
+		)
+		if err := t.scan(ctx, task, units[i].Object, outputDirs[i], unitOpts...); err != nil {
+			return fmt.Errorf("scan %s error, %w", units[i].Path, err)
+		}
+	}
+
+	// 结果中的文件路径改写为在上传文件中的路径，日志按扫描单元分段
+	if err := t.mergeUnits(ctx, resultPath, bha.ResultJsonFilename, units, outputDirs, func(w io.Writer) unitMerger {
+		return &resultMerger{enc: bha.NewResultEncoder(w), units: units, image: task.Unpack.ImageDigest()}
+	}); err != nil {
 		return err
 	}
+	for _, filename := range []string{constant.TaskLogFile, constant.TaskAsmFile} {
+		if err := t.mergeUnits(ctx, resultPath, filename, units, outputDirs, func(w io.Writer) unitMerger {
+			return &logMerger{w: w, units: units}
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unitMerger 合并各扫描单元的同名输出文件
+type unitMerger interface {
+	Merge(i int, r io.Reader) error
+	Close() error
+}
 
-	var r []bha.Result
-	if err = json.Unmarshal(data, &r); err != nil {
+// mergeUnits 依次下载各扫描单元的 filename 合并后上传至 resultPath
+func (t *Bha) mergeUnits(ctx context.Context, resultPath, filename string, units []models.TaskFile, outputDirs []string, newMerger func(w io.Writer) unitMerger) error {
+	tempDir, err := utils.MkdirTemp()
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary directory, %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	out, err := os.Create(filepath.Join(tempDir, filename))
+	if err != nil {
 		return err
 	}
+	defer func() { _ = out.Close() }()
+
+	client := minio.New(t.Minio)
+	w := bufio.NewWriter(out)
+	merger := newMerger(w)
+	for i := range units {
+		object := path.Join(outputDirs[i], filename)
+		src := filepath.Join(tempDir, strconv.Itoa(i))
+		if err = client.FGetObject(ctx, object, src); err != nil {
+			return fmt.Errorf("failed to FGetObject %s error, %w", object, err)
+		}
+		if err = mergeFile(merger, i, src); err != nil {
+			return fmt.Errorf("merge %s error, %w", object, err)
+		}
+		_ = os.Remove(src)
+	}
+	if err = merger.Close(); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	if _, err = client.FPutObject(ctx, path.Join(resultPath, filename), out.Name()); err != nil {
+		return fmt.Errorf("upload %s error, %w", filename, err)
+	}
+	return nil
+}
+
+func mergeFile(merger unitMerger, i int, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return merger.Merge(i, bufio.NewReader(f))
+}
+
+type resultMerger struct {
+	enc   *bha.ResultEncoder
+	units []models.TaskFile
+	image string // 容器镜像的 digest
+}
+
+func (m *resultMerger) Merge(i int, r io.Reader) error {
+	return bha.DecodeResult(r, &unitResult{ResultEncoder: m.enc, index: i, path: m.units[i].Path, image: m.image, layer: m.units[i].Layer, abi: m.units[i].Abi})
+}
+
+func (m *resultMerger) Close() error {
+	return m.enc.Close()
+}
+
+type logMerger struct {
+	w     io.Writer
+	units []models.TaskFile
+}
+
+func (m *logMerger) Merge(i int, r io.Reader) error {
+	if _, err := fmt.Fprintf(m.w, "==> %s <==\n", m.units[i].Path); err != nil {
+		return err
+	}
+	_, err := io.Copy(m.w, r)
+	return err
+}
+
+func (m *logMerger) Close() error {
+	return nil
+}
+
+// unitResult 将扫描单元结果中的文件改写为在上传文件中的路径，文件id加上单元序号避免重复
+// 容器镜像中的文件记录镜像 digest 及引入该文件的镜像层，安装包中的 native 库记录 ABI
+type unitResult struct {
+	*bha.ResultEncoder
+	index int
+	path  string
+	image string
+	layer string
+	abi   string
+}
+
+func (u *unitResult) HandleFunc(file *bha.Result, fn *bha.Func) error {
+	return u.ResultEncoder.HandleFunc(u.rewrite(file), fn)
+}
+
+func (u *unitResult) HandleFile(file *bha.Result) error {
+	return u.ResultEncoder.HandleFile(u.rewrite(file))
+}
+
+func (u *unitResult) rewrite(file *bha.Result) *bha.Result {
+	f := *file
+	f.FileId = fmt.Sprintf("%d-%s", u.index, file.FileId)
+	f.Image, f.Layer, f.Abi = u.image, u.layer, u.abi
+
+	// 扫描单元为单个文件，bha server 输出的路径通常为对象路径或文件名
+	name := filepath.ToSlash(file.FilePath)
+	if name == "" || path.Base(name) == path.Base(u.path) {
+		f.FilePath = u.path
+	} else {
+		f.FilePath = path.Join(u.path, name)
+	}
+	return &f
+}
+
+// reuseResult 复制参数相同的已完成任务的扫描结果，未命中或复制失败时返回 false
+func (t *Bha) reuseResult(ctx context.Context, task *models.Task) (bool, error) {
+	cached, err := mongo.NewTask(t.Mongo).FindBhaCached(ctx, task)
+	if err != nil || cached == nil {
+		return false, err
+	}
+
+	src := filepath.ToSlash(constant.TaskBhaResultPath(cached.TaskId)) + "/"
+	dst := filepath.ToSlash(constant.TaskBhaResultPath(task.TaskId)) + "/"
+	client := minio.New(t.Minio)
+
+	var copied bool
+	for object := range client.ListObjects(ctx, src) {
+		if object.Err != nil {
+			return false, object.Err
+		}
+		if _, err = client.CopyObject(ctx, dst+strings.TrimPrefix(object.Key, src), object.Key); err != nil {
+			return false, fmt.Errorf("copy %s error, %w", object.Key, err)
+		}
+		copied = true
+	}
+	// 结果文件已被清理
+	if !copied {
+		return false, nil
+	}
+
+	// 解包出的文件
+	if cached.Unpack != nil {
+		taskFiles := mongo.NewTaskFile(t.Mongo)
+		files, err := taskFiles.FindByTaskId(ctx, cached.TaskId)
+		if err != nil {
+			return false, fmt.Errorf("find task_files error, %w", err)
+		}
+		// 扫描单元复制至当前任务，缓存任务被删除时不影响当前任务
+		var units int
+		for i := range files {
+			files[i].Id = primitive.NilObjectID
+			files[i].TaskId = task.TaskId
+			if files[i].Object == "" {
+				continue
+			}
+			object := filepath.ToSlash(constant.TaskUnitPath(task.TaskId, units, path.Base(files[i].Path)))
+			if _, err = client.CopyObject(ctx, object, files[i].Object); err != nil {
+				return false, fmt.Errorf("copy %s error, %w", files[i].Object, err)
+			}
+			files[i].Object = object
+			units++
+		}
+		if err = taskFiles.DeleteByTaskIds(ctx, []string{task.TaskId}); err != nil {
+			return false, fmt.Errorf("delete task_files error, %w", err)
+		}
+		if err = taskFiles.InsertMany(ctx, files); err != nil {
+			return false, fmt.Errorf("insert task_files error, %w", err)
+		}
+	}
+
+	t.Logger.Infof("task %s reuses bha result of task %s", task.TaskId, cached.TaskId)
+	task.Result = strings.TrimSuffix(dst, "/")
+	task.Unpack = cached.Unpack
+	task.Backend = cached.Backend
+	task.CachedFrom = cached.TaskId
+	task.Progress = &models.TaskProgress{Percent: 100, UpdatedAt: time.Now()}
+	return true, nil
+}
+
+// progressFunc 记录扫描进度至任务，多个扫描单元时按第 unit 个(共 units 个)折算总进度
+func (t *Bha) progressFunc(ctx context.Context, task *models.Task, unit, units int) bha.ProgressFunc {
+	return func(status bhaserver.ScanStatus) {
+		task.Progress = &models.TaskProgress{
+			Percent:   (float64(unit)*100 + status.Progress) / float64(units),
+			Phase:     status.Phase,
+			UpdatedAt: time.Now(),
+		}
+		if err := mongo.NewTask(t.Mongo).UpdateProgress(ctx, task.TaskId, task.Detail.Type, pointer.PAny(task.Progress)); err != nil {
+			t.Logger.Errorf("update task %s progress error, %v", task.TaskId, err)
+		}
+	}
+}
+
+// assignFunc 扫描被分配至后端时立即记录至任务，扫描单元的后端另记录至对应的 task_files
+func (t *Bha) assignFunc(ctx context.Context, task *models.Task, unit *models.TaskFile) bha.AssignFunc {
+	return func(backend string) {
+		task.Backend = backend
+		if err := mongo.NewTask(t.Mongo).UpdateBackend(ctx, task.TaskId, task.Detail.Type, backend); err != nil {
+			t.Logger.Errorf("update task %s backend error, %v", task.TaskId, err)
+		}
+		if unit == nil {
+			return
+		}
+		unit.Backend = backend
+		if err := mongo.NewTaskFile(t.Mongo).UpdateBackend(ctx, task.TaskId, unit.Object, backend); err != nil {
+			t.Logger.Errorf("update task %s unit %s backend error, %v", task.TaskId, unit.Path, err)
+		}
+	}
+}
+
+func (t *Bha) processResult(ctx context.Context, task *models.Task) error {
+	jsonFile, remove, err := services.NewTask(t.Kit).BhaResultFile(ctx, task.TaskId)
+	if err != nil {
+		return err
+	}
+	defer func() { remove() }()
+
+	f, err := os.Open(jsonFile)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
 
 	// write mongo
-	if err = t.SaveResult(ctx, task, r); err != nil {
+	if err = t.SaveResult(ctx, task, bufio.NewReader(f)); err != nil {
 		return err
 	}
 
@@ -122,52 +546,138 @@ func (t *Bha) processResult(ctx context.Context, task *models.Task) error {
 	return nil
 }
 
-func (t *Bha) SaveResult(ctx context.Context, task *models.Task, r []bha.Result) error {
-	var err error
+// SaveResult 流式解析扫描结果并批量写入
+// 写入前清理该任务的历史结果，重复处理同一任务时结果不会重复
+func (t *Bha) SaveResult(ctx context.Context, task *models.Task, r io.Reader) error {
+	if err := services.NewTask(t.Kit).DeleteBhaGenerated(ctx, []string{task.TaskId}); err != nil {
+		return err
+	}
 
-	for _, f := range r {
-		for _, v := range f.FuncS {
-			bhaFunc := models.BhaFunc{
-				TaskId:   task.TaskId,
-				FileId:   f.FileId,
-				FilePath: f.FilePath,
-				Addr:     v.Addr,
-				FName:    v.FName,
-			}
+	// 应用历史研判形成的抑制规则
+	suppressions, err := mongo.NewBhaSuppression(t.Mongo).FindForTask(ctx, task.Name, task.FileHash)
+	if err != nil {
+		return fmt.Errorf("find bha_suppressions error, %w", err)
+	}
 
-			var funcId string
-			funcId, err = mongo.NewBhaFunc(t.Mongo).Insert(ctx, bhaFunc)
-			if err != nil {
-				return err
-			}
+	w := newBhaResultWriter(ctx, t.Mongo, task.TaskId)
+	w.suppressions = models.NewBhaSuppressionIndex(suppressions)
+	if err := bha.DecodeResult(r, w); err != nil {
+		return err
+	}
+	return w.flush()
+}
 
-			var funcResults []models.BhaFuncResult
-			{
-				for _, e := range v.Results {
-					m := models.BhaFuncResult{
-						TaskId:   task.TaskId,
-						FuncId:   funcId,
-						Purl:     e.Purl,
-						Version:  e.Version,
-						Refs:     e.Refs,
-						FName:    e.FName,
-						CVE:      e.CVE,
-						Arch:     e.Arch,
-						OptLevel: e.OptLevel,
-						Sim:      e.Sim,
-					}
-					if len(m.Refs) == 0 {
-						m.Refs = make([]string, 0)
-					}
-					funcResults = append(funcResults, m)
-				}
-			}
+// bhaResultWriter 批量写入函数及函数结果，并统计每个文件的检测情况
+type bhaResultWriter struct {
+	ctx    context.Context
+	taskId string
 
-			if err = mongo.NewBhaFuncResult(t.Mongo).InsertMany(ctx, utils.ConvertToInterfaceSlice(funcResults)); err != nil {
-				return err
-			}
+	files       *mongo.BhaFile
+	funcs       *mongo.BhaFunc
+	funcResults *mongo.BhaFuncResult
+
+	funcBatch       []interface{}
+	funcResultBatch []interface{}
+
+	suppressions *models.BhaSuppressionIndex // 适用于当前任务的抑制规则
+	now          time.Time
+
+	stats bha.FileStats // 当前文件统计，不含被抑制的结果
+}
+
+func newBhaResultWriter(ctx context.Context, client *mongo.Client, taskId string) *bhaResultWriter {
+	return &bhaResultWriter{
+		ctx:         ctx,
+		taskId:      taskId,
+		files:       mongo.NewBhaFile(client),
+		funcs:       mongo.NewBhaFunc(client),
+		funcResults: mongo.NewBhaFuncResult(client),
+		now:         time.Now(),
+	}
+}
+
+func (w *bhaResultWriter) HandleFunc(file *bha.Result, v *bha.Func) error {
+	// 预先生成函数id，函数与结果可在同一批次写入
+	funcId := primitive.NewObjectID()
+	w.funcBatch = append(w.funcBatch, models.BhaFunc{
+		Id:       funcId,
+		TaskId:   w.taskId,
+		FileId:   file.FileId,
+		FileArch: file.FileArch,
+		FilePath: file.FilePath,
+		Addr:     v.Addr,
+		FName:    v.FName,
+	})
+
+	unsuppressed := bha.Func{Addr: v.Addr, FName: v.FName}
+	for _, e := range v.Results {
+		m := models.BhaFuncResult{
+			TaskId:   w.taskId,
+			FuncId:   funcId.Hex(),
+			Purl:     e.Purl,
+			Version:  e.Version,
+			Refs:     e.Refs,
+			FName:    e.FName,
+			CVE:      e.CVE,
+			Arch:     e.Arch,
+			OptLevel: e.OptLevel,
+			Sim:      e.Sim,
+		}
+		if len(m.Refs) == 0 {
+			m.Refs = make([]string, 0)
+		}
+		if rule := w.suppressions.Match(file.FilePath, v.FName, v.Addr, e.CVE, e.Sim); rule != nil {
+			m.SuppressionId = rule.Id.Hex()
+			m.Triage = rule.Triage(w.now)
+		} else {
+			unsuppressed.Results = append(unsuppressed.Results, e)
 		}
+		w.funcResultBatch = append(w.funcResultBatch, m)
+	}
+
+	w.stats.Add(&unsuppressed)
+
+	if len(w.funcBatch) >= bhaFuncBatchSize || len(w.funcResultBatch) >= bhaFuncResultBatchSize {
+		return w.flush()
 	}
+	return nil
+}
+
+func (w *bhaResultWriter) HandleFile(file *bha.Result) error {
+	doc := &models.BhaFile{
+		TaskId:           w.taskId,
+		FileId:           file.FileId,
+		FilePath:         file.FilePath,
+		FileArch:         file.FileArch,
+		Image:            file.Image,
+		Layer:            file.Layer,
+		Abi:              file.Abi,
+		FuncCount:        w.stats.FuncCount,
+		MatchedFuncCount: w.stats.MatchedFuncCount,
+		BestSim:          w.stats.BestSim,
+		CVECount:         w.stats.CVECount(),
+		CVEs:             w.stats.CVEs(),
+	}
+	w.stats = bha.FileStats{}
+
+	if _, err := w.files.Insert(w.ctx, doc); err != nil {
+		return fmt.Errorf("insert bha_files error, %w", err)
+	}
+	return nil
+}
 
+func (w *bhaResultWriter) flush() error {
+	if len(w.funcBatch) > 0 {
+		if err := w.funcs.InsertMany(w.ctx, w.funcBatch); err != nil {
+			return fmt.Errorf("insert bha_funcs error, %w", err)
+		}
+		w.funcBatch = w.funcBatch[:0]
+	}
+	if len(w.funcResultBatch) > 0 {
+		if err := w.funcResults.InsertMany(w.ctx, w.funcResultBatch); err != nil {
+			return fmt.Errorf("insert bha_func_results error, %w", err)
+		}
+		w.funcResultBatch = w.funcResultBatch[:0]
+	}
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 
 var (
 	_ Handler = (*Bha)(nil) // bha server
+	_ Handler = (*Sca)(nil) // 组件识别
 )
 
 type Handler interface {
@@ -0,0 +1,291 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"bin-vul-inspector/app/kit"
+	"bin-vul-inspector/pkg/api/services"
+	"bin-vul-inspector/pkg/bha"
+	"bin-vul-inspector/pkg/constant"
+	"bin-vul-inspector/pkg/minio"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/mongo"
+	"bin-vul-inspector/pkg/sca"
+	"bin-vul-inspector/pkg/unpack"
+	"bin-vul-inspector/pkg/utils"
+)
+
+// Sca 根据版本字符串、SONAME 及符号版本识别组件，并按版本关联漏洞库中的 CVE
+type Sca struct {
+	*kit.Kit
+}
+
+func NewSCA(kit *kit.Kit) *Sca {
+	return &Sca{
+		Kit: kit,
+	}
+}
+
+// scaFileResult 单个文件的组件识别结果
+type scaFileResult struct {
+	FilePath   string          `json:"file_path"`
+	FileArch   string          `json:"file_arch"`
+	Image      string          `json:"image,omitempty"`
+	Layer      string          `json:"layer,omitempty"`
+	Abi        string          `json:"abi,omitempty"`
+	Components []sca.Component `json:"components"`
+}
+
+func (t *Sca) startJob(ctx context.Context, task *models.Task) error {
+	ctx, cancel := context.WithTimeout(ctx, t.Config.Task.GetScaTimeout())
+	defer cancel()
+
+	detector, err := t.detector()
+	if err != nil {
+		task.ErrMsg = "加载组件特征库失败"
+		return err
+	}
+
+	tempDir, err := utils.MkdirTemp()
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary directory, %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	client := minio.New(t.Minio)
+	src := filepath.Join(tempDir, filepath.Base(task.FilePath))
+	if err = client.FGetObject(ctx, task.FilePath, src); err != nil {
+		return fmt.Errorf("failed to FGetObject error, %w", err)
+	}
+
+	files, err := t.unpack(task, src, filepath.Join(tempDir, "unpack"))
+	if err != nil {
+		return err
+	}
+
+	results := make([]scaFileResult, 0, len(files))
+	for i := range files {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		components, err := detector.Detect(files[i].LocalPath)
+		if err != nil {
+			t.Logger.Warnf("task %s detect components in %s error, %v", task.TaskId, files[i].Path, err)
+			continue
+		}
+		if len(components) == 0 {
+			continue
+		}
+		results = append(results, scaFileResult{
+			FilePath:   files[i].Path,
+			FileArch:   files[i].Arch,
+			Image:      task.Unpack.ImageDigest(),
+			Layer:      files[i].Layer,
+			Abi:        files[i].Abi,
+			Components: components,
+		})
+	}
+
+	resultPath := filepath.ToSlash(constant.TaskScaResultPath(task.TaskId))
+	resultFile := filepath.Join(tempDir, constant.ScaResultFile)
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(resultFile, data, 0o644); err != nil {
+		return err
+	}
+	if _, err = client.FPutObject(ctx, path.Join(resultPath, constant.ScaResultFile), resultFile); err != nil {
+		return fmt.Errorf("upload %s error, %w", constant.ScaResultFile, err)
+	}
+	t.Logger.Infof("task %s detected components in %d of %d files", task.TaskId, len(results), len(files))
+
+	task.Result = resultPath
+	return nil
+}
+
+// detector 内置特征库与配置的自定义特征库合并
+func (t *Sca) detector() (*sca.Detector, error) {
+	sigs := sca.DefaultSignatures()
+	if name := t.Config.Sca.Signatures; name != "" {
+		extra, err := sca.LoadSignatures(name)
+		if err != nil {
+			return nil, fmt.Errorf("load signatures %s error, %w", name, err)
+		}
+		sigs = sca.MergeSignatures(sigs, extra)
+	}
+	return sca.NewDetector(sigs, sca.WithMinLength(t.Config.Sca.GetMinLength()))
+}
+
+// unpack 解包上传的压缩包、安装包、固件镜像或容器镜像，返回其中的可执行文件
+// 上传文件不是压缩包时直接识别上传文件，解包出的文件列表由 bha 任务记录
+func (t *Sca) unpack(task *models.Task, src, dst string) ([]unpack.File, error) {
+	cfg := t.Config.Bha.Unpack
+	unpacker := unpack.New(
+		unpack.WithMaxFileCount(cfg.GetMaxFileCount()),
+		unpack.WithMaxFileSize(cfg.GetMaxFileSize()),
+		unpack.WithMaxDepth(cfg.GetMaxDepth()),
+	)
+	var res *unpack.Result
+	var err error
+	if p := task.Detail.ScaParams; p != nil && p.Input == bha.ContainerInput {
+		res, err = unpacker.UnpackContainer(src, dst)
+	} else {
+		res, err = unpacker.Unpack(src, dst)
+	}
+	if err != nil {
+		task.ErrMsg = "解压文件失败"
+		if errors.Is(err, unpack.ErrNotContainer) {
+			task.ErrMsg = "不是有效的容器镜像(docker save 或 OCI 镜像布局的 tar 包)"
+		}
+		return nil, fmt.Errorf("unpack %s error, %w", task.FilePath, err)
+	}
+
+	if res.Archive == "" {
+		// 创建任务时仅识别了文件头，解析失败时保留文件头信息
+		if info, err := unpack.Analyze(src); err == nil {
+			task.Binary = info
+		}
+		file := unpack.File{LocalPath: src}
+		file.Path = path.Base(filepath.ToSlash(task.FilePath))
+		if task.Binary != nil {
+			file.Arch = task.Binary.Arch
+		}
+		return []unpack.File{file}, nil
+	}
+
+	units := res.Units()
+	if len(units) == 0 {
+		task.ErrMsg = "压缩包或固件镜像中未找到可执行文件(ELF、PE、Mach-O)"
+		if res.Image != nil {
+			task.ErrMsg = "容器镜像中未找到可执行文件(ELF、PE、Mach-O)"
+		}
+		if task.Package != "" {
+			task.ErrMsg = "安装包中未找到 native 库"
+		}
+		return nil, fmt.Errorf("no executable found in %s", task.FilePath)
+	}
+	task.Unpack = &models.TaskUnpack{Archive: res.Archive, Image: res.Image, Files: int64(len(res.Files)), Units: int64(len(units))}
+	return units, nil
+}
+
+func (t *Sca) processResult(ctx context.Context, task *models.Task) error {
+	jsonFile, remove, err := services.NewTask(t.Kit).ScaResultFile(ctx, task.TaskId)
+	if err != nil {
+		return err
+	}
+	defer func() { remove() }()
+
+	f, err := os.Open(jsonFile)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	// write mongo
+	if err = t.SaveResult(ctx, task, f); err != nil {
+		return err
+	}
+
+	// update task status
+	task.Status = models.TaskStatusFinished
+	return nil
+}
+
+// SaveResult 按名称及版本聚合各文件识别出的组件，关联受影响的 CVE 后写入
+// 写入前清理该任务的历史结果，重复处理同一任务时结果不会重复
+func (t *Sca) SaveResult(ctx context.Context, task *models.Task, r io.Reader) error {
+	var results []scaFileResult
+	if err := json.NewDecoder(r).Decode(&results); err != nil {
+		return fmt.Errorf("decode sca result error, %w", err)
+	}
+
+	var components []models.ScaComponent
+	index := make(map[string]int)
+	for _, res := range results {
+		for _, c := range res.Components {
+			key := c.Name + "@" + c.Version
+			i, ok := index[key]
+			if !ok {
+				i = len(components)
+				index[key] = i
+				components = append(components, models.ScaComponent{
+					TaskId:  task.TaskId,
+					Name:    c.Name,
+					Version: c.Version,
+					Purl:    c.Purl,
+					Vendor:  c.Vendor,
+					Product: c.Product,
+					CVEs:    make([]string, 0),
+				})
+			}
+
+			file := models.ScaFile{
+				FilePath: res.FilePath,
+				FileArch: res.FileArch,
+				Image:    res.Image,
+				Layer:    res.Layer,
+				Abi:      res.Abi,
+				Evidence: make([]models.ScaEvidence, 0, len(c.Evidence)),
+
+				SymbolVersion: c.SymbolVersion,
+			}
+			for _, e := range c.Evidence {
+				file.Evidence = append(file.Evidence, models.ScaEvidence{Type: e.Type, Value: e.Value})
+			}
+			components[i].Files = append(components[i].Files, file)
+		}
+	}
+
+	if err := t.linkCVEs(ctx, components); err != nil {
+		return err
+	}
+
+	if err := services.NewTask(t.Kit).DeleteScaGenerated(ctx, []string{task.TaskId}); err != nil {
+		return err
+	}
+	return mongo.NewScaComponent(t.Mongo).InsertMany(ctx, components)
+}
+
+// linkCVEs 按 CPE vendor:product 查询漏洞库，版本在受影响范围内的 CVE 关联到组件
+// 未识别出发布版本的组件不关联，符号版本仅为下限，按范围匹配会误报
+func (t *Sca) linkCVEs(ctx context.Context, components []models.ScaComponent) error {
+	vulns := make(map[string][]models.Vulnerability)
+	for i := range components {
+		c := &components[i]
+		if c.Version == "" || c.Vendor == "" || c.Product == "" {
+			continue
+		}
+
+		key := c.Vendor + ":" + c.Product
+		list, ok := vulns[key]
+		if !ok {
+			var err error
+			if list, err = mongo.NewVulnerability(t.Mongo).FindAffected(ctx, c.Vendor, c.Product); err != nil {
+				return fmt.Errorf("find vulnerabilities of %s error, %w", key, err)
+			}
+			vulns[key] = list
+		}
+
+		for j := range list {
+			for _, a := range list[j].Affected {
+				if a.Vendor == c.Vendor && a.Product == c.Product && sca.Affected(c.Version, a) {
+					c.CVEs = append(c.CVEs, list[j].CVE)
+					c.Vulns.Add(&list[j])
+					break
+				}
+			}
+		}
+		sort.Strings(c.CVEs)
+		c.CVECount = int64(len(c.CVEs))
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"bin-vul-inspector/app/kit"
 	"bin-vul-inspector/pkg/api/services/subject"
 	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/bha"
 	"bin-vul-inspector/pkg/constant"
 	"bin-vul-inspector/pkg/minio"
 	"bin-vul-inspector/pkg/models"
@@ -32,10 +33,12 @@ type Job struct {
 	ctxCache           sync.Map
 	limiters           Limiters
 	pool               *Limiter
+	backends           *bha.Pool
 	handler            map[string]Handler
 }
 
 func NewJob(kit *kit.Kit) *Job {
+	backends := NewBackendPool(kit)
 	job := &Job{
 		Kit:                kit,
 		name:               "tasks@job",
@@ -43,8 +46,10 @@ func NewJob(kit *kit.Kit) *Job {
 		subject:            subject.NewCreatedTask(kit.JetStream),
 		configSubject:      subject.NewUpdatedConfig(kit.JetStream),
 		terminatingSubject: subject.NewTerminatingTask(kit.Nats),
+		backends:           backends,
 		handler: map[string]Handler{
-			constant.TypeBha: NewBHA(kit),
+			constant.TypeBha: NewBHA(kit, backends),
+			constant.TypeSca: NewSCA(kit),
 		},
 	}
 
@@ -69,6 +74,7 @@ func (job *Job) Run(ctx context.Context) {
 		job.runConfigConsumer(ctxWithCancel)
 		job.runTasksConsumer(ctxWithCancel)
 		job.runTerminating()
+		job.runHealthCheck(ctxWithCancel)
 
 		ticker := time.NewTicker(10 * time.Second)
 		for {
@@ -273,6 +279,21 @@ func (job *Job) handle(ctx context.Context, task *models.Task) {
 	}
 }
 
+func (job *Job) runHealthCheck(ctx context.Context) {
+	job.wg.Add(1)
+	go func(ctx context.Context) {
+		defer job.wg.Done()
+		defer func() {
+			if e := recover(); e != nil {
+				job.errorf("bha backends health check panic: %v\n%s", e, debug.Stack())
+			}
+		}()
+
+		job.backends.RunHealthCheck(ctx, job.Config.Bha.GetHealthCheckInterval())
+		job.debugf("bha backends health check context canceled")
+	}(ctx)
+}
+
 func (job *Job) runConfigConsumer(ctx context.Context) {
 	job.wg.Add(1)
 	go func(ctx context.Context) {
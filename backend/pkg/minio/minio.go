@@ -8,6 +8,7 @@ import (
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/notification"
 )
 
 type MinIO struct {
@@ -145,6 +146,12 @@ func (c *MinIO) ListObjects(ctx context.Context, dir string) <-chan minio.Object
 	return c.client.ListObjects(ctx, c.bucket, opts)
 }
 
+// ListenObjectCreated 监听对象创建事件，仅MinIO支持
+func (c *MinIO) ListenObjectCreated(ctx context.Context, prefix, suffix string) <-chan notification.Info {
+	events := []string{string(notification.ObjectCreatedAll)}
+	return c.client.ListenBucketNotification(ctx, c.bucket, prefix, suffix, events)
+}
+
 func (c *MinIO) StatObject(ctx context.Context, objectName string) (minio.ObjectInfo, error) {
 	opts := minio.StatObjectOptions{}
 	return c.client.StatObject(ctx, c.bucket, objectName, opts)
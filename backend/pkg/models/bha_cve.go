@@ -0,0 +1,30 @@
+package models
+
+// BhaCVE 按 CVE/Purl/Version 聚合的函数相似性对比结果
+type BhaCVE struct {
+	CVE       string       `json:"cve" bson:"cve"`             // CVE编号
+	Purl      string       `json:"purl" bson:"purl"`           // purl
+	Version   string       `json:"version" bson:"version"`     // 版本
+	BestSim   float64      `json:"best_sim" bson:"best_sim"`   // 最高相似分数
+	Archs     []string     `json:"archs" bson:"archs"`         // 引用函数的架构
+	OptLevels []string     `json:"optlevels" bson:"optlevels"` // 引用函数的优化等级
+	Funcs     []BhaCVEFunc `json:"funcs" bson:"funcs"`         // 匹配的检测文件函数，按相似分数降序
+	Files     []BhaCVEFile `json:"files" bson:"-"`             // 受影响的文件
+
+	Vuln *Vulnerability `json:"vuln,omitempty" bson:"vuln,omitempty"` // 漏洞信息，查询时补充
+}
+
+type BhaCVEFunc struct {
+	FuncId   string  `json:"func_id" bson:"func_id"` // bha func id
+	FName    string  `json:"fname" bson:"-"`         // 检测文件函数名称
+	Addr     string  `json:"addr" bson:"-"`          // 函数地址
+	FileId   string  `json:"file_id" bson:"-"`       // 文件 id
+	FilePath string  `json:"file_path" bson:"-"`     // 文件路径
+	Sim      float64 `json:"sim" bson:"sim"`         // 相似分数
+}
+
+type BhaCVEFile struct {
+	FileId   string `json:"file_id"`   // 文件 id
+	FilePath string `json:"file_path"` // 文件路径
+	FileArch string `json:"file_arch"` // 二进制文件架构
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+)
+
+// 任务对比结果状态
+const (
+	BhaDiffFixed      = "fixed"      // 基准任务中存在，对比任务中消失，可能已修复
+	BhaDiffIntroduced = "introduced" // 对比任务中新增
+	BhaDiffChanged    = "changed"    // 相似分数变化超过阈值
+	BhaDiffUnchanged  = "unchanged"  // 相似分数变化未超过阈值
+)
+
+func BhaDiffStatuses() []string {
+	return []string{BhaDiffFixed, BhaDiffIntroduced, BhaDiffChanged, BhaDiffUnchanged}
+}
+
+// BhaDiff 两个bha任务漏洞匹配结果的对比
+type BhaDiff struct {
+	Base      BhaDiffTask    `json:"base"`      // 基准任务
+	Head      BhaDiffTask    `json:"head"`      // 对比任务
+	Threshold float64        `json:"threshold"` // 相似分数变化阈值
+	Summary   BhaDiffSummary `json:"summary"`   // 统计
+	Items     []BhaDiffItem  `json:"items"`     // 明细
+}
+
+type BhaDiffTask struct {
+	TaskId    string    `json:"task_id"`    // 任务id
+	Name      string    `json:"name"`       // 任务名称
+	FileHash  string    `json:"file_hash"`  // 文件hash
+	CreatedAt time.Time `json:"created_at"` // 创建时间
+}
+
+type BhaDiffSummary struct {
+	Fixed          int64    `json:"fixed"`           // 消失的匹配数
+	Introduced     int64    `json:"introduced"`      // 新增的匹配数
+	Changed        int64    `json:"changed"`         // 相似分数变化超过阈值的匹配数
+	Unchanged      int64    `json:"unchanged"`       // 相似分数变化未超过阈值的匹配数
+	FixedCVEs      []string `json:"fixed_cves"`      // 对比任务中完全消失的CVE
+	IntroducedCVEs []string `json:"introduced_cves"` // 基准任务中不存在的CVE
+}
+
+// BhaDiffItem 同一文件同一函数同一CVE的匹配对比
+type BhaDiffItem struct {
+	Status string         `json:"status"`         // 状态
+	CVE    string         `json:"cve"`            // CVE编号
+	Delta  float64        `json:"delta"`          // 相似分数变化，对比任务减基准任务
+	Base   *BhaFinding    `json:"base,omitempty"` // 基准任务中的匹配
+	Head   *BhaFinding    `json:"head,omitempty"` // 对比任务中的匹配
+	Vuln   *Vulnerability `json:"vuln,omitempty"` // 漏洞信息，查询时补充
+}
+
+// Finding 对比任务中的匹配，不存在时为基准任务中的匹配
+func (item *BhaDiffItem) Finding() *BhaFinding {
+	if item.Head != nil {
+		return item.Head
+	}
+	return item.Base
+}
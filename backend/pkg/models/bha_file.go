@@ -0,0 +1,31 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BhaFile struct {
+	Id               primitive.ObjectID `json:"id" bson:"_id,omitempty"`                      // id
+	TaskId           string             `json:"task_id" bson:"task_id"`                       // 任务id
+	FileId           string             `json:"file_id" bson:"file_id"`                       // 文件 id
+	FilePath         string             `json:"file_path" bson:"file_path"`                   // 文件路径
+	FileArch         string             `json:"file_arch" bson:"file_arch"`                   // 二进制文件架构
+	Image            string             `json:"image,omitempty" bson:"image,omitempty"`       // 容器镜像 digest
+	Layer            string             `json:"layer,omitempty" bson:"layer,omitempty"`       // 引入该文件的镜像层 digest
+	Abi              string             `json:"abi,omitempty" bson:"abi,omitempty"`           // 安装包中 native 库的 ABI
+	FuncCount        int64              `json:"func_count" bson:"func_count"`                 // 函数数
+	MatchedFuncCount int64              `json:"matched_func_count" bson:"matched_func_count"` // 存在匹配结果的函数数
+	BestSim          float64            `json:"best_sim" bson:"best_sim"`                     // 最高相似分数
+	CVECount         int64              `json:"cve_count" bson:"cve_count"`                   // 匹配到的CVE数(去重)
+	CVEs             []string           `json:"-" bson:"cves,omitempty"`                      // 匹配到的CVE，用于按 ABI 去重统计
+}
+
+// BhaAbiSummary 安装包中同一 ABI 的 native 库的扫描结果统计
+type BhaAbiSummary struct {
+	Abi              string  `json:"abi" bson:"_id"`                               // ABI
+	Arch             string  `json:"arch" bson:"-"`                                // ABI 对应的架构
+	FileCount        int64   `json:"file_count" bson:"file_count"`                 // 文件数
+	MatchedFuncCount int64   `json:"matched_func_count" bson:"matched_func_count"` // 存在匹配结果的函数数
+	BestSim          float64 `json:"best_sim" bson:"best_sim"`                     // 最高相似分数
+	CVECount         int64   `json:"cve_count" bson:"cve_count"`                   // 匹配到的CVE数(同一 ABI 内去重)
+}
@@ -0,0 +1,23 @@
+package models
+
+// BhaFinding 检测文件函数与CVE的匹配结果，同一函数同一CVE仅保留相似分数最高的匹配
+// 用于导出报告
+type BhaFinding struct {
+	TaskId   string  `json:"task_id" bson:"task_id"`                       // 任务id
+	FileId   string  `json:"file_id" bson:"file_id"`                       // 文件 id
+	FilePath string  `json:"file_path" bson:"file_path"`                   // 文件路径
+	FileArch string  `json:"file_arch" bson:"file_arch"`                   // 二进制文件架构
+	FuncId   string  `json:"func_id" bson:"func_id"`                       // bha func id
+	Addr     string  `json:"addr" bson:"addr"`                             // 函数地址
+	FName    string  `json:"fname" bson:"fname"`                           // 检测文件函数名称
+	RefFName string  `json:"ref_fname" bson:"ref_fname"`                   // 匹配文件函数名称
+	CVE      string  `json:"cve" bson:"cve"`                               // CVE编号
+	Purl     string  `json:"purl,omitempty" bson:"purl,omitempty"`         // purl
+	Version  string  `json:"version,omitempty" bson:"version,omitempty"`   // 版本
+	Arch     string  `json:"arch,omitempty" bson:"arch,omitempty"`         // 架构
+	OptLevel string  `json:"optlevel,omitempty" bson:"optlevel,omitempty"` // 优化等级
+	Sim      float64 `json:"sim" bson:"sim"`                               // 相似分数
+
+	Triage        *BhaTriage `json:"triage,omitempty" bson:"triage,omitempty"`                 // 研判信息
+	SuppressionId string     `json:"suppression_id,omitempty" bson:"suppression_id,omitempty"` // 命中的抑制规则id
+}
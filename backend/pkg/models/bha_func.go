@@ -13,3 +13,29 @@ type BhaFunc struct {
 	Addr     string             `json:"addr" bson:"addr"`           // 函数地址
 	FName    string             `json:"fname" bson:"fname"`         // 检测文件函数名称
 }
+
+// BhaFuncMatches 函数及其匹配结果，结果按相似分数降序
+type BhaFuncMatches struct {
+	Func    BhaFunc         `json:"func" bson:"func"`
+	Results []BhaFuncResult `json:"results" bson:"results"`
+}
+
+// BhaFuncRow 函数与匹配结果的扁平化记录，用于导出
+// 无匹配结果的函数 Rank 为0，匹配结果字段为空
+type BhaFuncRow struct {
+	FileId   string   `json:"file_id"`   // 文件 id
+	FilePath string   `json:"file_path"` // 文件路径
+	FileArch string   `json:"file_arch"` // 二进制文件架构
+	FuncId   string   `json:"func_id"`   // bha func id
+	Addr     string   `json:"addr"`      // 函数地址
+	FName    string   `json:"fname"`     // 检测文件函数名称
+	Rank     int      `json:"rank"`      // 匹配结果在函数内按相似分数的排名，从1开始
+	RefFName string   `json:"ref_fname"` // 匹配文件函数名称
+	Purl     string   `json:"purl"`      // purl
+	Version  string   `json:"version"`   // 版本
+	CVE      string   `json:"cve"`       // CVE编号
+	Arch     string   `json:"arch"`      // 架构
+	OptLevel string   `json:"optlevel"`  // 优化等级
+	Sim      float64  `json:"sim"`       // 相似分数
+	Refs     []string `json:"refs"`      // 引用
+}
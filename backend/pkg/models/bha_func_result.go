@@ -16,4 +16,9 @@ type BhaFuncResult struct {
 	Arch     string             `json:"arch,omitempty" bson:"arch,omitempty"`         // 架构
 	OptLevel string             `json:"optlevel,omitempty" bson:"optlevel,omitempty"` // 优化等级
 	Sim      float64            `json:"sim" bson:"sim"`                               // 相似分数
+	Triage   *BhaTriage         `json:"triage,omitempty" bson:"triage,omitempty"`     // 研判信息
+
+	SuppressionId string `json:"suppression_id,omitempty" bson:"suppression_id,omitempty"` // 命中的抑制规则id
+
+	Vuln *Vulnerability `json:"vuln,omitempty" bson:"-"` // 漏洞信息，查询时补充
 }
@@ -0,0 +1,22 @@
+package models
+
+// BhaSearchHit 跨任务检索命中的函数相似性对比结果
+type BhaSearchHit struct {
+	TaskId   string  `json:"task_id" bson:"task_id"`                       // 任务id
+	TaskName string  `json:"task_name" bson:"task_name"`                   // 任务名称
+	FileId   string  `json:"file_id" bson:"file_id"`                       // 文件 id
+	FilePath string  `json:"file_path" bson:"file_path"`                   // 文件路径
+	FileArch string  `json:"file_arch" bson:"file_arch"`                   // 二进制文件架构
+	FuncId   string  `json:"func_id" bson:"func_id"`                       // bha func id
+	Addr     string  `json:"addr" bson:"addr"`                             // 函数地址
+	FName    string  `json:"fname" bson:"fname"`                           // 检测文件函数名称
+	RefFName string  `json:"ref_fname" bson:"ref_fname"`                   // 匹配文件函数名称
+	CVE      string  `json:"cve,omitempty" bson:"cve,omitempty"`           // CVE编号
+	Purl     string  `json:"purl,omitempty" bson:"purl,omitempty"`         // purl
+	Version  string  `json:"version,omitempty" bson:"version,omitempty"`   // 版本
+	Arch     string  `json:"arch,omitempty" bson:"arch,omitempty"`         // 架构
+	OptLevel string  `json:"optlevel,omitempty" bson:"optlevel,omitempty"` // 优化等级
+	Sim      float64 `json:"sim" bson:"sim"`                               // 相似分数
+
+	Vuln *Vulnerability `json:"vuln,omitempty" bson:"-"` // 漏洞信息，查询时补充
+}
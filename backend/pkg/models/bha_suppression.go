@@ -0,0 +1,117 @@
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BhaSuppression 抑制规则，扫描结果入库时命中规则的匹配结果自动标记研判状态，默认不展示
+//
+// 规则按文件名、函数名或函数地址、CVE及相似分数范围匹配，可限定项目(任务名称)或文件hash
+type BhaSuppression struct {
+	Id            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	FileName      string             `json:"file_name" bson:"file_name"`         // 文件名，不含目录，为空时匹配全部文件
+	FName         string             `json:"fname" bson:"fname"`                 // 检测文件函数名称，为空时不限
+	Addr          string             `json:"addr" bson:"addr"`                   // 函数地址，为空时不限
+	CVE           string             `json:"cve" bson:"cve"`                     // CVE编号，为空时不限
+	MinSim        float64            `json:"min_sim" bson:"min_sim"`             // 最小相似分数
+	MaxSim        float64            `json:"max_sim" bson:"max_sim"`             // 最大相似分数
+	Project       string             `json:"project" bson:"project"`             // 适用的项目，即任务名称，为空时不限
+	FileHash      string             `json:"file_hash" bson:"file_hash"`         // 适用的文件hash，为空时不限
+	Status        string             `json:"status" bson:"status"`               // 命中后的研判状态 false_positive, wont_fix
+	Justification string             `json:"justification" bson:"justification"` // 研判依据
+	Comment       string             `json:"comment" bson:"comment"`             // 原因
+	Author        string             `json:"author" bson:"author"`               // 创建人
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`       // 创建时间
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`       // 更新时间
+}
+
+func SuppressionStatuses() []string {
+	return []string{TriageFalsePositive, TriageWontFix}
+}
+
+// Match 匹配结果是否命中规则，不校验项目及文件hash
+func (s *BhaSuppression) Match(filePath, fname, addr, cve string, sim float64) bool {
+	if (s.CVE != "" && s.CVE != cve) || sim < s.MinSim || sim > s.MaxSim {
+		return false
+	}
+	if s.FileName != "" && s.FileName != path.Base(filePath) {
+		return false
+	}
+	if s.FName != "" && s.FName != fname {
+		return false
+	}
+	if s.Addr != "" && NormalizeAddr(s.Addr) != NormalizeAddr(addr) {
+		return false
+	}
+	return true
+}
+
+// BhaSuppressionIndex 按CVE索引的抑制规则，避免每个匹配结果遍历全部规则
+type BhaSuppressionIndex struct {
+	rules    []BhaSuppression
+	byCVE    map[string][]int // CVE -> 规则下标，升序
+	wildcard []int            // 未指定CVE的规则下标，升序
+}
+
+func NewBhaSuppressionIndex(rules []BhaSuppression) *BhaSuppressionIndex {
+	idx := &BhaSuppressionIndex{rules: rules, byCVE: make(map[string][]int)}
+	for i := range rules {
+		if rules[i].CVE == "" {
+			idx.wildcard = append(idx.wildcard, i)
+		} else {
+			idx.byCVE[rules[i].CVE] = append(idx.byCVE[rules[i].CVE], i)
+		}
+	}
+	return idx
+}
+
+// Match 查找命中的第一条规则，与按顺序遍历全部规则的结果一致
+func (idx *BhaSuppressionIndex) Match(filePath, fname, addr, cve string, sim float64) *BhaSuppression {
+	if idx == nil {
+		return nil
+	}
+
+	found := -1
+	for _, bucket := range [][]int{idx.byCVE[cve], idx.wildcard} {
+		for _, i := range bucket {
+			if found >= 0 && i > found {
+				break
+			}
+			if idx.rules[i].Match(filePath, fname, addr, cve, sim) {
+				found = i
+				break
+			}
+		}
+	}
+	if found < 0 {
+		return nil
+	}
+	return &idx.rules[found]
+}
+
+// Triage 命中规则后的研判信息
+func (s *BhaSuppression) Triage(now time.Time) *BhaTriage {
+	content := "suppressed by rule " + s.Id.Hex()
+	if s.Comment != "" {
+		content += ": " + s.Comment
+	}
+	return &BhaTriage{
+		Status:        s.Status,
+		Justification: s.Justification,
+		Comments:      []BhaTriageComment{{Author: s.Author, Content: content, CreatedAt: now}},
+		UpdatedAt:     now,
+	}
+}
+
+// NormalizeAddr 统一函数地址格式，去除0x前缀及前导0
+func NormalizeAddr(addr string) string {
+	addr = strings.TrimPrefix(strings.ToLower(addr), "0x")
+	if addr = strings.TrimLeft(addr, "0"); addr == "" {
+		return "0"
+	}
+	return addr
+}
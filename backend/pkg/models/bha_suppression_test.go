@@ -0,0 +1,52 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+func TestBhaSuppression_Match(t *testing.T) {
+	rule := models.BhaSuppression{
+		FileName: "libssl.so",
+		Addr:     "0x0004A2F0",
+		CVE:      "CVE-2014-0160",
+		MinSim:   0,
+		MaxSim:   0.9,
+	}
+
+	assert.True(t, rule.Match("usr/lib/libssl.so", "sub_4a2f0", "4a2f0", "CVE-2014-0160", 0.85))
+	assert.True(t, rule.Match("firmware-2.0/lib/libssl.so", "", "0x4a2f0", "CVE-2014-0160", 0.9))
+
+	assert.False(t, rule.Match("usr/lib/libssl.so", "sub_4a2f0", "4a2f0", "CVE-2014-0160", 0.93), "sim out of range")
+	assert.False(t, rule.Match("usr/lib/libcrypto.so", "sub_4a2f0", "4a2f0", "CVE-2014-0160", 0.85), "file name")
+	assert.False(t, rule.Match("usr/lib/libssl.so", "sub_4a2f0", "4a2f4", "CVE-2014-0160", 0.85), "addr")
+	assert.False(t, rule.Match("usr/lib/libssl.so", "sub_4a2f0", "4a2f0", "CVE-2014-0161", 0.85), "cve")
+
+	// 未指定文件名及地址时按函数名匹配全部文件
+	rule = models.BhaSuppression{FName: "tls1_process_heartbeat", CVE: "CVE-2014-0160", MaxSim: 1}
+	assert.True(t, rule.Match("a/b/c", "tls1_process_heartbeat", "1000", "CVE-2014-0160", 1))
+	assert.False(t, rule.Match("a/b/c", "dtls1_process_heartbeat", "1000", "CVE-2014-0160", 1))
+}
+
+func TestBhaSuppressionIndex_Match(t *testing.T) {
+	rules := []models.BhaSuppression{
+		{FName: "f1", CVE: "CVE-2014-0160", MaxSim: 0.5},
+		{FName: "f1", MaxSim: 1},
+		{FName: "f1", CVE: "CVE-2014-0160", MaxSim: 1},
+		{FName: "f2", CVE: "CVE-2016-2105", MaxSim: 1},
+	}
+	idx := models.NewBhaSuppressionIndex(rules)
+
+	// 与按顺序遍历的结果一致
+	assert.Equal(t, &rules[0], idx.Match("a.so", "f1", "1000", "CVE-2014-0160", 0.4))
+	assert.Equal(t, &rules[1], idx.Match("a.so", "f1", "1000", "CVE-2014-0160", 0.8), "wildcard cve")
+	assert.Equal(t, &rules[1], idx.Match("a.so", "f1", "1000", "CVE-2016-2106", 0.8), "wildcard cve")
+	assert.Equal(t, &rules[3], idx.Match("a.so", "f2", "1000", "CVE-2016-2105", 0.8))
+	assert.Nil(t, idx.Match("a.so", "f2", "1000", "CVE-2014-0160", 0.8))
+
+	var empty *models.BhaSuppressionIndex
+	assert.Nil(t, empty.Match("a.so", "f1", "1000", "CVE-2014-0160", 0.4))
+}
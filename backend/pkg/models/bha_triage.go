@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// 研判状态
+const (
+	TriageUntriaged     = "untriaged"      // 未研判
+	TriageConfirmed     = "confirmed"      // 确认存在漏洞
+	TriageFalsePositive = "false_positive" // 误报
+	TriageWontFix       = "wont_fix"       // 确认存在，不修复
+)
+
+func TriageStatuses() []string {
+	return []string{TriageUntriaged, TriageConfirmed, TriageFalsePositive, TriageWontFix}
+}
+
+// 研判依据，与 CycloneDX VEX 的 justification 一致
+const (
+	JustificationCodeNotPresent               = "code_not_present"
+	JustificationCodeNotReachable             = "code_not_reachable"
+	JustificationRequiresConfiguration        = "requires_configuration"
+	JustificationRequiresDependency           = "requires_dependency"
+	JustificationRequiresEnvironment          = "requires_environment"
+	JustificationProtectedByCompiler          = "protected_by_compiler"
+	JustificationProtectedAtRuntime           = "protected_at_runtime"
+	JustificationProtectedAtPerimeter         = "protected_at_perimeter"
+	JustificationProtectedByMitigatingControl = "protected_by_mitigating_control"
+)
+
+func TriageJustifications() []string {
+	return []string{
+		JustificationCodeNotPresent,
+		JustificationCodeNotReachable,
+		JustificationRequiresConfiguration,
+		JustificationRequiresDependency,
+		JustificationRequiresEnvironment,
+		JustificationProtectedByCompiler,
+		JustificationProtectedAtRuntime,
+		JustificationProtectedAtPerimeter,
+		JustificationProtectedByMitigatingControl,
+	}
+}
+
+// BhaTriage 函数匹配结果的研判信息
+type BhaTriage struct {
+	Status        string             `json:"status" bson:"status"`                                   // 研判状态
+	Justification string             `json:"justification,omitempty" bson:"justification,omitempty"` // 研判依据
+	Assignee      string             `json:"assignee,omitempty" bson:"assignee,omitempty"`           // 处理人
+	Comments      []BhaTriageComment `json:"comments,omitempty" bson:"comments,omitempty"`           // 评论
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`                           // 更新时间
+}
+
+type BhaTriageComment struct {
+	Author    string    `json:"author" bson:"author"`         // 评论人
+	Content   string    `json:"content" bson:"content"`       // 内容
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // 评论时间
+}
+
+// TriageStatus 研判状态，未研判时为 untriaged
+func (t *BhaTriage) TriageStatus() string {
+	if t == nil || t.Status == "" {
+		return TriageUntriaged
+	}
+	return t.Status
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScaComponent 按名称及版本聚合的组件识别结果
+type ScaComponent struct {
+	Id       primitive.ObjectID `json:"id" bson:"_id,omitempty"`    // id
+	TaskId   string             `json:"task_id" bson:"task_id"`     // 任务id
+	Name     string             `json:"name" bson:"name"`           // 组件名称
+	Version  string             `json:"version" bson:"version"`     // 版本，无法确定时为空
+	Purl     string             `json:"purl" bson:"purl"`           // purl
+	Vendor   string             `json:"vendor" bson:"vendor"`       // CPE vendor
+	Product  string             `json:"product" bson:"product"`     // CPE product
+	Files    []ScaFile          `json:"files" bson:"files"`         // 包含该组件的文件
+	CVEs     []string           `json:"cves" bson:"cves"`           // 影响该版本的CVE
+	CVECount int64              `json:"cve_count" bson:"cve_count"` // CVE数
+	Vulns    VulnSummary        `json:"vulns" bson:"vulns"`         // 漏洞严重等级统计
+}
+
+// ScaFile 包含组件的文件及识别依据
+type ScaFile struct {
+	FilePath      string        `json:"file_path" bson:"file_path"`                               // 文件路径
+	FileArch      string        `json:"file_arch" bson:"file_arch"`                               // 二进制文件架构
+	Image         string        `json:"image,omitempty" bson:"image,omitempty"`                   // 容器镜像 digest
+	Layer         string        `json:"layer,omitempty" bson:"layer,omitempty"`                   // 引入该文件的镜像层 digest
+	Abi           string        `json:"abi,omitempty" bson:"abi,omitempty"`                       // 安装包中 native 库的 ABI
+	SymbolVersion string        `json:"symbol_version,omitempty" bson:"symbol_version,omitempty"` // 未识别出版本时共享库符号版本中的最高版本，仅说明组件不低于该版本
+	Evidence      []ScaEvidence `json:"evidence" bson:"evidence"`                                 // 识别依据
+}
+
+// ScaEvidence 组件识别依据
+type ScaEvidence struct {
+	Type  string `json:"type" bson:"type"`   // string 可打印字符串, soname 共享库SONAME, symbol 符号版本
+	Value string `json:"value" bson:"value"` // 匹配的内容
+}
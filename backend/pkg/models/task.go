@@ -62,23 +62,39 @@ func TaskModes() []int {
 }
 
 type Task struct {
-	Id          string     `json:"id" bson:"_id,omitempty"`
-	Mode        int        `bson:"mode"`
-	TaskId      string     `bson:"task_id"`       // 任务id
-	Source      string     `bson:"source"`        // 任务来源
-	Detail      TaskDetail `bson:"detail"`        // 标记任务详细信息，根据类型为sca或task，值会有不同，
-	Status      string     `bson:"status"`        // 任务状态
-	Result      string     `bson:"result"`        // 扫描结果保存路径
-	DebugMsg    string     `bson:"debug_message"` // debug错误信息
-	ErrCode     int        `bson:"err_code"`      // 错误码，取值参考全局错误码
-	ErrMsg      string     `bson:"err_message"`   // 错误信息，仅当错误码不为0时有效
-	Name        string     `bson:"name"`          // 名称
-	Description string     `bson:"description"`   // 描述
-	FileHash    string     `bson:"file_hash"`     // 文件hash
-	FilePath    string     `bson:"file_path"`     // 待扫描文件的保存路径
-	FileSize    int64      `bson:"file_size"`     // 文件大小，单位为字节
-	CreatedAt   time.Time  `bson:"created_at"`    // 创建时间
-	ModifiedAt  time.Time  `bson:"modified_at"`   // 修改时间
+	Id          string        `json:"id" bson:"_id,omitempty"`
+	Mode        int           `bson:"mode"`
+	TaskId      string        `bson:"task_id"`            // 任务id
+	Source      string        `bson:"source"`             // 任务来源
+	Detail      TaskDetail    `bson:"detail"`             // 标记任务详细信息，根据类型为sca或task，值会有不同，
+	Status      string        `bson:"status"`             // 任务状态
+	Result      string        `bson:"result"`             // 扫描结果保存路径
+	DebugMsg    string        `bson:"debug_message"`      // debug错误信息
+	ErrCode     int           `bson:"err_code"`           // 错误码，取值参考全局错误码
+	ErrMsg      string        `bson:"err_message"`        // 错误信息，仅当错误码不为0时有效
+	Name        string        `bson:"name"`               // 名称
+	Description string        `bson:"description"`        // 描述
+	FileHash    string        `bson:"file_hash"`          // 文件SHA-256
+	FileSHA1    string        `bson:"file_sha1"`          // 文件SHA-1
+	FileMD5     string        `bson:"file_md5"`           // 文件MD5
+	FilePath    string        `bson:"file_path"`          // 待扫描文件的保存路径
+	FileSize    int64         `bson:"file_size"`          // 文件大小，单位为字节
+	Progress    *TaskProgress `bson:"progress,omitempty"` // 扫描进度
+	Backend     string        `bson:"backend,omitempty"`  // 执行扫描的后端，多个扫描单元时为最近分配的后端，各单元的后端见 task_files
+	CreatedAt   time.Time     `bson:"created_at"`         // 创建时间
+	ModifiedAt  time.Time     `bson:"modified_at"`        // 修改时间
+
+	Package    string      `bson:"package,omitempty"`     // 上传的安装包类型 apk, aab, aar, jar
+	Binary     *BinaryInfo `bson:"binary,omitempty"`      // 上传的可执行文件的预分析结果
+	CachedFrom string      `bson:"cached_from,omitempty"` // 复用扫描结果的任务id
+	Unpack     *TaskUnpack `bson:"unpack,omitempty"`      // 上传压缩包的解包统计，文件明细见 task_files
+}
+
+// TaskProgress 扫描进度
+type TaskProgress struct {
+	Percent   float64   `json:"percent" bson:"percent"`       // 进度百分比 0-100
+	Phase     string    `json:"phase" bson:"phase"`           // 当前阶段 decompile, embed, match
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"` // 更新时间
 }
 
 type SastParams struct {
@@ -91,12 +107,22 @@ type SastParams struct {
 type ScaParams struct {
 	OptionalFeature      []string `json:"--optional-feature" bson:"optional_feature"`
 	ReachabilityAnalysis bool     `json:"reachability_analysis" bson:"reachability_analysis,omitempty"`
+	Input                string   `json:"input" bson:"sca_input,omitempty"` // 输入类型 file, container，与 bha 参数同时内嵌于 detail，字段名需区分
 }
 
 type BhaParams struct {
-	DetectionMethod string `json:"detection_method" bson:"detection_method"` // 检测方式 fast, intelligent
-	Algorithm       string `json:"algorithm" bson:"algorithm"`               // 检测算法 sfs,ssfs,bsd
-	ModelId         string `json:"model_id" bson:"model_id"`                 // 模型id
+	DetectionMethod string   `json:"detection_method" bson:"detection_method"`       // 检测方式 fast, intelligent
+	Algorithm       string   `json:"algorithm" bson:"algorithm"`                     // 检测算法 sfs,ssfs,bsd
+	ModelId         string   `json:"model_id" bson:"model_id"`                       // 模型id
+	TopN            uint     `json:"top_n" bson:"top_n"`                             // 每个函数保留的候选结果数
+	MinimumSim      float32  `json:"minimum_sim" bson:"minimum_sim"`                 // 最小相似度 [0,1]
+	ModelHash       string   `json:"model_hash" bson:"model_hash,omitempty"`         // 模型文件内容hash，扫描时记录
+	NoCache         bool     `json:"no_cache" bson:"no_cache,omitempty"`             // 不复用相同文件的扫描结果，强制重新扫描
+	Input           string   `json:"input" bson:"input,omitempty"`                   // 输入类型 file, container
+	MaxFileCount    uint64   `json:"max_file_count" bson:"max_file_count,omitempty"` // 解包文件数上限，扫描时记录
+	MaxFileSize     uint64   `json:"max_file_size" bson:"max_file_size,omitempty"`   // 解包单个文件大小上限，扫描时记录
+	MaxDepth        int      `json:"max_depth" bson:"max_depth,omitempty"`           // 嵌套解包深度上限，扫描时记录
+	Arches          []string `json:"arches" bson:"arches,omitempty"`                 // 支持的架构，升序，扫描时记录
 }
 
 type TaskDetail struct {
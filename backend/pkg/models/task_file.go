@@ -0,0 +1,82 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 解包文件类型
+const (
+	TaskFileBinary  = "binary"  // 可执行文件，作为扫描单元
+	TaskFileArchive = "archive" // 压缩包
+	TaskFileImage   = "image"   // 固件镜像及其中的文件系统
+	TaskFileOther   = "other"   // 其他文件
+)
+
+// 可执行文件格式
+const (
+	BinaryFormatELF   = "elf"
+	BinaryFormatPE    = "pe"
+	BinaryFormatMachO = "macho"
+)
+
+// TaskFile 上传文件解包出的文件
+type TaskFile struct {
+	Id      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TaskId  string             `json:"task_id" bson:"task_id"`                     // 任务id
+	Path    string             `json:"path" bson:"path"`                           // 在上传文件中的路径，嵌套压缩包内的文件以压缩包路径为前缀，文件系统内的文件以 <镜像路径>/<类型>@<偏移> 为前缀
+	Size    int64              `json:"size" bson:"size"`                           // 文件大小，单位为字节
+	Type    string             `json:"type" bson:"type"`                           // 文件类型 binary, archive, image, other
+	Format  string             `json:"format,omitempty" bson:"format,omitempty"`   // 可执行文件格式 elf, pe, macho，文件系统的类型 squashfs, cpio, jffs2, ubi, ubifs
+	Object  string             `json:"object,omitempty" bson:"object,omitempty"`   // 扫描单元在对象存储中的路径
+	Skipped string             `json:"skipped,omitempty" bson:"skipped,omitempty"` // 未扫描的原因
+	Layer   string             `json:"layer,omitempty" bson:"layer,omitempty"`     // 容器镜像中引入该文件的镜像层 digest
+	Abi     string             `json:"abi,omitempty" bson:"abi,omitempty"`         // 安装包中 native 库的 ABI，如 arm64-v8a
+	Arch    string             `json:"arch,omitempty" bson:"arch,omitempty"`       // 扫描时传给 bha server 的架构，安装包中的 native 库为 ABI 对应的架构
+	Binary  *BinaryInfo        `json:"binary,omitempty" bson:"binary,omitempty"`   // 可执行文件的预分析结果
+	Backend string             `json:"backend,omitempty" bson:"backend,omitempty"` // 执行扫描单元的后端
+}
+
+// BinaryInfo 扫描前对可执行文件的预分析结果
+type BinaryInfo struct {
+	Format    string   `json:"format" bson:"format"`                           // 格式 elf, pe, macho
+	Arch      string   `json:"arch" bson:"arch"`                               // 架构 x86, x86_64, arm, arm64, mips, mips64 等
+	Bits      int      `json:"bits" bson:"bits"`                               // 位数 32, 64
+	Endian    string   `json:"endian" bson:"endian"`                           // 字节序 little, big
+	Stripped  bool     `json:"stripped" bson:"stripped"`                       // 是否已去除符号表
+	BuildId   string   `json:"build_id,omitempty" bson:"build_id,omitempty"`   // ELF 的 GNU build-id，Mach-O 的 UUID
+	Soname    string   `json:"soname,omitempty" bson:"soname,omitempty"`       // 动态库名称，Mach-O 为 install name
+	Needed    []string `json:"needed,omitempty" bson:"needed,omitempty"`       // 依赖的动态库
+	Compilers []string `json:"compilers,omitempty" bson:"compilers,omitempty"` // .comment 段中的编译器信息
+	FuncCount int64    `json:"func_count" bson:"func_count"`                   // 函数符号数，去除符号表时为动态符号表中的函数数
+}
+
+// IsUnit 是否为扫描单元
+func (f *TaskFile) IsUnit() bool {
+	return f.Type == TaskFileBinary && f.Skipped == ""
+}
+
+// TaskUnpack 上传压缩包或固件镜像的解包统计
+type TaskUnpack struct {
+	Archive string `json:"archive" bson:"archive"` // 压缩包类型，安装包为 apk, aab, aar, jar，固件镜像为 firmware，容器镜像为 docker 或 oci
+	Files   int64  `json:"files" bson:"files"`     // 文件数
+	Units   int64  `json:"units" bson:"units"`     // 扫描单元数
+	Skipped int64  `json:"skipped" bson:"skipped"` // 未扫描的文件数
+
+	Image *TaskImage `json:"image,omitempty" bson:"image,omitempty"` // 上传的容器镜像
+}
+
+// TaskImage 上传的容器镜像
+type TaskImage struct {
+	Digest   string   `json:"digest" bson:"digest"`                         // 镜像 digest，OCI 为镜像清单的 digest，docker save 为镜像 id
+	Tags     []string `json:"tags,omitempty" bson:"tags,omitempty"`         // 镜像名称
+	Platform string   `json:"platform,omitempty" bson:"platform,omitempty"` // 平台，如 linux/amd64
+	Layers   []string `json:"layers" bson:"layers"`                         // 按应用顺序的镜像层 digest
+}
+
+// ImageDigest 上传的容器镜像的 digest，不是容器镜像时为空
+func (u *TaskUnpack) ImageDigest() string {
+	if u == nil || u.Image == nil {
+		return ""
+	}
+	return u.Image.Digest
+}
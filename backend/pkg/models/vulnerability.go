@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 漏洞严重等级
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityNone     = "none"
+	SeverityUnknown  = "unknown"
+)
+
+// Vulnerability 离线漏洞库
+// 由 NVD JSON 2.0 数据源导入，CISA KEV 及 EPSS 数据源补充
+type Vulnerability struct {
+	Id             primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	CVE            string             `json:"cve" bson:"cve"`                                           // CVE编号
+	Description    string             `json:"description" bson:"description"`                           // 描述(英文)
+	CWEs           []string           `json:"cwes" bson:"cwes"`                                         // CWE
+	CVSS           []CVSS             `json:"cvss" bson:"cvss"`                                         // 各版本CVSS评分
+	Severity       string             `json:"severity" bson:"severity"`                                 // 严重等级，取优先版本的CVSS评分
+	Score          float64            `json:"score" bson:"score"`                                       // CVSS基础分数，取优先版本的CVSS评分
+	Published      time.Time          `json:"published" bson:"published"`                               // 发布时间
+	LastModified   time.Time          `json:"last_modified" bson:"last_modified"`                       // 最后修改时间
+	KEV            bool               `json:"kev" bson:"kev"`                                           // 是否在CISA KEV目录中
+	KEVDateAdded   *time.Time         `json:"kev_date_added,omitempty" bson:"kev_date_added,omitempty"` // 加入KEV目录的时间
+	EPSS           float64            `json:"epss" bson:"epss"`                                         // EPSS分数
+	EPSSPercentile float64            `json:"epss_percentile" bson:"epss_percentile"`                   // EPSS百分位
+	Affected       []VulnAffected     `json:"affected,omitempty" bson:"affected,omitempty"`             // 受影响的产品及版本，取自NVD CPE配置
+}
+
+// VulnAffected 受影响的产品版本范围，Version 不为空时仅该版本受影响
+type VulnAffected struct {
+	Vendor         string `json:"vendor" bson:"vendor"`                                       // CPE vendor
+	Product        string `json:"product" bson:"product"`                                     // CPE product
+	Version        string `json:"version,omitempty" bson:"version,omitempty"`                 // 受影响的版本
+	StartIncluding string `json:"start_including,omitempty" bson:"start_including,omitempty"` // 起始版本(含)
+	StartExcluding string `json:"start_excluding,omitempty" bson:"start_excluding,omitempty"` // 起始版本(不含)
+	EndIncluding   string `json:"end_including,omitempty" bson:"end_including,omitempty"`     // 结束版本(含)
+	EndExcluding   string `json:"end_excluding,omitempty" bson:"end_excluding,omitempty"`     // 结束版本(不含)
+}
+
+type CVSS struct {
+	Version   string  `json:"version" bson:"version"`       // CVSS版本 2.0,3.0,3.1,4.0
+	Vector    string  `json:"vector" bson:"vector"`         // 向量
+	BaseScore float64 `json:"base_score" bson:"base_score"` // 基础分数
+	Severity  string  `json:"severity" bson:"severity"`     // 严重等级
+	Source    string  `json:"source" bson:"source"`         // 评分来源
+	Type      string  `json:"type" bson:"type"`             // Primary, Secondary
+}
+
+// VulnKEV CISA KEV 目录条目
+type VulnKEV struct {
+	CVE       string
+	DateAdded time.Time
+}
+
+// VulnEPSS EPSS 评分
+type VulnEPSS struct {
+	CVE        string
+	EPSS       float64
+	Percentile float64
+}
+
+// VulnSummary 漏洞严重等级统计
+type VulnSummary struct {
+	Total    int64 `json:"total"`    // CVE总数
+	Critical int64 `json:"critical"` // 严重
+	High     int64 `json:"high"`     // 高危
+	Medium   int64 `json:"medium"`   // 中危
+	Low      int64 `json:"low"`      // 低危
+	Unknown  int64 `json:"unknown"`  // 未知，包括漏洞库中不存在的CVE
+	KEV      int64 `json:"kev"`      // 在CISA KEV目录中
+}
+
+// Add 统计漏洞，vuln为nil表示漏洞库中不存在
+func (s *VulnSummary) Add(vuln *Vulnerability) {
+	s.Total++
+	if vuln == nil {
+		s.Unknown++
+		return
+	}
+
+	switch vuln.Severity {
+	case SeverityCritical:
+		s.Critical++
+	case SeverityHigh:
+		s.High++
+	case SeverityMedium:
+		s.Medium++
+	case SeverityLow:
+		s.Low++
+	default:
+		s.Unknown++
+	}
+	if vuln.KEV {
+		s.KEV++
+	}
+}
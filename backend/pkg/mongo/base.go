@@ -44,6 +44,11 @@ func newBase(client *Client, collection string, opts ...Option) *base {
 	return o
 }
 
+// IsDuplicateKey 是否为唯一索引冲突
+func IsDuplicateKey(err error) bool {
+	return mongo.IsDuplicateKeyError(err)
+}
+
 func (o *base) database() *mongo.Database {
 	return o.client.Database(o.db)
 }
@@ -198,6 +203,31 @@ func aggregate[T any](ctx context.Context, col *mongo.Collection, pipeline mongo
 	return list, nil
 }
 
+// aggregateEach 逐条处理聚合结果，避免一次性加载全部结果
+func aggregateEach[T any](ctx context.Context, col *mongo.Collection, pipeline mongo.Pipeline, fn func(*T) error, opts ...*options.AggregateOptions) (err error) {
+	opt := &options.AggregateOptions{}
+	opt.SetBatchSize(BatchSize)
+	opt.SetAllowDiskUse(true)
+	opts = append(opts, opt)
+
+	var cursor *mongo.Cursor
+	if cursor, err = col.Aggregate(ctx, pipeline, opts...); err != nil {
+		return err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	for cursor.Next(ctx) {
+		var m T
+		if err = cursor.Decode(&m); err != nil {
+			return err
+		}
+		if err = fn(&m); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
 func ObjectID(id string) primitive.ObjectID {
 	oid, _ := primitive.ObjectIDFromHex(id)
 	return oid
@@ -0,0 +1,103 @@
+package mongo
+
+import (
+	"context"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/pointer"
+)
+
+type BhaFile struct {
+	*base
+}
+
+func NewBhaFile(client *Client) *BhaFile {
+	return &BhaFile{
+		base: newBase(client, bhaFilesCollection),
+	}
+}
+
+func (c *BhaFile) ListFile(ctx context.Context, params dto.BhaFileListReq) (total int64, list []models.BhaFile, err error) {
+	var filter bson.M
+	{
+		filter = bson.M{"task_id": params.TaskId}
+		if params.Q != "" {
+			filter["file_path"] = bson.M{"$regex": regexp.QuoteMeta(params.Q), "$options": "i"}
+		}
+		if params.Abi != "" {
+			filter["abi"] = params.Abi
+		}
+	}
+
+	total, err = c.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 漏洞多的文件优先
+	findOptions := &options.FindOptions{
+		Sort: bson.D{
+			{Key: "cve_count", Value: models.Desc},
+			{Key: "best_sim", Value: models.Desc},
+			{Key: "file_path", Value: models.Asc},
+		},
+		Skip:  pointer.Of(params.Skip()),
+		Limit: pointer.Of(params.PageSize),
+	}
+
+	if list, err = find[models.BhaFile](ctx, c.collection(), filter, findOptions); err != nil {
+		return 0, nil, err
+	}
+
+	return total, list, nil
+}
+
+// AbiSummary 按 ABI 统计安装包中 native 库的扫描结果，同一 CVE 出现在多个文件中时只计一次
+// 未记录 cves 的历史结果按文件的 cve_count 累加
+func (c *BhaFile) AbiSummary(ctx context.Context, taskId string) ([]models.BhaAbiSummary, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"task_id": taskId, "abi": bson.M{"$exists": true, "$ne": ""}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":                "$abi",
+			"file_count":         bson.M{"$sum": 1},
+			"matched_func_count": bson.M{"$sum": "$matched_func_count"},
+			"best_sim":           bson.M{"$max": "$best_sim"},
+			"cves":               bson.M{"$addToSet": bson.M{"$ifNull": bson.A{"$cves", bson.A{}}}},
+			"legacy_cve_count": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{bson.M{"$type": "$cves"}, "missing"}}, "$cve_count", 0,
+			}}},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"file_count":         1,
+			"matched_func_count": 1,
+			"best_sim":           1,
+			"cve_count": bson.M{"$add": bson.A{
+				bson.M{"$size": bson.M{"$reduce": bson.M{
+					"input":        "$cves",
+					"initialValue": bson.A{},
+					"in":           bson.M{"$setUnion": bson.A{"$$value", "$$this"}},
+				}}},
+				"$legacy_cve_count",
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": models.Asc}}},
+	}
+	return aggregate[models.BhaAbiSummary](ctx, c.collection(), pipeline)
+}
+
+func (c *BhaFile) FindByTaskId(ctx context.Context, taskId string) ([]models.BhaFile, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "file_path", Value: models.Asc}})
+	return find[models.BhaFile](ctx, c.collection(), bson.M{"task_id": taskId}, findOptions)
+}
+
+func (c *BhaFile) DeleteByTaskIds(ctx context.Context, ids []string) (err error) {
+	filter := bson.M{"task_id": bson.M{"$in": ids}}
+	_, err = c.collection().DeleteMany(ctx, filter)
+	return err
+}
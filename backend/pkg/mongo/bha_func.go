@@ -5,6 +5,8 @@ import (
 	"regexp"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"bin-vul-inspector/pkg/api/v1/dto"
@@ -26,11 +28,30 @@ func (c *BhaFunc) ListFunc(ctx context.Context, params dto.BhaFuncListReq) (tota
 	var filter bson.M
 	{
 		filter = bson.M{"task_id": params.TaskId}
+		if params.FileId != "" {
+			filter["file_id"] = params.FileId
+		}
 		if params.Q != "" {
 			filter["fname"] = bson.M{"$regex": regexp.QuoteMeta(params.Q), "$options": "i"}
 		}
 	}
 
+	// 按研判条件过滤时在服务端关联匹配结果，避免加载全部函数id
+	if params.TriageStatus != "" || params.Assignee != "" {
+		pipeline := append(mongo.Pipeline{{{Key: "$match", Value: filter}}}, triageLookup(params.TaskId, params.BhaTriageFilter, params.Suppressed)...)
+		if total, err = c.CountDocumentsWithPipeline(ctx, pipeline); err != nil {
+			return 0, nil, err
+		}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$skip", Value: params.Skip()}},
+			bson.D{{Key: "$limit", Value: params.PageSize}},
+		)
+		if list, err = aggregate[models.BhaFunc](ctx, c.collection(), pipeline); err != nil {
+			return 0, nil, err
+		}
+		return total, list, nil
+	}
+
 	total, err = c.CountDocuments(ctx, filter)
 	if err != nil {
 		return 0, nil, err
@@ -48,6 +69,10 @@ func (c *BhaFunc) ListFunc(ctx context.Context, params dto.BhaFuncListReq) (tota
 	return total, list, nil
 }
 
+func (c *BhaFunc) FindByIds(ctx context.Context, ids []primitive.ObjectID) ([]models.BhaFunc, error) {
+	return find[models.BhaFunc](ctx, c.collection(), bson.M{"_id": bson.M{"$in": ids}})
+}
+
 func (c *BhaFunc) DeleteByTaskIds(ctx context.Context, ids []string) (err error) {
 	filter := bson.M{"task_id": bson.M{"$in": ids}}
 	_, err = c.collection().DeleteMany(ctx, filter)
@@ -3,15 +3,24 @@ package mongo
 import (
 	"context"
 	"regexp"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/constant"
 	"bin-vul-inspector/pkg/models"
 	"bin-vul-inspector/pkg/pointer"
+	"bin-vul-inspector/pkg/utils"
 )
 
+// exportBatchSize 导出时游标每批读取的文档数
+const exportBatchSize = 1000
+
 type BhaFuncResult struct {
 	*base
 }
@@ -26,6 +35,7 @@ func (c *BhaFuncResult) ListFuncResult(ctx context.Context, params dto.BhaFuncRe
 	var filter bson.M
 	{
 		filter = bson.M{"task_id": params.TaskId, "func_id": params.FuncId}
+		suppressionFilter(filter, params.Suppressed)
 	}
 
 	var pipeline mongo.Pipeline
@@ -38,8 +48,11 @@ func (c *BhaFuncResult) ListFuncResult(ctx context.Context, params dto.BhaFuncRe
 		if !pointer.IsNil(params.TopN) {
 			pipeline = append(pipeline, bson.D{{Key: "$limit", Value: pointer.PAny(params.TopN)}})
 		}
+		postFilter := triageFilter(params.BhaTriageFilter)
 		if params.Q != "" {
-			postFilter := bson.M{"fname": bson.M{"$regex": regexp.QuoteMeta(params.Q), "$options": "i"}}
+			postFilter["fname"] = bson.M{"$regex": regexp.QuoteMeta(params.Q), "$options": "i"}
+		}
+		if len(postFilter) > 0 {
 			pipeline = append(pipeline, bson.D{{Key: "$match", Value: postFilter}})
 		}
 	}
@@ -58,8 +71,632 @@ func (c *BhaFuncResult) ListFuncResult(ctx context.Context, params dto.BhaFuncRe
 	return total, list, nil
 }
 
+// ListCVE 按 CVE/Purl/Version 聚合函数相似性对比结果
+func (c *BhaFuncResult) ListCVE(ctx context.Context, params dto.BhaCVEListReq) (total int64, list []models.BhaCVE, err error) {
+	var filter bson.M
+	{
+		filter = bson.M{"task_id": params.TaskId, "cve": bson.M{"$nin": bson.A{nil, ""}}}
+		if params.Q != "" {
+			regex := bson.M{"$regex": regexp.QuoteMeta(params.Q), "$options": "i"}
+			filter["$or"] = bson.A{bson.M{"cve": regex}, bson.M{"purl": regex}}
+		}
+		suppressionFilter(filter, params.Suppressed)
+	}
+
+	// 合并多个数组为去重后的数组
+	union := func(field string) bson.M {
+		return bson.M{"$reduce": bson.M{
+			"input":        field,
+			"initialValue": bson.A{},
+			"in":           bson.M{"$setUnion": bson.A{"$$value", "$$this"}},
+		}}
+	}
+
+	var pipeline mongo.Pipeline
+	{
+		match := bson.D{{Key: "$match", Value: filter}}
+		// 同一函数取最高相似分数
+		groupByFunc := bson.D{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"cve":     "$cve",
+				"purl":    "$purl",
+				"version": "$version",
+				"func_id": "$func_id",
+			},
+			"sim":       bson.M{"$max": "$sim"},
+			"archs":     bson.M{"$addToSet": "$arch"},
+			"optlevels": bson.M{"$addToSet": "$optlevel"},
+		}}}
+		sortBySim := bson.D{{Key: "$sort", Value: bson.M{"sim": models.Desc}}}
+		groupByCVE := bson.D{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"cve":     "$_id.cve",
+				"purl":    "$_id.purl",
+				"version": "$_id.version",
+			},
+			"best_sim":  bson.M{"$max": "$sim"},
+			"funcs":     bson.M{"$push": bson.M{"func_id": "$_id.func_id", "sim": "$sim"}},
+			"archs":     bson.M{"$push": "$archs"},
+			"optlevels": bson.M{"$push": "$optlevels"},
+		}}}
+		project := bson.D{{Key: "$project", Value: bson.M{
+			"_id":       0,
+			"cve":       "$_id.cve",
+			"purl":      "$_id.purl",
+			"version":   "$_id.version",
+			"best_sim":  1,
+			"funcs":     1,
+			"archs":     union("$archs"),
+			"optlevels": union("$optlevels"),
+		}}}
+		pipeline = mongo.Pipeline{match, groupByFunc, sortBySim, groupByCVE, project}
+	}
+
+	if total, err = c.CountDocumentsWithPipeline(ctx, pipeline); err != nil {
+		return 0, nil, err
+	}
+
+	var sort bson.D
+	{
+		switch params.SortBy {
+		case dto.BhaCVESortBySeverity:
+			sort = bson.D{{Key: "vuln.score", Value: models.Desc}}
+		case dto.BhaCVESortByEPSS:
+			sort = bson.D{{Key: "vuln.epss", Value: models.Desc}}
+		}
+		sort = append(sort,
+			bson.E{Key: "best_sim", Value: models.Desc},
+			bson.E{Key: "cve", Value: models.Asc},
+			bson.E{Key: "purl", Value: models.Asc},
+			bson.E{Key: "version", Value: models.Asc},
+		)
+	}
+
+	// 关联漏洞库
+	lookupVuln := mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{
+			"from":         vulnerabilitiesCollection,
+			"localField":   "cve",
+			"foreignField": "cve",
+			"as":           "vuln",
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$vuln", "preserveNullAndEmptyArrays": true}}},
+	}
+	page := mongo.Pipeline{
+		{{Key: "$sort", Value: sort}},
+		{{Key: "$skip", Value: params.Skip()}},
+		{{Key: "$limit", Value: params.PageSize}},
+	}
+	if params.SortBy == dto.BhaCVESortBySim {
+		// 按相似分数排序时仅关联当前页
+		pipeline = append(append(pipeline, page...), lookupVuln...)
+	} else {
+		pipeline = append(append(pipeline, lookupVuln...), page...)
+	}
+
+	opts := options.Aggregate().SetAllowDiskUse(true)
+	if list, err = aggregate[models.BhaCVE](ctx, c.collection(), pipeline, opts); err != nil {
+		return 0, nil, err
+	}
+
+	if err = c.fillCVEFuncs(ctx, list); err != nil {
+		return 0, nil, err
+	}
+
+	return total, list, nil
+}
+
+// fillCVEFuncs 补充函数及受影响的文件信息
+func (c *BhaFuncResult) fillCVEFuncs(ctx context.Context, list []models.BhaCVE) error {
+	var funcIds []string
+	for i := range list {
+		for _, f := range list[i].Funcs {
+			funcIds = append(funcIds, f.FuncId)
+		}
+	}
+	if len(funcIds) == 0 {
+		return nil
+	}
+
+	objIds := make([]primitive.ObjectID, 0, len(funcIds))
+	for _, id := range utils.UniqueSlice(funcIds) {
+		objIds = append(objIds, ObjectID(id))
+	}
+	filter := bson.M{"_id": bson.M{"$in": objIds}}
+	funcs, err := find[models.BhaFunc](ctx, c.database().Collection(bhaFuncsCollection), filter)
+	if err != nil {
+		return err
+	}
+	funcMap := make(map[string]models.BhaFunc, len(funcs))
+	for _, f := range funcs {
+		funcMap[f.Id.Hex()] = f
+	}
+
+	for i := range list {
+		item := &list[i]
+		item.Archs = nonEmpty(item.Archs)
+		item.OptLevels = nonEmpty(item.OptLevels)
+		item.Files = make([]models.BhaCVEFile, 0)
+
+		files := make(map[string]struct{})
+		for j := range item.Funcs {
+			f, ok := funcMap[item.Funcs[j].FuncId]
+			if !ok {
+				continue
+			}
+			item.Funcs[j].FName = f.FName
+			item.Funcs[j].Addr = f.Addr
+			item.Funcs[j].FileId = f.FileId
+			item.Funcs[j].FilePath = f.FilePath
+
+			if _, ok = files[f.FileId]; !ok {
+				files[f.FileId] = struct{}{}
+				item.Files = append(item.Files, models.BhaCVEFile{
+					FileId:   f.FileId,
+					FilePath: f.FilePath,
+					FileArch: f.FileArch,
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// EachFinding 逐条处理任务的函数与CVE匹配结果，按文件路径、函数地址排序
+// suppressed 为 false 时不含被抑制的结果
+func (c *BhaFuncResult) EachFinding(ctx context.Context, taskId string, suppressed bool, fn func(*models.BhaFinding) error) error {
+	filter := bson.M{"task_id": taskId, "cve": bson.M{"$nin": bson.A{nil, ""}}}
+	suppressionFilter(filter, suppressed)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "func_id", Value: models.Asc},
+			{Key: "cve", Value: models.Asc},
+			{Key: "sim", Value: models.Desc},
+		}}},
+		// 同一函数同一CVE取相似分数最高的匹配
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"func_id": "$func_id", "cve": "$cve"},
+			"task_id":  bson.M{"$first": "$task_id"},
+			"fname":    bson.M{"$first": "$fname"},
+			"purl":     bson.M{"$first": "$purl"},
+			"version":  bson.M{"$first": "$version"},
+			"arch":     bson.M{"$first": "$arch"},
+			"optlevel": bson.M{"$first": "$optlevel"},
+			"sim":      bson.M{"$first": "$sim"},
+			"triage":   bson.M{"$first": "$triage"},
+
+			// 任一匹配被抑制时即视为被抑制，不依赖相似分数最高的匹配
+			"suppression_id": bson.M{"$max": "$suppression_id"},
+		}}},
+		{{Key: "$addFields", Value: bson.M{"func_oid": bson.M{"$toObjectId": "$_id.func_id"}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         bhaFuncsCollection,
+			"localField":   "func_oid",
+			"foreignField": "_id",
+			"as":           "func",
+		}}},
+		{{Key: "$unwind", Value: "$func"}},
+		{{Key: "$project", Value: bson.M{
+			"_id":       0,
+			"task_id":   1,
+			"file_id":   "$func.file_id",
+			"file_path": "$func.file_path",
+			"file_arch": "$func.file_arch",
+			"func_id":   "$_id.func_id",
+			"addr":      "$func.addr",
+			"fname":     "$func.fname",
+			"ref_fname": "$fname",
+			"cve":       "$_id.cve",
+			"purl":      1,
+			"version":   1,
+			"arch":      1,
+			"optlevel":  1,
+			"sim":       1,
+			"triage":    1,
+
+			"suppression_id": 1,
+
+			// 十六进制地址去除0x前缀及前导0后，按长度、字符串排序即按数值排序
+			"addr_key": bson.M{"$ltrim": bson.M{"input": bson.M{"$toLower": "$func.addr"}, "chars": "0x"}},
+		}}},
+		{{Key: "$addFields", Value: bson.M{"addr_len": bson.M{"$strLenCP": "$addr_key"}}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "file_path", Value: models.Asc},
+			{Key: "addr_len", Value: models.Asc},
+			{Key: "addr_key", Value: models.Asc},
+			{Key: "cve", Value: models.Asc},
+		}}},
+		{{Key: "$project", Value: bson.M{"addr_key": 0, "addr_len": 0}}},
+	}
+
+	return aggregateEach[models.BhaFinding](ctx, c.collection(), pipeline, fn)
+}
+
+// EachFuncMatches 遍历任务中存在匹配结果的函数，按文件路径、函数地址排序，不含被抑制的结果
+func (c *BhaFuncResult) EachFuncMatches(ctx context.Context, taskId string, fn func(*models.BhaFuncMatches) error) error {
+	filter := bson.M{"task_id": taskId}
+	suppressionFilter(filter, false)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "func_id", Value: models.Asc},
+			{Key: "sim", Value: models.Desc},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     "$func_id",
+			"results": bson.M{"$push": "$$ROOT"},
+		}}},
+		{{Key: "$addFields", Value: bson.M{"func_oid": bson.M{"$toObjectId": "$_id"}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         bhaFuncsCollection,
+			"localField":   "func_oid",
+			"foreignField": "_id",
+			"as":           "func",
+		}}},
+		{{Key: "$unwind", Value: "$func"}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "func": 1, "results": 1}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "func.file_path", Value: models.Asc},
+			{Key: "func.addr", Value: models.Asc},
+		}}},
+	}
+
+	return aggregateEach[models.BhaFuncMatches](ctx, c.collection(), pipeline, fn)
+}
+
+// EachFuncRow 遍历任务的函数及其匹配结果，按函数id排序，同一函数的匹配结果按相似分数降序
+//
+// 函数与匹配结果分别以游标读取后归并，内存占用与数据量无关
+func (c *BhaFuncResult) EachFuncRow(ctx context.Context, params dto.BhaFuncResultExportReq, fn func(*models.BhaFuncRow) error) (err error) {
+	funcOpts := options.Find().SetSort(bson.M{"_id": models.Asc}).SetBatchSize(exportBatchSize)
+	funcCur, err := c.database().Collection(bhaFuncsCollection).Find(ctx, bson.M{"task_id": params.TaskId}, funcOpts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = funcCur.Close(ctx) }()
+
+	filter := bson.M{"task_id": params.TaskId}
+	if !pointer.IsNil(params.MinSim) {
+		filter["sim"] = bson.M{"$gte": pointer.PAny(params.MinSim)}
+	}
+	suppressionFilter(filter, params.Suppressed)
+	resultOpts := options.Find().
+		SetSort(bson.D{{Key: "func_id", Value: models.Asc}, {Key: "sim", Value: models.Desc}}).
+		SetBatchSize(exportBatchSize)
+	resultCur, err := c.collection().Find(ctx, filter, resultOpts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resultCur.Close(ctx) }()
+
+	// 当前待归并的匹配结果
+	var result *models.BhaFuncResult
+	nextResult := func() error {
+		result = nil
+		if resultCur.Next(ctx) {
+			result = &models.BhaFuncResult{}
+			return resultCur.Decode(result)
+		}
+		return resultCur.Err()
+	}
+	if err = nextResult(); err != nil {
+		return err
+	}
+
+	q := strings.ToLower(params.Q)
+	for funcCur.Next(ctx) {
+		var f models.BhaFunc
+		if err = funcCur.Decode(&f); err != nil {
+			return err
+		}
+		funcId := f.Id.Hex()
+		row := models.BhaFuncRow{
+			FileId:   f.FileId,
+			FilePath: f.FilePath,
+			FileArch: f.FileArch,
+			FuncId:   funcId,
+			Addr:     f.Addr,
+			FName:    f.FName,
+		}
+
+		// ObjectID的hex字符串与ObjectID排序一致
+		rank := 0
+		for result != nil && result.FuncId <= funcId {
+			if result.FuncId == funcId {
+				rank++
+				if (pointer.IsNil(params.TopN) || rank <= int(pointer.PAny(params.TopN))) &&
+					strings.Contains(strings.ToLower(result.FName), q) {
+					r := row
+					r.Rank = rank
+					r.RefFName = result.FName
+					r.Purl = result.Purl
+					r.Version = result.Version
+					r.CVE = result.CVE
+					r.Arch = result.Arch
+					r.OptLevel = result.OptLevel
+					r.Sim = result.Sim
+					r.Refs = result.Refs
+					if err = fn(&r); err != nil {
+						return err
+					}
+				}
+			}
+			if err = nextResult(); err != nil {
+				return err
+			}
+		}
+
+		if rank == 0 && params.Unmatched {
+			if err = fn(&row); err != nil {
+				return err
+			}
+		}
+	}
+	return funcCur.Err()
+}
+
+func (c *BhaFuncResult) FindById(ctx context.Context, taskId, id string) (*models.BhaFuncResult, error) {
+	return findOne[models.BhaFuncResult](ctx, c.collection(), bson.M{"task_id": taskId, "_id": ObjectID(id)})
+}
+
+func (c *BhaFuncResult) FindByIds(ctx context.Context, taskId string, ids []primitive.ObjectID) ([]models.BhaFuncResult, error) {
+	return find[models.BhaFuncResult](ctx, c.collection(), bson.M{"task_id": taskId, "_id": bson.M{"$in": ids}})
+}
+
+// UpdateTriage 更新任务中指定匹配结果的研判信息
+func (c *BhaFuncResult) UpdateTriage(ctx context.Context, taskId string, ids []primitive.ObjectID, req dto.BhaTriageReq) (matched int64, err error) {
+	now := time.Now()
+	set := bson.M{"triage.updated_at": now}
+	unset := bson.M{}
+	if req.Status != nil {
+		set["triage.status"] = *req.Status
+	}
+	if req.Justification != nil {
+		if *req.Justification == "" {
+			unset["triage.justification"] = ""
+		} else {
+			set["triage.justification"] = *req.Justification
+		}
+	}
+	if req.Assignee != nil {
+		if *req.Assignee == "" {
+			unset["triage.assignee"] = ""
+		} else {
+			set["triage.assignee"] = *req.Assignee
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if req.Comment != "" {
+		update["$push"] = bson.M{"triage.comments": models.BhaTriageComment{
+			Author:    req.Author,
+			Content:   req.Comment,
+			CreatedAt: now,
+		}}
+	}
+
+	filter := bson.M{"task_id": taskId, "_id": bson.M{"$in": ids}}
+	res, err := c.collection().UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
+}
+
+// SetSuppression 标记任务中命中抑制规则的匹配结果，与入库时命中规则的结果一致默认不再展示
+func (c *BhaFuncResult) SetSuppression(ctx context.Context, taskId string, ids []primitive.ObjectID, suppressionId string) error {
+	filter := bson.M{"task_id": taskId, "_id": bson.M{"$in": ids}}
+	_, err := c.collection().UpdateMany(ctx, filter, bson.M{"$set": bson.M{"suppression_id": suppressionId}})
+	return err
+}
+
+// triageLookup 仅保留存在符合研判条件的匹配结果的函数，与 ListFuncResult 一致默认不含被抑制的结果
+// 每个函数只需确认是否存在一条匹配结果
+func triageLookup(taskId string, params dto.BhaTriageFilter, suppressed bool) mongo.Pipeline {
+	match := triageFilter(params)
+	match["task_id"] = taskId
+	suppressionFilter(match, suppressed)
+
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{
+			"from": bhaFuncResultsCollection,
+			"let":  bson.M{"func_id": bson.M{"$toString": "$_id"}},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$func_id", "$$func_id"}}}},
+				bson.M{"$match": match},
+				bson.M{"$limit": 1},
+				bson.M{"$project": bson.M{"_id": 1}},
+			},
+			"as": "triage_results",
+		}}},
+		{{Key: "$match", Value: bson.M{"triage_results.0": bson.M{"$exists": true}}}},
+		{{Key: "$project", Value: bson.M{"triage_results": 0}}},
+	}
+}
+
+// triageFilter 研判信息过滤条件，未研判包括无研判信息的结果
+func triageFilter(params dto.BhaTriageFilter) bson.M {
+	filter := bson.M{}
+	switch params.TriageStatus {
+	case "":
+	case models.TriageUntriaged:
+		filter["triage.status"] = bson.M{"$in": bson.A{nil, models.TriageUntriaged}}
+	default:
+		filter["triage.status"] = params.TriageStatus
+	}
+	if params.Assignee != "" {
+		filter["triage.assignee"] = params.Assignee
+	}
+	return filter
+}
+
+// suppressionFilter 默认不展示被抑制规则命中的结果
+func suppressionFilter(filter bson.M, suppressed bool) {
+	if !suppressed {
+		filter["suppression_id"] = bson.M{"$exists": false}
+	}
+}
+
+// DistinctCVEs 任务匹配到的CVE(去重)，suppressed 为 false 时不含被抑制的结果
+func (c *BhaFuncResult) DistinctCVEs(ctx context.Context, taskId string, suppressed bool) ([]string, error) {
+	filter := bson.M{"task_id": taskId, "cve": bson.M{"$nin": bson.A{nil, ""}}}
+	suppressionFilter(filter, suppressed)
+	values, err := c.collection().Distinct(ctx, "cve", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cves := make([]string, 0, len(values))
+	for _, v := range values {
+		if cve, ok := v.(string); ok {
+			cves = append(cves, cve)
+		}
+	}
+	return cves, nil
+}
+
 func (c *BhaFuncResult) DeleteByTaskIds(ctx context.Context, ids []string) (err error) {
 	filter := bson.M{"task_id": bson.M{"$in": ids}}
 	_, err = c.collection().DeleteMany(ctx, filter)
 	return err
 }
+
+// Search 跨任务检索函数相似性对比结果
+func (c *BhaFuncResult) Search(ctx context.Context, params dto.BhaSearchReq) (total int64, list []models.BhaSearchHit, err error) {
+	var filter bson.M
+	{
+		filter = bson.M{}
+		if params.CVE != "" {
+			filter["cve"] = params.CVE
+		}
+		if params.Purl != "" {
+			// 前缀匹配可使用索引
+			filter["purl"] = bson.M{"$regex": "^" + regexp.QuoteMeta(params.Purl)}
+		}
+		if params.Version != "" {
+			filter["version"] = params.Version
+		}
+
+		sim := bson.M{}
+		if !pointer.IsNil(params.MinSim) {
+			sim["$gte"] = pointer.PAny(params.MinSim)
+		}
+		if !pointer.IsNil(params.MaxSim) {
+			sim["$lte"] = pointer.PAny(params.MaxSim)
+		}
+		if len(sim) > 0 {
+			filter["sim"] = sim
+		}
+		suppressionFilter(filter, params.Suppressed)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+	}
+	if params.FName != "" {
+		pipeline = searchByFName(filter, params.FName)
+	}
+
+	if total, err = c.CountDocumentsWithPipeline(ctx, pipeline); err != nil {
+		return 0, nil, err
+	}
+
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "sim", Value: models.Desc}, {Key: "_id", Value: models.Asc}}}},
+		bson.D{{Key: "$skip", Value: params.Skip()}},
+		bson.D{{Key: "$limit", Value: params.PageSize}},
+		// 关联检测文件函数
+		bson.D{{Key: "$addFields", Value: bson.M{"func_oid": bson.M{"$toObjectId": "$func_id"}}}},
+		bson.D{{Key: "$lookup", Value: bson.M{
+			"from":         bhaFuncsCollection,
+			"localField":   "func_oid",
+			"foreignField": "_id",
+			"as":           "func",
+		}}},
+		bson.D{{Key: "$unwind", Value: bson.M{"path": "$func", "preserveNullAndEmptyArrays": true}}},
+		// 关联任务名称
+		bson.D{{Key: "$lookup", Value: bson.M{
+			"from": tasksCollection,
+			"let":  bson.M{"task_id": "$task_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{
+					"$expr":       bson.M{"$eq": bson.A{"$task_id", "$$task_id"}},
+					"detail.type": constant.TypeBha,
+				}},
+				bson.M{"$project": bson.M{"name": 1}},
+			},
+			"as": "task",
+		}}},
+		bson.D{{Key: "$unwind", Value: bson.M{"path": "$task", "preserveNullAndEmptyArrays": true}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":       0,
+			"task_id":   1,
+			"task_name": "$task.name",
+			"file_id":   "$func.file_id",
+			"file_path": "$func.file_path",
+			"file_arch": "$func.file_arch",
+			"func_id":   1,
+			"addr":      "$func.addr",
+			"fname":     "$func.fname",
+			"ref_fname": "$fname",
+			"cve":       1,
+			"purl":      1,
+			"version":   1,
+			"arch":      1,
+			"optlevel":  1,
+			"sim":       1,
+		}}},
+	)
+
+	if list, err = aggregate[models.BhaSearchHit](ctx, c.collection(), pipeline); err != nil {
+		return 0, nil, err
+	}
+
+	return total, list, nil
+}
+
+// searchByFName 匹配文件函数名称或检测文件函数名称为 fname 的结果
+// 检测文件函数在服务端按名称关联其匹配结果，不加载函数id，同时命中两者的结果只取匹配文件函数一侧
+func searchByFName(filter bson.M, fname string) mongo.Pipeline {
+	byRef, byFunc := bson.M{"fname": fname}, bson.M{"fname": bson.M{"$ne": fname}}
+	for k, v := range filter {
+		byRef[k], byFunc[k] = v, v
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: byRef}},
+		{{Key: "$unionWith", Value: bson.M{
+			"coll": bhaFuncsCollection,
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"fname": fname}},
+				bson.M{"$lookup": bson.M{
+					"from": bhaFuncResultsCollection,
+					"let":  bson.M{"func_id": bson.M{"$toString": "$_id"}},
+					"pipeline": bson.A{
+						bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$func_id", "$$func_id"}}}},
+						bson.M{"$match": byFunc},
+					},
+					"as": "result",
+				}},
+				bson.M{"$unwind": "$result"},
+				bson.M{"$replaceRoot": bson.M{"newRoot": "$result"}},
+			},
+		}}},
+	}
+}
+
+func nonEmpty(items []string) []string {
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,148 @@
+package mongo
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/pointer"
+)
+
+type BhaSuppression struct {
+	*base
+}
+
+func NewBhaSuppression(client *Client) *BhaSuppression {
+	return &BhaSuppression{
+		base: newBase(client, bhaSuppressionsCollection),
+	}
+}
+
+func (c *BhaSuppression) List(ctx context.Context, params dto.BhaSuppressionListReq) (total int64, list []models.BhaSuppression, err error) {
+	filter := bson.M{}
+	{
+		if params.Q != "" {
+			regex := bson.M{"$regex": regexp.QuoteMeta(params.Q), "$options": "i"}
+			filter["$or"] = bson.A{bson.M{"cve": regex}, bson.M{"fname": regex}, bson.M{"file_name": regex}}
+		}
+		if params.Project != "" {
+			filter["project"] = params.Project
+		}
+		if params.FileHash != "" {
+			filter["file_hash"] = params.FileHash
+		}
+	}
+
+	total, err = c.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	findOptions := &options.FindOptions{
+		Skip:  pointer.Of(params.Skip()),
+		Limit: pointer.Of(params.PageSize),
+		Sort:  bson.M{"created_at": models.Desc},
+	}
+
+	if list, err = find[models.BhaSuppression](ctx, c.collection(), filter, findOptions); err != nil {
+		return 0, nil, err
+	}
+
+	return total, list, nil
+}
+
+func (c *BhaSuppression) FindById(ctx context.Context, id string) (*models.BhaSuppression, error) {
+	return findById[models.BhaSuppression](ctx, c.collection(), id)
+}
+
+// FindAll 全部规则，按创建时间排序
+func (c *BhaSuppression) FindAll(ctx context.Context) ([]models.BhaSuppression, error) {
+	return find[models.BhaSuppression](ctx, c.collection(), bson.M{}, options.Find().SetSort(bson.M{"created_at": models.Asc}))
+}
+
+// FindForTask 适用于任务的规则，项目或文件hash为空的规则适用于全部任务
+func (c *BhaSuppression) FindForTask(ctx context.Context, project, fileHash string) ([]models.BhaSuppression, error) {
+	filter := bson.M{
+		"project":   bson.M{"$in": bson.A{"", project}},
+		"file_hash": bson.M{"$in": bson.A{"", fileHash}},
+	}
+	return find[models.BhaSuppression](ctx, c.collection(), filter)
+}
+
+// Create 新增规则，已存在相同条件的规则时返回 duplicate key 错误
+func (c *BhaSuppression) Create(ctx context.Context, m models.BhaSuppression) (string, error) {
+	m.CreatedAt = time.Now()
+	m.UpdatedAt = m.CreatedAt
+	return c.Insert(ctx, m)
+}
+
+// UpdateById 更新规则，返回是否存在
+func (c *BhaSuppression) UpdateById(ctx context.Context, id string, m models.BhaSuppression) (bool, error) {
+	oid, err := ObjectIDWithError(id)
+	if err != nil {
+		return false, nil
+	}
+
+	update := bson.M{"$set": c.fields(m)}
+	res, err := c.collection().UpdateByID(ctx, oid, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// Upsert 按匹配条件导入规则，已存在的规则更新研判信息
+func (c *BhaSuppression) Upsert(ctx context.Context, list []models.BhaSuppression) (inserted, updated int64, err error) {
+	if len(list) == 0 {
+		return 0, 0, nil
+	}
+
+	now := time.Now()
+	writeModels := make([]mongo.WriteModel, 0, len(list))
+	for _, m := range list {
+		writeModels = append(writeModels, mongo.NewUpdateOneModel().
+			SetFilter(c.key(m)).
+			SetUpdate(bson.M{
+				"$set":         c.fields(m),
+				"$setOnInsert": bson.M{"created_at": now},
+			}).
+			SetUpsert(true))
+	}
+
+	res, err := c.collection().BulkWrite(ctx, writeModels, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, 0, err
+	}
+	return res.UpsertedCount, res.MatchedCount, nil
+}
+
+// key 规则的匹配条件，与唯一索引一致
+func (c *BhaSuppression) key(m models.BhaSuppression) bson.M {
+	return bson.M{
+		"file_name": m.FileName,
+		"fname":     m.FName,
+		"addr":      m.Addr,
+		"cve":       m.CVE,
+		"min_sim":   m.MinSim,
+		"max_sim":   m.MaxSim,
+		"project":   m.Project,
+		"file_hash": m.FileHash,
+	}
+}
+
+// fields 除创建时间外的全部字段
+func (c *BhaSuppression) fields(m models.BhaSuppression) bson.M {
+	fields := c.key(m)
+	fields["status"] = m.Status
+	fields["justification"] = m.Justification
+	fields["comment"] = m.Comment
+	fields["author"] = m.Author
+	fields["updated_at"] = time.Now()
+	return fields
+}
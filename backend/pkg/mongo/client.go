@@ -16,11 +16,18 @@ import (
 const (
 	configsCollection = "configs"
 
-	tasksCollection = "tasks"
+	tasksCollection     = "tasks"
+	taskFilesCollection = "task_files"
 
-	bhaFuncsCollection       = "bha_funcs"
-	bhaFuncResultsCollection = "bha_func_results"
-	bhaModelsCollection      = "bha_models"
+	bhaFilesCollection        = "bha_files"
+	bhaFuncsCollection        = "bha_funcs"
+	bhaFuncResultsCollection  = "bha_func_results"
+	bhaModelsCollection       = "bha_models"
+	bhaSuppressionsCollection = "bha_suppressions"
+
+	scaComponentsCollection = "sca_components"
+
+	vulnerabilitiesCollection = "vulnerabilities"
 )
 
 type Client struct {
@@ -3,10 +3,12 @@ package mongo
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"bin-vul-inspector/pkg/models"
 	"bin-vul-inspector/pkg/utils"
@@ -74,9 +76,33 @@ func InitCollections(ctx context.Context, client *Client) (err error) {
 		}
 	}
 
+	// 删除已废弃的索引，已部署的数据库不会随 collectionsAndIndices 的变更自动删除
+	for name, indexNames := range droppedIndices() {
+		indexView := newBase(client, name).collection().Indexes()
+		for _, indexName := range indexNames {
+			if _, err = indexView.DropOne(ctx, indexName); err != nil && !isIndexNotFound(err) {
+				return fmt.Errorf("delete collection %s index %s error, %w", name, indexName, err)
+			}
+		}
+	}
+
 	return nil
 }
 
+// droppedIndices 已废弃的索引名称
+func droppedIndices() map[string][]string {
+	return map[string][]string{
+		// 与 {task_id, _id} 的前缀重复
+		bhaFuncsCollection: {"task_id_1"},
+	}
+}
+
+// isIndexNotFound 索引不存在，新部署或已删除
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && (cmdErr.Code == 27 || cmdErr.Name == "IndexNotFound")
+}
+
 func collectionsAndIndices() map[string][]mongo.IndexModel {
 	return map[string][]mongo.IndexModel{
 		tasksCollection: {
@@ -89,14 +115,44 @@ func collectionsAndIndices() map[string][]mongo.IndexModel {
 			{
 				Keys: bson.D{{Key: "name", Value: models.Asc}},
 			},
+			// 查找可复用扫描结果的任务
+			{
+				Keys: bson.D{
+					{Key: "file_hash", Value: models.Asc},
+					{Key: "detail.type", Value: models.Asc},
+				},
+			},
+		},
+		taskFilesCollection: {
+			{
+				Keys: bson.D{
+					{Key: "task_id", Value: models.Asc},
+					{Key: "path", Value: models.Asc},
+				},
+			},
+		},
+		bhaFilesCollection: {
+			{
+				Keys: bson.D{
+					{Key: "task_id", Value: models.Asc},
+					{Key: "file_id", Value: models.Asc},
+				},
+			},
 		},
 		bhaFuncsCollection: {
+			// 按任务查询，及导出时按id遍历任务的函数
 			{
-				Keys: bson.D{{Key: "task_id", Value: models.Asc}},
+				Keys: bson.D{
+					{Key: "task_id", Value: models.Asc},
+					{Key: "_id", Value: models.Asc},
+				},
 			},
 			{
 				Keys: bson.D{{Key: "file_id", Value: models.Asc}},
 			},
+			{
+				Keys: bson.D{{Key: "fname", Value: models.Asc}},
+			},
 		},
 		bhaFuncResultsCollection: {
 			{
@@ -112,9 +168,80 @@ func collectionsAndIndices() map[string][]mongo.IndexModel {
 					{Key: "sim", Value: models.Desc},
 				},
 			},
+			{
+				Keys: bson.D{
+					{Key: "task_id", Value: models.Asc},
+					{Key: "cve", Value: models.Asc},
+				},
+			},
+			{
+				Keys: bson.D{
+					{Key: "task_id", Value: models.Asc},
+					{Key: "triage.status", Value: models.Asc},
+				},
+			},
+			// 跨任务检索
+			{
+				Keys: bson.D{
+					{Key: "cve", Value: models.Asc},
+					{Key: "sim", Value: models.Desc},
+				},
+			},
+			{
+				Keys: bson.D{
+					{Key: "purl", Value: models.Asc},
+					{Key: "version", Value: models.Asc},
+					{Key: "sim", Value: models.Desc},
+				},
+			},
+			{
+				Keys: bson.D{
+					{Key: "fname", Value: models.Asc},
+					{Key: "sim", Value: models.Desc},
+				},
+			},
 		},
 		bhaModelsCollection: {},
-		configsCollection:   {},
+		bhaSuppressionsCollection: {
+			{
+				Keys: bson.D{
+					{Key: "cve", Value: models.Asc},
+					{Key: "fname", Value: models.Asc},
+					{Key: "addr", Value: models.Asc},
+					{Key: "file_name", Value: models.Asc},
+					{Key: "min_sim", Value: models.Asc},
+					{Key: "max_sim", Value: models.Asc},
+					{Key: "project", Value: models.Asc},
+					{Key: "file_hash", Value: models.Asc},
+				},
+				Options: options.Index().SetName("suppression_key").SetUnique(true),
+			},
+		},
+		scaComponentsCollection: {
+			{
+				Keys: bson.D{
+					{Key: "task_id", Value: models.Asc},
+					{Key: "cve_count", Value: models.Desc},
+				},
+			},
+		},
+		configsCollection: {},
+		vulnerabilitiesCollection: {
+			{
+				Keys:    bson.D{{Key: "cve", Value: models.Asc}},
+				Options: options.Index().SetName("cve_1").SetUnique(true),
+			},
+			{
+				Keys: bson.D{{Key: "kev", Value: models.Asc}},
+			},
+			// sca 组件关联漏洞
+			{
+				Keys: bson.D{
+					{Key: "affected.vendor", Value: models.Asc},
+					{Key: "affected.product", Value: models.Asc},
+				},
+			},
+		},
 	}
 }
 
@@ -0,0 +1,79 @@
+package mongo
+
+import (
+	"context"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/pointer"
+)
+
+type ScaComponent struct {
+	*base
+}
+
+func NewScaComponent(client *Client) *ScaComponent {
+	return &ScaComponent{
+		base: newBase(client, scaComponentsCollection),
+	}
+}
+
+func (c *ScaComponent) ListComponent(ctx context.Context, params dto.ScaComponentListReq) (total int64, list []models.ScaComponent, err error) {
+	var filter bson.M
+	{
+		filter = bson.M{"task_id": params.TaskId}
+		if params.Q != "" {
+			q := bson.M{"$regex": regexp.QuoteMeta(params.Q), "$options": "i"}
+			filter["$or"] = bson.A{bson.M{"name": q}, bson.M{"files.file_path": q}}
+		}
+		if params.Vulnerable {
+			filter["cve_count"] = bson.M{"$gt": 0}
+		}
+	}
+
+	total, err = c.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 漏洞多的组件优先
+	findOptions := &options.FindOptions{
+		Sort: bson.D{
+			{Key: "vulns.critical", Value: models.Desc},
+			{Key: "cve_count", Value: models.Desc},
+			{Key: "name", Value: models.Asc},
+			{Key: "version", Value: models.Asc},
+		},
+		Skip:  pointer.Of(params.Skip()),
+		Limit: pointer.Of(params.PageSize),
+	}
+
+	if list, err = find[models.ScaComponent](ctx, c.collection(), filter, findOptions); err != nil {
+		return 0, nil, err
+	}
+
+	return total, list, nil
+}
+
+func (c *ScaComponent) FindByTaskId(ctx context.Context, taskId string) ([]models.ScaComponent, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: models.Asc}, {Key: "version", Value: models.Asc}})
+	return find[models.ScaComponent](ctx, c.collection(), bson.M{"task_id": taskId}, findOptions)
+}
+
+func (c *ScaComponent) InsertMany(ctx context.Context, components []models.ScaComponent) error {
+	if len(components) == 0 {
+		return nil
+	}
+	_, err := insertMany(ctx, c.collection(), components)
+	return err
+}
+
+func (c *ScaComponent) DeleteByTaskIds(ctx context.Context, ids []string) (err error) {
+	filter := bson.M{"task_id": bson.M{"$in": ids}}
+	_, err = c.collection().DeleteMany(ctx, filter)
+	return err
+}
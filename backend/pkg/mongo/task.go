@@ -9,6 +9,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/bha"
 	"bin-vul-inspector/pkg/constant"
 	"bin-vul-inspector/pkg/models"
 )
@@ -100,6 +101,59 @@ func (c *Task) GetBhaTask(ctx context.Context, taskId string) (*models.Task, err
 	return c.findOne(ctx, bson.M{"task_id": taskId, "detail.type": constant.TypeBha})
 }
 
+// emptyFileHash 空内容的 SHA-256，早期版本计算上传文件hash时读取的是空内容，所有任务均记录为该值
+const emptyFileHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// FindBhaCached 查找可复用扫描结果的任务，即参数相同且已完成的最近一次扫描
+func (c *Task) FindBhaCached(ctx context.Context, task *models.Task) (*models.Task, error) {
+	filter := BhaCachedFilter(task)
+	if filter == nil {
+		return nil, nil
+	}
+	opts := options.FindOne().SetSort(bson.M{"modified_at": models.Desc})
+	return c.findOne(ctx, filter, opts)
+}
+
+// BhaCachedFilter 可复用扫描结果的任务查询条件，文件hash不可信时返回 nil
+//
+// 早期任务的 file_hash 均为 emptyFileHash 且未记录 SHA-1，修复hash计算后的任务才参与复用
+func BhaCachedFilter(task *models.Task) bson.M {
+	if task.FileHash == "" || task.FileHash == emptyFileHash || task.FileSHA1 == "" {
+		return nil
+	}
+
+	p := task.Detail.BhaParams
+	filter := bson.M{
+		"task_id":                 bson.M{"$ne": task.TaskId},
+		"detail.type":             constant.TypeBha,
+		"status":                  models.TaskStatusFinished,
+		"file_hash":               task.FileHash,
+		"file_sha1":               task.FileSHA1,
+		"file_size":               task.FileSize,
+		"detail.detection_method": p.DetectionMethod,
+		"detail.algorithm":        p.Algorithm,
+		"detail.model_id":         p.ModelId,
+		"detail.top_n":            p.TopN,
+		"detail.minimum_sim":      p.MinimumSim,
+		// 解包限制及支持的架构决定扫描单元，未记录的早期任务不参与复用
+		"detail.max_file_count": p.MaxFileCount,
+		"detail.max_file_size":  p.MaxFileSize,
+		"detail.max_depth":      p.MaxDepth,
+		"detail.arches":         p.Arches,
+	}
+	// 模型可能被同名重新上传，需校验内容hash
+	if p.ModelHash != "" {
+		filter["detail.model_hash"] = p.ModelHash
+	}
+	// 早期任务未记录输入类型，均为文件
+	if p.Input == "" || p.Input == bha.FileInput {
+		filter["detail.input"] = bson.M{"$in": bson.A{nil, bha.FileInput}}
+	} else {
+		filter["detail.input"] = p.Input
+	}
+	return filter
+}
+
 func (c *Task) GetTasksByTaskId(ctx context.Context, taskId string) ([]models.Task, error) {
 	return find[models.Task](ctx, c.collection(), bson.M{"task_id": taskId})
 }
@@ -139,8 +193,16 @@ func (c *Task) UpdateTaskByFilter(ctx context.Context, filter interface{}, task
 
 			// 扫描镜像时, 添加镜像地址
 			"file_hash": task.FileHash,
+			"file_sha1": task.FileSHA1,
+			"file_md5":  task.FileMD5,
 			"file_path": task.FilePath,
 			"file_size": task.FileSize,
+			"progress":  task.Progress,
+			"backend":   task.Backend,
+
+			"cached_from": task.CachedFrom,
+			"unpack":      task.Unpack,
+			"binary":      task.Binary,
 		},
 	}
 	updateResult, err := c.collection().UpdateOne(ctx, filter, update)
@@ -150,6 +212,30 @@ func (c *Task) UpdateTaskByFilter(ctx context.Context, filter interface{}, task
 	return updateResult.ModifiedCount, nil
 }
 
+func (c *Task) UpdateProgress(ctx context.Context, taskId, taskType string, progress models.TaskProgress) error {
+	filter := bson.M{"task_id": taskId, "detail.type": taskType}
+	update := bson.M{
+		"$set": bson.M{
+			"progress":    progress,
+			"modified_at": time.Now(),
+		},
+	}
+	_, err := c.collection().UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (c *Task) UpdateBackend(ctx context.Context, taskId, taskType, backend string) error {
+	filter := bson.M{"task_id": taskId, "detail.type": taskType}
+	update := bson.M{
+		"$set": bson.M{
+			"backend":     backend,
+			"modified_at": time.Now(),
+		},
+	}
+	_, err := c.collection().UpdateOne(ctx, filter, update)
+	return err
+}
+
 func (c *Task) InsertMany(ctx context.Context, documents []models.Task) (err error) {
 	_, err = insertMany(ctx, c.collection(), documents)
 	return err
@@ -176,6 +262,8 @@ func (c *Task) UpdateUploadFileByTaskId(ctx context.Context, taskId string, file
 		"$set": bson.M{
 			"file_path": file.FilePath,
 			"file_hash": file.FileHash,
+			"file_sha1": file.FileSHA1,
+			"file_md5":  file.FileMD5,
 			"file_size": file.FileSize,
 		},
 	}
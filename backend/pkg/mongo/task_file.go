@@ -0,0 +1,82 @@
+package mongo
+
+import (
+	"context"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bin-vul-inspector/pkg/api/v1/dto"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/pointer"
+)
+
+type TaskFile struct {
+	*base
+}
+
+func NewTaskFile(client *Client) *TaskFile {
+	return &TaskFile{
+		base: newBase(client, taskFilesCollection),
+	}
+}
+
+func (c *TaskFile) List(ctx context.Context, params dto.TaskFileListReq) (total int64, list []models.TaskFile, err error) {
+	var filter bson.M
+	{
+		filter = bson.M{"task_id": params.TaskId}
+		if params.Type != "" {
+			filter["type"] = params.Type
+		}
+		if params.Skipped != nil {
+			filter["skipped"] = bson.M{"$exists": *params.Skipped}
+		}
+		if params.Q != "" {
+			filter["path"] = bson.M{"$regex": regexp.QuoteMeta(params.Q), "$options": "i"}
+		}
+	}
+
+	total, err = c.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	findOptions := &options.FindOptions{
+		Sort:  bson.D{{Key: "path", Value: models.Asc}},
+		Skip:  pointer.Of(params.Skip()),
+		Limit: pointer.Of(params.PageSize),
+	}
+
+	if list, err = find[models.TaskFile](ctx, c.collection(), filter, findOptions); err != nil {
+		return 0, nil, err
+	}
+
+	return total, list, nil
+}
+
+func (c *TaskFile) FindByTaskId(ctx context.Context, taskId string) ([]models.TaskFile, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "path", Value: models.Asc}})
+	return find[models.TaskFile](ctx, c.collection(), bson.M{"task_id": taskId}, findOptions)
+}
+
+func (c *TaskFile) InsertMany(ctx context.Context, files []models.TaskFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	_, err := insertMany(ctx, c.collection(), files)
+	return err
+}
+
+// UpdateBackend 记录扫描单元的执行后端
+func (c *TaskFile) UpdateBackend(ctx context.Context, taskId, object, backend string) error {
+	filter := bson.M{"task_id": taskId, "object": object}
+	_, err := c.collection().UpdateOne(ctx, filter, bson.M{"$set": bson.M{"backend": backend}})
+	return err
+}
+
+func (c *TaskFile) DeleteByTaskIds(ctx context.Context, ids []string) (err error) {
+	filter := bson.M{"task_id": bson.M{"$in": ids}}
+	_, err = c.collection().DeleteMany(ctx, filter)
+	return err
+}
@@ -0,0 +1,160 @@
+package mongo_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"bin-vul-inspector/pkg/bha"
+	"bin-vul-inspector/pkg/constant"
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/mongo"
+)
+
+const emptyFileHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func newBhaTask(taskId string) *models.Task {
+	return &models.Task{
+		TaskId:   taskId,
+		Status:   models.TaskStatusFinished,
+		FileHash: "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592",
+		FileSHA1: "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12",
+		FileSize: 43,
+		Detail: models.TaskDetail{
+			Type: constant.TypeBha,
+			BhaParams: &models.BhaParams{
+				DetectionMethod: bha.IntelligentDetectMethod,
+				Algorithm:       bha.BSDAlgorithm,
+				ModelId:         "m1",
+				ModelHash:       "abc",
+				TopN:            100,
+				MinimumSim:      0.5,
+				Input:           bha.FileInput,
+				MaxFileCount:    100000,
+				MaxFileSize:     10 << 30,
+				MaxDepth:        4,
+				Arches:          []string{"arm", "arm64", "x86_64"},
+			},
+		},
+	}
+}
+
+func TestBhaCachedFilter(t *testing.T) {
+	task := newBhaTask("t2")
+	filter := mongo.BhaCachedFilter(task)
+	require.NotNil(t, filter)
+
+	t.Run("hit", func(t *testing.T) {
+		assert.True(t, matchFilter(t, filter, newBhaTask("t1")))
+
+		// 早期任务未记录输入类型
+		cached := newBhaTask("t1")
+		cached.Detail.BhaParams.Input = ""
+		assert.True(t, matchFilter(t, filter, cached))
+	})
+
+	t.Run("miss", func(t *testing.T) {
+		cases := map[string]func(c *models.Task){
+			"self":        func(c *models.Task) { c.TaskId = task.TaskId },
+			"unfinished":  func(c *models.Task) { c.Status = models.TaskStatusFailed },
+			"file size":   func(c *models.Task) { c.FileSize++ },
+			"file sha1":   func(c *models.Task) { c.FileSHA1 = strings.Repeat("0", 40) },
+			"top_n":       func(c *models.Task) { c.Detail.TopN = 10 },
+			"minimum_sim": func(c *models.Task) { c.Detail.MinimumSim = 0.8 },
+			"algorithm":   func(c *models.Task) { c.Detail.Algorithm = bha.SSFSAlgorithm },
+			"model":       func(c *models.Task) { c.Detail.ModelHash = "def" },
+			"input":       func(c *models.Task) { c.Detail.BhaParams.Input = bha.ContainerInput },
+			"max files":   func(c *models.Task) { c.Detail.MaxFileCount = 10 },
+			"max size":    func(c *models.Task) { c.Detail.MaxFileSize = 1 << 20 },
+			"max depth":   func(c *models.Task) { c.Detail.MaxDepth = 1 },
+			"arches":      func(c *models.Task) { c.Detail.Arches = []string{"arm", "arm64", "mips", "x86_64"} },
+			// 早期任务未记录解包限制及架构
+			"legacy": func(c *models.Task) {
+				c.Detail.MaxFileCount, c.Detail.MaxFileSize, c.Detail.MaxDepth, c.Detail.Arches = 0, 0, 0, nil
+			},
+		}
+		for name, modify := range cases {
+			cached := newBhaTask("t1")
+			modify(cached)
+			assert.False(t, matchFilter(t, filter, cached), name)
+		}
+	})
+
+	t.Run("legacy empty hash", func(t *testing.T) {
+		// 早期任务记录的hash均为空内容的hash，且未记录 SHA-1
+		legacy := newBhaTask("t1")
+		legacy.FileHash, legacy.FileSHA1 = emptyFileHash, ""
+		assert.Nil(t, mongo.BhaCachedFilter(legacy))
+		assert.False(t, matchFilter(t, filter, legacy))
+
+		// 重新排队的早期任务不复用其他任务的结果
+		requeued := newBhaTask("t3")
+		requeued.FileSHA1 = ""
+		assert.Nil(t, mongo.BhaCachedFilter(requeued))
+
+		requeued.FileHash, requeued.FileSHA1 = emptyFileHash, "da39a3ee5e6b4b0d3255bfef95601890afd80709"
+		assert.Nil(t, mongo.BhaCachedFilter(requeued))
+	})
+}
+
+// matchFilter 按 bson 编码后的值判断任务是否满足查询条件，仅支持 FindBhaCached 用到的操作符
+func matchFilter(t *testing.T, filter bson.M, task *models.Task) bool {
+	doc, cond := roundTrip(t, task), roundTrip(t, filter)
+	for key, want := range cond {
+		got, ok := lookup(doc, key)
+		op, isOp := want.(bson.M)
+		if !isOp {
+			if !ok || !reflect.DeepEqual(got, want) {
+				return false
+			}
+			continue
+		}
+		for name, value := range op {
+			switch name {
+			case "$ne":
+				if ok && reflect.DeepEqual(got, value) {
+					return false
+				}
+			case "$in":
+				var in bool
+				for _, v := range value.(bson.A) {
+					if (v == nil && !ok) || (ok && reflect.DeepEqual(got, v)) {
+						in = true
+					}
+				}
+				if !in {
+					return false
+				}
+			default:
+				t.Fatalf("unsupported operator %s", name)
+			}
+		}
+	}
+	return true
+}
+
+func roundTrip(t *testing.T, v any) bson.M {
+	data, err := bson.Marshal(v)
+	require.NoError(t, err)
+	var m bson.M
+	require.NoError(t, bson.Unmarshal(data, &m))
+	return m
+}
+
+func lookup(doc bson.M, key string) (any, bool) {
+	var v any = doc
+	for _, k := range strings.Split(key, ".") {
+		m, ok := v.(bson.M)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
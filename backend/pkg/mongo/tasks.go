@@ -236,6 +236,8 @@ func groupFields() bson.M {
 		"description":   bson.M{"$first": "$description"},
 		"file_path":     bson.M{"$first": "$file_path"},
 		"file_hash":     bson.M{"$first": "$file_hash"},
+		"file_sha1":     bson.M{"$first": "$file_sha1"},
+		"file_md5":      bson.M{"$first": "$file_md5"},
 		"file_size":     bson.M{"$max": "$file_size"},
 		"types":         bson.M{"$addToSet": "$detail.type"},
 		"status":        bson.M{"$addToSet": "$status"},
@@ -255,6 +257,8 @@ func projectFields() bson.M {
 		"description":   1,
 		"file_path":     1,
 		"file_hash":     1,
+		"file_sha1":     1,
+		"file_md5":      1,
 		"file_size":     1,
 		"types":         1,
 		"err_message":   1,
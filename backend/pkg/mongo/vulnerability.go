@@ -0,0 +1,114 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+type Vulnerability struct {
+	*base
+}
+
+func NewVulnerability(client *Client) *Vulnerability {
+	return &Vulnerability{
+		base: newBase(client, vulnerabilitiesCollection),
+	}
+}
+
+func (c *Vulnerability) FindByCVE(ctx context.Context, cve string) (*models.Vulnerability, error) {
+	return findOne[models.Vulnerability](ctx, c.collection(), bson.M{"cve": cve})
+}
+
+func (c *Vulnerability) FindByCVEs(ctx context.Context, cves []string) ([]models.Vulnerability, error) {
+	return find[models.Vulnerability](ctx, c.collection(), bson.M{"cve": bson.M{"$in": cves}})
+}
+
+// FindAffected 查找影响产品 vendor:product 的漏洞，版本范围由调用方判断
+func (c *Vulnerability) FindAffected(ctx context.Context, vendor, product string) ([]models.Vulnerability, error) {
+	filter := bson.M{"affected": bson.M{"$elemMatch": bson.M{"vendor": vendor, "product": product}}}
+	opts := options.Find().SetProjection(bson.M{"cve": 1, "severity": 1, "kev": 1, "affected": 1})
+	return find[models.Vulnerability](ctx, c.collection(), filter, opts)
+}
+
+// UpsertNVD 批量写入NVD数据，不覆盖KEV及EPSS字段
+func (c *Vulnerability) UpsertNVD(ctx context.Context, vulns []models.Vulnerability) error {
+	writeModels := make([]mongo.WriteModel, 0, len(vulns))
+	for _, v := range vulns {
+		update := bson.M{
+			"$set": bson.M{
+				"description":   v.Description,
+				"cwes":          v.CWEs,
+				"cvss":          v.CVSS,
+				"severity":      v.Severity,
+				"score":         v.Score,
+				"published":     v.Published,
+				"last_modified": v.LastModified,
+				"affected":      v.Affected,
+			},
+			"$setOnInsert": bson.M{"kev": false, "epss": 0, "epss_percentile": 0},
+		}
+		writeModels = append(writeModels, c.upsertModel(v.CVE, update))
+	}
+	return c.bulkWrite(ctx, writeModels)
+}
+
+// UpdateKEV 批量写入KEV目录，不在目录中的漏洞取消KEV标记
+func (c *Vulnerability) UpdateKEV(ctx context.Context, kevs []models.VulnKEV) error {
+	cves := make([]string, 0, len(kevs))
+	writeModels := make([]mongo.WriteModel, 0, len(kevs))
+	for _, v := range kevs {
+		cves = append(cves, v.CVE)
+		update := bson.M{
+			"$set": bson.M{"kev": true, "kev_date_added": v.DateAdded},
+			"$setOnInsert": bson.M{
+				"cwes": bson.A{}, "cvss": bson.A{}, "severity": models.SeverityUnknown,
+				"epss": 0, "epss_percentile": 0,
+			},
+		}
+		writeModels = append(writeModels, c.upsertModel(v.CVE, update))
+	}
+	if err := c.bulkWrite(ctx, writeModels); err != nil {
+		return err
+	}
+
+	filter := bson.M{"kev": true, "cve": bson.M{"$nin": cves}}
+	update := bson.M{"$set": bson.M{"kev": false}, "$unset": bson.M{"kev_date_added": ""}}
+	_, err := c.collection().UpdateMany(ctx, filter, update)
+	return err
+}
+
+// UpdateEPSS 批量写入EPSS评分
+func (c *Vulnerability) UpdateEPSS(ctx context.Context, scores []models.VulnEPSS) error {
+	writeModels := make([]mongo.WriteModel, 0, len(scores))
+	for _, v := range scores {
+		update := bson.M{
+			"$set": bson.M{"epss": v.EPSS, "epss_percentile": v.Percentile},
+			"$setOnInsert": bson.M{
+				"cwes": bson.A{}, "cvss": bson.A{}, "severity": models.SeverityUnknown,
+				"kev": false,
+			},
+		}
+		writeModels = append(writeModels, c.upsertModel(v.CVE, update))
+	}
+	return c.bulkWrite(ctx, writeModels)
+}
+
+func (c *Vulnerability) upsertModel(cve string, update bson.M) mongo.WriteModel {
+	return mongo.NewUpdateOneModel().
+		SetFilter(bson.M{"cve": cve}).
+		SetUpdate(update).
+		SetUpsert(true)
+}
+
+func (c *Vulnerability) bulkWrite(ctx context.Context, writeModels []mongo.WriteModel) error {
+	if len(writeModels) == 0 {
+		return nil
+	}
+	_, err := c.collection().BulkWrite(ctx, writeModels, options.BulkWrite().SetOrdered(false))
+	return err
+}
@@ -0,0 +1,468 @@
+package report
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/utils"
+)
+
+const (
+	CycloneDXSpecVersion = "1.5"
+
+	cycloneDXPropertyPrefix = ToolName + ":"
+)
+
+type CycloneDX struct {
+	BomFormat       string                   `json:"bomFormat"`
+	SpecVersion     string                   `json:"specVersion"`
+	SerialNumber    string                   `json:"serialNumber"`
+	Version         int                      `json:"version"`
+	Metadata        CycloneDXMetadata        `json:"metadata"`
+	Components      []CycloneDXComponent     `json:"components"`
+	Dependencies    []CycloneDXDependency    `json:"dependencies"`
+	Vulnerabilities []CycloneDXVulnerability `json:"vulnerabilities"`
+}
+
+type CycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     CycloneDXTools     `json:"tools"`
+	Component CycloneDXComponent `json:"component"`
+}
+
+type CycloneDXTools struct {
+	Components []CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXComponent struct {
+	Type       string               `json:"type"`
+	BomRef     string               `json:"bom-ref,omitempty"`
+	Name       string               `json:"name"`
+	Version    string               `json:"version,omitempty"`
+	Purl       string               `json:"purl,omitempty"`
+	Hashes     []CycloneDXHash      `json:"hashes,omitempty"`
+	Evidence   *CycloneDXEvidence   `json:"evidence,omitempty"`
+	Properties []CycloneDXProperty  `json:"properties,omitempty"`
+	Components []CycloneDXComponent `json:"components,omitempty"`
+}
+
+type CycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type CycloneDXEvidence struct {
+	Identity    *CycloneDXIdentity    `json:"identity,omitempty"`
+	Occurrences []CycloneDXOccurrence `json:"occurrences,omitempty"`
+}
+
+type CycloneDXIdentity struct {
+	Field      string            `json:"field"`
+	Confidence float64           `json:"confidence"`
+	Methods    []CycloneDXMethod `json:"methods"`
+}
+
+type CycloneDXMethod struct {
+	Technique  string  `json:"technique"`
+	Confidence float64 `json:"confidence"`
+	Value      string  `json:"value,omitempty"`
+}
+
+type CycloneDXOccurrence struct {
+	Location string `json:"location"`
+}
+
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type CycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+type CycloneDXVulnerability struct {
+	BomRef      string              `json:"bom-ref"`
+	Id          string              `json:"id"`
+	Source      CycloneDXSource     `json:"source"`
+	Ratings     []CycloneDXRating   `json:"ratings,omitempty"`
+	CWEs        []int               `json:"cwes,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Published   string              `json:"published,omitempty"`
+	Analysis    *CycloneDXAnalysis  `json:"analysis,omitempty"`
+	Affects     []CycloneDXAffect   `json:"affects"`
+	Properties  []CycloneDXProperty `json:"properties,omitempty"`
+}
+
+type CycloneDXSource struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+type CycloneDXRating struct {
+	Source   *CycloneDXSource `json:"source,omitempty"`
+	Score    float64          `json:"score"`
+	Severity string           `json:"severity"`
+	Method   string           `json:"method,omitempty"`
+	Vector   string           `json:"vector,omitempty"`
+}
+
+type CycloneDXAnalysis struct {
+	State         string   `json:"state"`
+	Justification string   `json:"justification,omitempty"`
+	Response      []string `json:"response,omitempty"`
+	Detail        string   `json:"detail,omitempty"`
+}
+
+type CycloneDXAffect struct {
+	Ref string `json:"ref"`
+}
+
+// CycloneDXBuilder 逐条添加匹配结果生成 CycloneDX 1.5 SBOM 及 VEX
+//
+// 每个检测文件为一个file组件，根据匹配结果的purl、version推断文件内嵌的第三方组件，
+// 每个CVE与受影响组件为一条漏洞，受影响的函数作为组件的证据
+type CycloneDXBuilder struct {
+	version string
+	task    *models.Task
+	vulns   Vulns
+
+	files     []*cdxFile
+	fileIndex map[string]*cdxFile
+	vulnIndex map[string]*cdxVuln // cve + component ref -> vuln
+	vulnOrder []*cdxVuln
+}
+
+type cdxFile struct {
+	component CycloneDXComponent
+	libs      []*cdxLib
+	libIndex  map[string]*cdxLib
+}
+
+type cdxLib struct {
+	component CycloneDXComponent
+	bestSim   float64
+}
+
+type cdxVuln struct {
+	cve       string
+	ref       string
+	functions []string
+
+	triaged        bool
+	statuses       map[string]int // 研判状态 -> 函数数
+	justifications map[string]int // 误报的研判依据 -> 函数数
+	comment        *models.BhaTriageComment
+}
+
+func NewCycloneDXBuilder(version string, task *models.Task, vulns Vulns) *CycloneDXBuilder {
+	return &CycloneDXBuilder{
+		version:   version,
+		task:      task,
+		vulns:     vulns,
+		fileIndex: make(map[string]*cdxFile),
+		vulnIndex: make(map[string]*cdxVuln),
+	}
+}
+
+// AddFile 添加检测文件，无匹配结果的文件同样列出
+func (b *CycloneDXBuilder) AddFile(f *models.BhaFile) {
+	b.file(f.FileId, f.FilePath, f.FileArch)
+}
+
+func (b *CycloneDXBuilder) Add(f *models.BhaFinding) {
+	file := b.file(f.FileId, f.FilePath, f.FileArch)
+
+	// 无purl时无法推断组件，漏洞关联至文件
+	ref := file.component.BomRef
+	if f.Purl != "" {
+		ref = b.lib(file, f).component.BomRef
+	}
+
+	key := f.CVE + "|" + ref
+	v, ok := b.vulnIndex[key]
+	if !ok {
+		v = &cdxVuln{cve: f.CVE, ref: ref}
+		b.vulnIndex[key] = v
+		b.vulnOrder = append(b.vulnOrder, v)
+	}
+	v.functions = append(v.functions, fmt.Sprintf("%s#0x%s %s %.4f", f.FilePath, strings.TrimPrefix(f.Addr, "0x"), displayName(f.FName), f.Sim))
+	v.addTriage(f.Triage)
+}
+
+func (v *cdxVuln) addTriage(t *models.BhaTriage) {
+	if v.statuses == nil {
+		v.statuses = make(map[string]int)
+		v.justifications = make(map[string]int)
+	}
+
+	status := t.TriageStatus()
+	v.statuses[status]++
+	if status != models.TriageUntriaged {
+		v.triaged = true
+	}
+	if t == nil {
+		return
+	}
+	if status == models.TriageFalsePositive && t.Justification != "" {
+		v.justifications[t.Justification]++
+	}
+	for i := range t.Comments {
+		if c := &t.Comments[i]; v.comment == nil || c.CreatedAt.After(v.comment.CreatedAt) {
+			v.comment = c
+		}
+	}
+}
+
+// analysis 根据受影响函数的研判状态生成VEX分析结论，均未研判时为nil
+//
+// 任一函数确认存在时为 exploitable，存在未研判的函数时为 in_triage，
+// 均为误报且研判依据一致时为 not_affected，否则为 false_positive
+func (v *cdxVuln) analysis() *CycloneDXAnalysis {
+	if !v.triaged {
+		return nil
+	}
+
+	analysis := &CycloneDXAnalysis{}
+	switch {
+	case v.statuses[models.TriageConfirmed] > 0:
+		analysis.State = "exploitable"
+	case v.statuses[models.TriageWontFix] > 0:
+		analysis.State = "exploitable"
+		analysis.Response = []string{"will_not_fix"}
+	case v.statuses[models.TriageUntriaged] > 0:
+		analysis.State = "in_triage"
+	default:
+		analysis.State = "false_positive"
+		for justification, n := range v.justifications {
+			if len(v.justifications) == 1 && n == v.statuses[models.TriageFalsePositive] {
+				analysis.State = "not_affected"
+				analysis.Justification = justification
+			}
+		}
+	}
+	if v.comment != nil {
+		analysis.Detail = v.comment.Content
+	}
+	return analysis
+}
+
+func (b *CycloneDXBuilder) file(fileId, filePath, fileArch string) *cdxFile {
+	if file, ok := b.fileIndex[fileId]; ok {
+		return file
+	}
+
+	file := &cdxFile{
+		component: CycloneDXComponent{
+			Type:   "file",
+			BomRef: "file:" + fileId,
+			Name:   filePath,
+		},
+		libIndex: make(map[string]*cdxLib),
+	}
+	if fileArch != "" {
+		file.component.Properties = []CycloneDXProperty{{Name: cycloneDXPropertyPrefix + "arch", Value: fileArch}}
+	}
+	b.files = append(b.files, file)
+	b.fileIndex[fileId] = file
+	return file
+}
+
+func (b *CycloneDXBuilder) lib(file *cdxFile, f *models.BhaFinding) *cdxLib {
+	purl := purlWithVersion(f.Purl, f.Version)
+	lib, ok := file.libIndex[purl]
+	if !ok {
+		name, version := parsePurl(purl)
+		lib = &cdxLib{component: CycloneDXComponent{
+			Type:     "library",
+			BomRef:   file.component.BomRef + ":" + purl,
+			Name:     name,
+			Version:  version,
+			Purl:     purl,
+			Evidence: &CycloneDXEvidence{},
+		}}
+		file.libs = append(file.libs, lib)
+		file.libIndex[purl] = lib
+	}
+
+	if f.Sim > lib.bestSim {
+		lib.bestSim = f.Sim
+	}
+	lib.component.Evidence.Occurrences = append(lib.component.Evidence.Occurrences, CycloneDXOccurrence{
+		Location: fmt.Sprintf("%s#0x%s", f.FilePath, strings.TrimPrefix(f.Addr, "0x")),
+	})
+	return lib
+}
+
+func (b *CycloneDXBuilder) Build() *CycloneDX {
+	bom := &CycloneDX{
+		BomFormat:       "CycloneDX",
+		SpecVersion:     CycloneDXSpecVersion,
+		SerialNumber:    "urn:uuid:" + utils.GenerateUUID(),
+		Version:         1,
+		Components:      make([]CycloneDXComponent, 0, len(b.files)),
+		Dependencies:    make([]CycloneDXDependency, 0, len(b.files)+1),
+		Vulnerabilities: make([]CycloneDXVulnerability, 0, len(b.vulnOrder)),
+	}
+
+	root := CycloneDXComponent{
+		Type:   "firmware",
+		BomRef: "task:" + b.task.TaskId,
+		Name:   b.task.Name,
+	}
+	if root.Name == "" {
+		root.Name = b.task.TaskId
+	}
+	for _, h := range []CycloneDXHash{
+		{Alg: "SHA-256", Content: b.task.FileHash},
+		{Alg: "SHA-1", Content: b.task.FileSHA1},
+		{Alg: "MD5", Content: b.task.FileMD5},
+	} {
+		if h.Content != "" {
+			root.Hashes = append(root.Hashes, h)
+		}
+	}
+	bom.Metadata = CycloneDXMetadata{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Tools: CycloneDXTools{Components: []CycloneDXComponent{
+			{Type: "application", Name: ToolName, Version: b.version},
+		}},
+		Component: root,
+	}
+
+	rootDependency := CycloneDXDependency{Ref: root.BomRef, DependsOn: make([]string, 0, len(b.files))}
+	for _, file := range b.files {
+		dependency := CycloneDXDependency{Ref: file.component.BomRef, DependsOn: make([]string, 0, len(file.libs))}
+
+		component := file.component
+		for _, lib := range file.libs {
+			c := lib.component
+			c.Evidence.Identity = &CycloneDXIdentity{
+				Field:      "purl",
+				Confidence: lib.bestSim,
+				Methods: []CycloneDXMethod{{
+					Technique:  "binary-analysis",
+					Confidence: lib.bestSim,
+					Value:      "function similarity",
+				}},
+			}
+			component.Components = append(component.Components, c)
+			dependency.DependsOn = append(dependency.DependsOn, c.BomRef)
+		}
+
+		bom.Components = append(bom.Components, component)
+		bom.Dependencies = append(bom.Dependencies, dependency)
+		rootDependency.DependsOn = append(rootDependency.DependsOn, component.BomRef)
+	}
+	bom.Dependencies = append([]CycloneDXDependency{rootDependency}, bom.Dependencies...)
+
+	for _, v := range b.vulnOrder {
+		bom.Vulnerabilities = append(bom.Vulnerabilities, b.vulnerability(v))
+	}
+	return bom
+}
+
+func (b *CycloneDXBuilder) vulnerability(v *cdxVuln) CycloneDXVulnerability {
+	nvd := CycloneDXSource{Name: "NVD", Url: nvdURL(v.cve)}
+	item := CycloneDXVulnerability{
+		BomRef:   "vuln:" + v.cve + ":" + v.ref,
+		Id:       v.cve,
+		Source:   nvd,
+		Affects:  []CycloneDXAffect{{Ref: v.ref}},
+		Analysis: v.analysis(),
+	}
+	for _, fn := range v.functions {
+		item.Properties = append(item.Properties, CycloneDXProperty{Name: cycloneDXPropertyPrefix + "function", Value: fn})
+	}
+
+	vuln := b.vulns[v.cve]
+	if vuln == nil {
+		return item
+	}
+
+	item.Description = vuln.Description
+	if !vuln.Published.IsZero() {
+		item.Published = vuln.Published.UTC().Format(time.RFC3339)
+	}
+	for _, cwe := range vuln.CWEs {
+		if id, err := strconv.Atoi(strings.TrimPrefix(cwe, "CWE-")); err == nil {
+			item.CWEs = append(item.CWEs, id)
+		}
+	}
+	for _, cvss := range vuln.CVSS {
+		item.Ratings = append(item.Ratings, CycloneDXRating{
+			Source:   &CycloneDXSource{Name: cvss.Source, Url: nvd.Url},
+			Score:    cvss.BaseScore,
+			Severity: cycloneDXSeverity(cvss.Severity),
+			Method:   cycloneDXMethod(cvss.Version),
+			Vector:   cvss.Vector,
+		})
+	}
+	if vuln.KEV {
+		item.Properties = append(item.Properties, CycloneDXProperty{Name: cycloneDXPropertyPrefix + "kev", Value: "true"})
+	}
+	if vuln.EPSS > 0 {
+		item.Properties = append(item.Properties, CycloneDXProperty{Name: cycloneDXPropertyPrefix + "epss", Value: strconv.FormatFloat(vuln.EPSS, 'f', -1, 64)})
+	}
+	return item
+}
+
+func cycloneDXMethod(version string) string {
+	switch version {
+	case "2.0":
+		return "CVSSv2"
+	case "3.0":
+		return "CVSSv3"
+	case "3.1":
+		return "CVSSv31"
+	case "4.0":
+		return "CVSSv4"
+	default:
+		return "other"
+	}
+}
+
+func cycloneDXSeverity(severity string) string {
+	switch severity {
+	case models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow, models.SeverityNone:
+		return severity
+	default:
+		return models.SeverityUnknown
+	}
+}
+
+// purlWithVersion purl中不包含版本时补充版本
+func purlWithVersion(purl, version string) string {
+	if version == "" || strings.Contains(purl, "@") {
+		return purl
+	}
+
+	// 版本位于qualifiers及subpath之前
+	rest := ""
+	if i := strings.IndexAny(purl, "?#"); i >= 0 {
+		purl, rest = purl[:i], purl[i:]
+	}
+	return purl + "@" + url.PathEscape(version) + rest
+}
+
+// parsePurl 解析purl中的名称及版本
+func parsePurl(purl string) (name, version string) {
+	p := purl
+	if i := strings.IndexAny(p, "?#"); i >= 0 {
+		p = p[:i]
+	}
+	if i := strings.LastIndex(p, "@"); i >= 0 {
+		version, _ = url.PathUnescape(p[i+1:])
+		p = p[:i]
+	}
+	name = p[strings.LastIndex(p, "/")+1:]
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name, version
+}
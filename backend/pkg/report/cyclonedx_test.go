@@ -0,0 +1,85 @@
+package report_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/report"
+)
+
+func TestCycloneDXBuilder(t *testing.T) {
+	vulns := report.Vulns{
+		"CVE-2014-0160": {
+			CVE:      "CVE-2014-0160",
+			Severity: models.SeverityHigh,
+			CWEs:     []string{"CWE-125"},
+			CVSS:     []models.CVSS{{Version: "3.1", Vector: "CVSS:3.1/AV:N", BaseScore: 7.5, Severity: models.SeverityHigh, Source: "nvd@nist.gov"}},
+		},
+	}
+	task := &models.Task{TaskId: "t1", Name: "router.bin", FileHash: "abc", FileMD5: "def"}
+
+	builder := report.NewCycloneDXBuilder("1.0.0", task, vulns)
+	builder.AddFile(&models.BhaFile{FileId: "f0", FilePath: "bin/busybox", FileArch: "arm"})
+	builder.Add(&models.BhaFinding{FileId: "f1", FilePath: "lib/libssl.so", Addr: "4a2f0", FName: "sub_4a2f0", CVE: "CVE-2014-0160", Purl: "pkg:generic/openssl", Version: "1.0.1f", Sim: 0.93})
+	builder.Add(&models.BhaFinding{FileId: "f1", FilePath: "lib/libssl.so", Addr: "4b000", FName: "sub_4b000", CVE: "CVE-2014-0160", Purl: "pkg:generic/openssl", Version: "1.0.1f", Sim: 0.81})
+	builder.Add(&models.BhaFinding{FileId: "f1", FilePath: "lib/libssl.so", Addr: "5000", FName: "foo", CVE: "CVE-2099-0001", Sim: 0.7})
+
+	bom := builder.Build()
+	assert.Equal(t, "1.5", bom.SpecVersion)
+	assert.Equal(t, "router.bin", bom.Metadata.Component.Name)
+	assert.Equal(t, []report.CycloneDXHash{{Alg: "SHA-256", Content: "abc"}, {Alg: "MD5", Content: "def"}}, bom.Metadata.Component.Hashes)
+
+	// 无匹配结果的文件同样列出
+	assert.Len(t, bom.Components, 2)
+	assert.Equal(t, "bin/busybox", bom.Components[0].Name)
+	assert.Empty(t, bom.Components[0].Components)
+
+	libs := bom.Components[1].Components
+	if assert.Len(t, libs, 1) {
+		assert.Equal(t, "openssl", libs[0].Name)
+		assert.Equal(t, "1.0.1f", libs[0].Version)
+		assert.Equal(t, "pkg:generic/openssl@1.0.1f", libs[0].Purl)
+		assert.Len(t, libs[0].Evidence.Occurrences, 2)
+		assert.Equal(t, 0.93, libs[0].Evidence.Identity.Confidence)
+	}
+
+	if assert.Len(t, bom.Vulnerabilities, 2) {
+		v := bom.Vulnerabilities[0]
+		assert.Equal(t, "CVE-2014-0160", v.Id)
+		assert.Equal(t, libs[0].BomRef, v.Affects[0].Ref)
+		assert.Equal(t, []int{125}, v.CWEs)
+		assert.Equal(t, "CVSSv31", v.Ratings[0].Method)
+		assert.Len(t, v.Properties, 2)
+
+		// 无purl时关联至文件
+		assert.Equal(t, bom.Components[1].BomRef, bom.Vulnerabilities[1].Affects[0].Ref)
+	}
+}
+
+func TestCycloneDXBuilder_Analysis(t *testing.T) {
+	fp := func(justification string) *models.BhaTriage {
+		return &models.BhaTriage{Status: models.TriageFalsePositive, Justification: justification}
+	}
+	cases := []struct {
+		name     string
+		triages  []*models.BhaTriage
+		expected *report.CycloneDXAnalysis
+	}{
+		{"untriaged", []*models.BhaTriage{nil, nil}, nil},
+		{"in triage", []*models.BhaTriage{nil, fp("")}, &report.CycloneDXAnalysis{State: "in_triage"}},
+		{"confirmed", []*models.BhaTriage{fp(""), {Status: models.TriageConfirmed}}, &report.CycloneDXAnalysis{State: "exploitable"}},
+		{"wont fix", []*models.BhaTriage{{Status: models.TriageWontFix}}, &report.CycloneDXAnalysis{State: "exploitable", Response: []string{"will_not_fix"}}},
+		{"not affected", []*models.BhaTriage{fp(models.JustificationCodeNotReachable), fp(models.JustificationCodeNotReachable)}, &report.CycloneDXAnalysis{State: "not_affected", Justification: models.JustificationCodeNotReachable}},
+		{"false positive", []*models.BhaTriage{fp(models.JustificationCodeNotReachable), fp("")}, &report.CycloneDXAnalysis{State: "false_positive"}},
+	}
+	for _, c := range cases {
+		builder := report.NewCycloneDXBuilder("1.0.0", &models.Task{TaskId: "t1"}, report.Vulns{})
+		for i, triage := range c.triages {
+			builder.Add(&models.BhaFinding{FileId: "f1", FilePath: "lib/libssl.so", Addr: fmt.Sprint(i), CVE: "CVE-2014-0160", Triage: triage})
+		}
+		assert.Equal(t, c.expected, builder.Build().Vulnerabilities[0].Analysis, c.name)
+	}
+}
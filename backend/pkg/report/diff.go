@@ -0,0 +1,207 @@
+package report
+
+import (
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+// DiffBuilder 对比两个任务的漏洞匹配结果
+//
+// 文件优先按路径匹配，其次按文件名匹配(两个任务中均唯一时)；
+// 函数优先按函数名匹配，无符号名的函数(sub_xxx等)按地址匹配
+type DiffBuilder struct {
+	threshold float64
+	files     map[string]string // head file id -> base file id
+
+	base map[diffKey]*models.BhaFinding
+	head map[diffKey]*models.BhaFinding
+}
+
+// diffKey 以基准任务的文件id标识匹配到的文件，未匹配的对比任务文件以 head: 前缀区分
+type diffKey struct {
+	file string
+	fn   string
+	cve  string
+}
+
+// NewDiffBuilder 根据两个任务的文件列表建立文件对应关系
+func NewDiffBuilder(baseFiles, headFiles []models.BhaFile, threshold float64) *DiffBuilder {
+	return &DiffBuilder{
+		threshold: threshold,
+		files:     matchFiles(baseFiles, headFiles),
+		base:      make(map[diffKey]*models.BhaFinding),
+		head:      make(map[diffKey]*models.BhaFinding),
+	}
+}
+
+// AddBase 添加基准任务的匹配结果
+func (b *DiffBuilder) AddBase(f *models.BhaFinding) {
+	addFinding(b.base, diffKey{file: f.FileId, fn: funcKey(f), cve: f.CVE}, f)
+}
+
+// AddHead 添加对比任务的匹配结果
+func (b *DiffBuilder) AddHead(f *models.BhaFinding) {
+	file, ok := b.files[f.FileId]
+	if !ok {
+		file = "head:" + f.FileId
+	}
+	addFinding(b.head, diffKey{file: file, fn: funcKey(f), cve: f.CVE}, f)
+}
+
+// 同名函数取相似分数最高的匹配
+func addFinding(m map[diffKey]*models.BhaFinding, key diffKey, f *models.BhaFinding) {
+	if old, ok := m[key]; ok && old.Sim >= f.Sim {
+		return
+	}
+	v := *f
+	m[key] = &v
+}
+
+// Build 生成对比结果，unchanged 为 false 时不包含未变化的匹配
+func (b *DiffBuilder) Build(unchanged bool) ([]models.BhaDiffItem, models.BhaDiffSummary) {
+	items := make([]models.BhaDiffItem, 0)
+	summary := models.BhaDiffSummary{
+		FixedCVEs:      make([]string, 0),
+		IntroducedCVEs: make([]string, 0),
+	}
+	baseCVEs := make(map[string]struct{})
+	headCVEs := make(map[string]struct{})
+
+	for key, base := range b.base {
+		baseCVEs[key.cve] = struct{}{}
+
+		item := models.BhaDiffItem{CVE: key.cve, Base: base, Head: b.head[key]}
+		switch {
+		case item.Head == nil:
+			item.Status = models.BhaDiffFixed
+			item.Delta = -base.Sim
+			summary.Fixed++
+		case math.Abs(item.Head.Sim-base.Sim) > b.threshold:
+			item.Status = models.BhaDiffChanged
+			item.Delta = item.Head.Sim - base.Sim
+			summary.Changed++
+		default:
+			item.Status = models.BhaDiffUnchanged
+			item.Delta = item.Head.Sim - base.Sim
+			summary.Unchanged++
+			if !unchanged {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	for key, head := range b.head {
+		headCVEs[key.cve] = struct{}{}
+		if _, ok := b.base[key]; ok {
+			continue
+		}
+		items = append(items, models.BhaDiffItem{
+			Status: models.BhaDiffIntroduced,
+			CVE:    key.cve,
+			Delta:  head.Sim,
+			Head:   head,
+		})
+		summary.Introduced++
+	}
+
+	for cve := range baseCVEs {
+		if _, ok := headCVEs[cve]; !ok {
+			summary.FixedCVEs = append(summary.FixedCVEs, cve)
+		}
+	}
+	for cve := range headCVEs {
+		if _, ok := baseCVEs[cve]; !ok {
+			summary.IntroducedCVEs = append(summary.IntroducedCVEs, cve)
+		}
+	}
+	sort.Strings(summary.FixedCVEs)
+	sort.Strings(summary.IntroducedCVEs)
+
+	sortDiffItems(items)
+	return items, summary
+}
+
+// sortDiffItems 按状态、CVE、文件路径、函数地址排序
+func sortDiffItems(items []models.BhaDiffItem) {
+	rank := make(map[string]int)
+	for i, status := range models.BhaDiffStatuses() {
+		rank[status] = i
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		a, b := &items[i], &items[j]
+		if a.Status != b.Status {
+			return rank[a.Status] < rank[b.Status]
+		}
+		if a.CVE != b.CVE {
+			return a.CVE < b.CVE
+		}
+		fa, fb := a.Finding(), b.Finding()
+		if fa.FilePath != fb.FilePath {
+			return fa.FilePath < fb.FilePath
+		}
+		return fa.Addr < fb.Addr
+	})
+}
+
+// matchFiles 建立对比任务文件到基准任务文件的对应关系
+func matchFiles(baseFiles, headFiles []models.BhaFile) map[string]string {
+	basePaths := make(map[string]string)
+	baseNames := make(map[string][]string)
+	for _, f := range baseFiles {
+		basePaths[f.FilePath] = f.FileId
+		name := path.Base(f.FilePath)
+		baseNames[name] = append(baseNames[name], f.FileId)
+	}
+	headNames := make(map[string]int)
+	for _, f := range headFiles {
+		headNames[path.Base(f.FilePath)]++
+	}
+
+	files := make(map[string]string)
+	matched := make(map[string]struct{})
+	for _, f := range headFiles {
+		if id, ok := basePaths[f.FilePath]; ok {
+			files[f.FileId] = id
+			matched[id] = struct{}{}
+		}
+	}
+	// 路径不同时(如固件解包目录带版本号)按唯一的文件名匹配
+	for _, f := range headFiles {
+		if _, ok := files[f.FileId]; ok {
+			continue
+		}
+		name := path.Base(f.FilePath)
+		ids := baseNames[name]
+		if len(ids) != 1 || headNames[name] != 1 {
+			continue
+		}
+		if _, ok := matched[ids[0]]; ok {
+			continue
+		}
+		files[f.FileId] = ids[0]
+		matched[ids[0]] = struct{}{}
+	}
+	return files
+}
+
+// funcKey 函数标识，反编译器生成的函数名随地址变化，按地址匹配
+func funcKey(f *models.BhaFinding) string {
+	if f.FName == "" || isGeneratedFuncName(f.FName) {
+		return "@" + strings.ToLower(strings.TrimPrefix(f.Addr, "0x"))
+	}
+	return f.FName
+}
+
+func isGeneratedFuncName(fname string) bool {
+	for _, prefix := range []string{"sub_", "fcn.", "FUN_", "func_"} {
+		if strings.HasPrefix(fname, prefix) {
+			return true
+		}
+	}
+	return false
+}
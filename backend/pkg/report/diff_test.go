@@ -0,0 +1,62 @@
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/report"
+)
+
+func TestDiffBuilder(t *testing.T) {
+	baseFiles := []models.BhaFile{
+		{FileId: "b1", FilePath: "fw-1.0/usr/lib/libssl.so"},
+		{FileId: "b2", FilePath: "fw-1.0/usr/bin/httpd"},
+	}
+	headFiles := []models.BhaFile{
+		{FileId: "h1", FilePath: "fw-1.1/usr/lib/libssl.so"},
+		{FileId: "h2", FilePath: "fw-1.1/usr/bin/httpd"},
+		{FileId: "h3", FilePath: "fw-1.1/usr/bin/telnetd"},
+	}
+	builder := report.NewDiffBuilder(baseFiles, headFiles, 0.05)
+
+	// 按函数名匹配，地址变化不影响
+	builder.AddBase(&models.BhaFinding{FileId: "b1", FilePath: "fw-1.0/usr/lib/libssl.so", Addr: "1000", FName: "tls1_process_heartbeat", CVE: "CVE-2014-0160", Sim: 0.95})
+	builder.AddHead(&models.BhaFinding{FileId: "h1", FilePath: "fw-1.1/usr/lib/libssl.so", Addr: "1200", FName: "tls1_process_heartbeat", CVE: "CVE-2014-0160", Sim: 0.6})
+	// 无符号名按地址匹配
+	builder.AddBase(&models.BhaFinding{FileId: "b2", FilePath: "fw-1.0/usr/bin/httpd", Addr: "0x2000", FName: "sub_2000", CVE: "CVE-2020-0001", Sim: 0.8})
+	builder.AddHead(&models.BhaFinding{FileId: "h2", FilePath: "fw-1.1/usr/bin/httpd", Addr: "2000", FName: "sub_2000", CVE: "CVE-2020-0001", Sim: 0.82})
+	builder.AddBase(&models.BhaFinding{FileId: "b2", FilePath: "fw-1.0/usr/bin/httpd", Addr: "3000", FName: "sub_3000", CVE: "CVE-2020-0002", Sim: 0.9})
+	builder.AddHead(&models.BhaFinding{FileId: "h2", FilePath: "fw-1.1/usr/bin/httpd", Addr: "3100", FName: "sub_3100", CVE: "CVE-2020-0002", Sim: 0.9})
+	// 新增文件
+	builder.AddHead(&models.BhaFinding{FileId: "h3", FilePath: "fw-1.1/usr/bin/telnetd", Addr: "4000", FName: "main", CVE: "CVE-2021-0001", Sim: 0.7})
+
+	items, summary := builder.Build(false)
+	assert.Equal(t, int64(1), summary.Fixed)
+	assert.Equal(t, int64(2), summary.Introduced)
+	assert.Equal(t, int64(1), summary.Changed)
+	assert.Equal(t, int64(1), summary.Unchanged)
+	assert.Equal(t, []string{}, summary.FixedCVEs)
+	assert.Equal(t, []string{"CVE-2021-0001"}, summary.IntroducedCVEs)
+
+	if assert.Len(t, items, 4) {
+		assert.Equal(t, models.BhaDiffFixed, items[0].Status)
+		assert.Equal(t, "3000", items[0].Finding().Addr)
+		assert.Equal(t, models.BhaDiffIntroduced, items[1].Status)
+		assert.Equal(t, "CVE-2020-0002", items[1].CVE)
+		assert.Equal(t, models.BhaDiffIntroduced, items[2].Status)
+		assert.Equal(t, "CVE-2021-0001", items[2].CVE)
+		assert.Equal(t, models.BhaDiffChanged, items[3].Status)
+		assert.InDelta(t, -0.35, items[3].Delta, 1e-9)
+	}
+
+	items, _ = builder.Build(true)
+	assert.Len(t, items, 5)
+
+	var buf bytes.Buffer
+	diff := &models.BhaDiff{Threshold: 0.05, Items: items, Summary: summary}
+	assert.NoError(t, report.RenderDiffHTML(&buf, "1.0.0", diff))
+	assert.Contains(t, buf.String(), "-35.0%")
+}
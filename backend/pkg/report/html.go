@@ -0,0 +1,291 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+// HTMLTopMatches 函数明细中展示的匹配结果数
+const HTMLTopMatches = 5
+
+//go:embed templates/*.html
+var templates embed.FS
+
+var htmlTmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"percent": func(sim float64) string {
+		return fmt.Sprintf("%.1f", sim*100)
+	},
+	"datetime": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format(time.DateTime)
+	},
+	"filesize": filesize,
+	"nvd":      nvdURL,
+	"signed": func(delta float64) string {
+		return fmt.Sprintf("%+.1f", delta*100)
+	},
+}).ParseFS(templates, "templates/*.html"))
+
+// HTMLMeta HTML报告中的任务信息
+type HTMLMeta struct {
+	TaskId          string    // 任务id
+	Name            string    // 任务名称
+	FileHash        string    // 文件hash
+	FileSize        int64     // 文件大小
+	DetectionMethod string    // 检测方式
+	Algorithm       string    // 检测算法
+	Model           string    // 模型名称
+	TopN            uint      // 每个函数保留的候选结果数
+	MinimumSim      float64   // 最小相似度
+	CreatedAt       time.Time // 任务创建时间
+}
+
+// HTMLBuilder 生成单文件HTML报告，样式内联，可离线查看
+//
+// 任务中的文件通过 AddFile 添加，存在匹配结果的函数通过 AddFunc 添加
+type HTMLBuilder struct {
+	version string
+	meta    HTMLMeta
+	vulns   Vulns
+
+	files []models.BhaFile
+	funcs []htmlFunc
+	cves  map[string]*htmlCVE
+}
+
+type htmlCVE struct {
+	CVE       string
+	Severity  string
+	Score     float64
+	KEV       bool
+	EPSS      float64
+	BestSim   float64
+	FuncCount int
+	Purls     []string
+
+	purls map[string]struct{}
+	files map[string]struct{}
+}
+
+type htmlFunc struct {
+	FilePath string
+	Addr     string
+	FName    string
+	Matches  []htmlMatch
+	More     int // 未展示的匹配结果数
+}
+
+type htmlMatch struct {
+	FName    string
+	Purl     string
+	CVE      string
+	Severity string
+	Arch     string
+	OptLevel string
+	Sim      float64
+}
+
+type htmlReport struct {
+	Tool         string
+	Version      string
+	GeneratedAt  time.Time
+	Meta         HTMLMeta
+	Summary      models.VulnSummary
+	FuncCount    int64
+	MatchedCount int64
+	CVEs         []*htmlCVE
+	Files        []models.BhaFile
+	Funcs        []htmlFunc
+	TopMatches   int
+}
+
+func NewHTMLBuilder(version string, meta HTMLMeta, vulns Vulns) *HTMLBuilder {
+	return &HTMLBuilder{
+		version: version,
+		meta:    meta,
+		vulns:   vulns,
+		cves:    make(map[string]*htmlCVE),
+	}
+}
+
+// AddFile 添加文件统计
+func (b *HTMLBuilder) AddFile(file *models.BhaFile) {
+	b.files = append(b.files, *file)
+}
+
+// AddFunc 添加函数及其全部匹配结果
+func (b *HTMLBuilder) AddFunc(fn *models.BhaFunc, results []models.BhaFuncResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	sorted := make([]models.BhaFuncResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Sim > sorted[j].Sim
+	})
+
+	row := htmlFunc{
+		FilePath: fn.FilePath,
+		Addr:     fn.Addr,
+		FName:    fn.FName,
+	}
+	counted := make(map[string]struct{})
+	for i := range sorted {
+		r := &sorted[i]
+		if i < HTMLTopMatches {
+			row.Matches = append(row.Matches, htmlMatch{
+				FName:    r.FName,
+				Purl:     matchPurl(r),
+				CVE:      r.CVE,
+				Severity: b.vulns.severity(r.CVE),
+				Arch:     r.Arch,
+				OptLevel: r.OptLevel,
+				Sim:      r.Sim,
+			})
+		}
+		if r.CVE == "" {
+			continue
+		}
+
+		c := b.cve(r.CVE)
+		if r.Sim > c.BestSim {
+			c.BestSim = r.Sim
+		}
+		if _, ok := counted[r.CVE]; !ok {
+			counted[r.CVE] = struct{}{}
+			c.FuncCount++
+		}
+		c.files[fn.FileId] = struct{}{}
+		if p := matchPurl(r); p != "" {
+			c.purls[p] = struct{}{}
+		}
+	}
+	row.More = len(sorted) - len(row.Matches)
+	b.funcs = append(b.funcs, row)
+}
+
+func (b *HTMLBuilder) cve(id string) *htmlCVE {
+	c, ok := b.cves[id]
+	if !ok {
+		c = &htmlCVE{
+			CVE:      id,
+			Severity: b.vulns.severity(id),
+			purls:    make(map[string]struct{}),
+			files:    make(map[string]struct{}),
+		}
+		if vuln := b.vulns[id]; vuln != nil {
+			c.Score = vuln.Score
+			c.KEV = vuln.KEV
+			c.EPSS = vuln.EPSS
+		}
+		b.cves[id] = c
+	}
+	return c
+}
+
+// Render 输出HTML报告
+func (b *HTMLBuilder) Render(w io.Writer) error {
+	data := htmlReport{
+		Tool:        ToolName,
+		Version:     b.version,
+		GeneratedAt: time.Now(),
+		Meta:        b.meta,
+		Files:       b.files,
+		Funcs:       b.funcs,
+		TopMatches:  HTMLTopMatches,
+	}
+
+	for _, f := range b.files {
+		data.FuncCount += f.FuncCount
+		data.MatchedCount += f.MatchedFuncCount
+	}
+
+	for _, c := range b.cves {
+		data.Summary.Add(b.vulns[c.CVE])
+		for p := range c.purls {
+			c.Purls = append(c.Purls, p)
+		}
+		sort.Strings(c.Purls)
+		data.CVEs = append(data.CVEs, c)
+	}
+	// 按严重等级、相似分数排序
+	sort.Slice(data.CVEs, func(i, j int) bool {
+		a, b := data.CVEs[i], data.CVEs[j]
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra > rb
+		}
+		if a.BestSim != b.BestSim {
+			return a.BestSim > b.BestSim
+		}
+		return a.CVE < b.CVE
+	})
+
+	return htmlTmpl.ExecuteTemplate(w, "report.html", data)
+}
+
+// FileCount 匹配到该CVE的文件数
+func (c *htmlCVE) FileCount() int {
+	return len(c.files)
+}
+
+// matchPurl 匹配结果的purl，包含版本
+func matchPurl(r *models.BhaFuncResult) string {
+	if r.Purl == "" {
+		return ""
+	}
+	return purlWithVersion(r.Purl, r.Version)
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case models.SeverityCritical:
+		return 4
+	case models.SeverityHigh:
+		return 3
+	case models.SeverityMedium:
+		return 2
+	case models.SeverityLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func filesize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+type htmlDiff struct {
+	Tool        string
+	Version     string
+	GeneratedAt time.Time
+	Diff        *models.BhaDiff
+}
+
+// RenderDiffHTML 输出任务对比的HTML报告
+func RenderDiffHTML(w io.Writer, version string, diff *models.BhaDiff) error {
+	return htmlTmpl.ExecuteTemplate(w, "diff.html", htmlDiff{
+		Tool:        ToolName,
+		Version:     version,
+		GeneratedAt: time.Now(),
+		Diff:        diff,
+	})
+}
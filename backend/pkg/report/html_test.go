@@ -0,0 +1,42 @@
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/report"
+)
+
+func TestHTMLBuilder(t *testing.T) {
+	vulns := report.Vulns{
+		"CVE-2014-0160": {CVE: "CVE-2014-0160", Severity: models.SeverityHigh, Score: 7.5, KEV: true},
+	}
+	builder := report.NewHTMLBuilder("1.0.0", report.HTMLMeta{Name: "<firmware>", FileHash: "abc", FileSize: 2048, Algorithm: "sfs"}, vulns)
+	builder.AddFile(&models.BhaFile{FileId: "f1", FilePath: "usr/lib/libssl.so", FuncCount: 10, MatchedFuncCount: 1, BestSim: 0.93, CVECount: 2})
+
+	results := []models.BhaFuncResult{
+		{FName: "dtls1_process_heartbeat", CVE: "CVE-2099-0001", Sim: 0.5},
+		{FName: "tls1_process_heartbeat", CVE: "CVE-2014-0160", Purl: "pkg:generic/openssl", Version: "1.0.1f", Sim: 0.93},
+	}
+	for i := 0; i < report.HTMLTopMatches; i++ {
+		results = append(results, models.BhaFuncResult{FName: "other", Sim: 0.1})
+	}
+	builder.AddFunc(&models.BhaFunc{FileId: "f1", FilePath: "usr/lib/libssl.so", Addr: "4a2f0", FName: "sub_4a2f0"}, results)
+	builder.AddFunc(&models.BhaFunc{FileId: "f1", FilePath: "usr/lib/libssl.so", Addr: "5000", FName: "unmatched"}, nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, builder.Render(&buf))
+	html := buf.String()
+
+	assert.Contains(t, html, "&lt;firmware&gt;")
+	assert.Contains(t, html, "2.0 KiB")
+	assert.Contains(t, html, "pkg:generic/openssl@1.0.1f")
+	assert.Contains(t, html, `style="width: 93.0%"`)
+	assert.Contains(t, html, "and 2 more")
+	assert.NotContains(t, html, "unmatched")
+	// 严重等级高的CVE排在前面
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("CVE-2014-0160")), bytes.Index(buf.Bytes(), []byte("CVE-2099-0001")))
+}
@@ -0,0 +1,25 @@
+// Package report 将bha检测结果转换为标准报告格式
+package report
+
+import (
+	"bin-vul-inspector/pkg/models"
+)
+
+const (
+	ToolName = "bin-vul-inspector"
+)
+
+// Vulns 漏洞信息 cve -> vulnerability
+type Vulns map[string]*models.Vulnerability
+
+func (v Vulns) severity(cve string) string {
+	if vuln := v[cve]; vuln != nil && vuln.Severity != "" {
+		return vuln.Severity
+	}
+	return models.SeverityUnknown
+}
+
+// nvdURL NVD漏洞详情地址
+func nvdURL(cve string) string {
+	return "https://nvd.nist.gov/vuln/detail/" + cve
+}
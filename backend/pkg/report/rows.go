@@ -0,0 +1,86 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+// RowWriter 逐行写出函数匹配结果
+type RowWriter interface {
+	Write(row *models.BhaFuncRow) error
+	Flush() error
+}
+
+// csvHeader CSV列名，与 models.BhaFuncRow 的json字段一致
+var csvHeader = []string{
+	"file_id", "file_path", "file_arch", "func_id", "addr", "fname",
+	"rank", "ref_fname", "purl", "version", "cve", "arch", "optlevel", "sim", "refs",
+}
+
+type csvRowWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVRowWriter CSV格式，首行为列名，refs以空格分隔
+func NewCSVRowWriter(w io.Writer) RowWriter {
+	return &csvRowWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvRowWriter) Write(row *models.BhaFuncRow) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	var rank, sim string
+	// 无匹配结果的函数
+	if row.Rank > 0 {
+		rank = strconv.Itoa(row.Rank)
+		sim = strconv.FormatFloat(row.Sim, 'f', -1, 64)
+	}
+	return c.w.Write([]string{
+		row.FileId, row.FilePath, row.FileArch, row.FuncId, row.Addr, row.FName,
+		rank, row.RefFName, row.Purl, row.Version, row.CVE, row.Arch, row.OptLevel, sim,
+		strings.Join(row.Refs, " "),
+	})
+}
+
+func (c *csvRowWriter) Flush() error {
+	// 无数据时也输出列名
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonRowWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONRowWriter NDJSON格式，每行一个json对象
+func NewNDJSONRowWriter(w io.Writer) RowWriter {
+	return &ndjsonRowWriter{enc: json.NewEncoder(w)}
+}
+
+func (n *ndjsonRowWriter) Write(row *models.BhaFuncRow) error {
+	if row.Refs == nil {
+		row.Refs = make([]string, 0)
+	}
+	return n.enc.Encode(row)
+}
+
+func (n *ndjsonRowWriter) Flush() error {
+	return nil
+}
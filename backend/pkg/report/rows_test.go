@@ -0,0 +1,44 @@
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/report"
+)
+
+func TestRowWriter(t *testing.T) {
+	rows := []models.BhaFuncRow{
+		{FileId: "f1", FilePath: "bin/a,b", FuncId: "64f", Addr: "1000", FName: "main", Rank: 1, RefFName: "main", CVE: "CVE-2014-0160", Sim: 0.9, Refs: []string{"r1", "r2"}},
+		{FileId: "f1", FilePath: "bin/a,b", FuncId: "650", Addr: "2000", FName: "foo"},
+	}
+
+	var buf bytes.Buffer
+	w := report.NewCSVRowWriter(&buf)
+	for i := range rows {
+		assert.NoError(t, w.Write(&rows[i]))
+	}
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "file_id,file_path,file_arch,func_id,addr,fname,rank,ref_fname,purl,version,cve,arch,optlevel,sim,refs\n"+
+		"f1,\"bin/a,b\",,64f,1000,main,1,main,,,CVE-2014-0160,,,0.9,r1 r2\n"+
+		"f1,\"bin/a,b\",,650,2000,foo,,,,,,,,,\n", buf.String())
+
+	// 无数据时仅输出列名
+	buf.Reset()
+	assert.NoError(t, report.NewCSVRowWriter(&buf).Flush())
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+
+	buf.Reset()
+	w = report.NewNDJSONRowWriter(&buf)
+	for i := range rows {
+		assert.NoError(t, w.Write(&rows[i]))
+	}
+	assert.NoError(t, w.Flush())
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.Contains(t, string(lines[1]), `"rank":0`)
+	assert.Contains(t, string(lines[1]), `"refs":[]`)
+}
@@ -0,0 +1,269 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type SARIF struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []SARIFRule `json:"rules"`
+}
+
+type SARIFRule struct {
+	Id               string         `json:"id"`
+	Name             string         `json:"name"`
+	ShortDescription SARIFMessage   `json:"shortDescription"`
+	FullDescription  *SARIFMessage  `json:"fullDescription,omitempty"`
+	HelpUri          string         `json:"helpUri"`
+	Properties       map[string]any `json:"properties,omitempty"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFResult struct {
+	RuleId              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             SARIFMessage       `json:"message"`
+	Locations           []SARIFLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints"`
+	BaselineState       string             `json:"baselineState,omitempty"`
+	Suppressions        []SARIFSuppression `json:"suppressions,omitempty"`
+	Properties          map[string]any     `json:"properties"`
+}
+
+// SARIFSuppression 结果被抑制规则命中
+type SARIFSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status,omitempty"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []SARIFLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Address          *SARIFAddress         `json:"address,omitempty"`
+}
+
+type SARIFArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type SARIFAddress struct {
+	AbsoluteAddress uint64 `json:"absoluteAddress"`
+	Name            string `json:"name,omitempty"`
+	Kind            string `json:"kind"`
+}
+
+type SARIFLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// SARIFBuilder 逐条添加匹配结果生成 SARIF 2.1.0 报告
+// 每个CVE为一条规则，每个检测文件函数与CVE的匹配为一条结果
+type SARIFBuilder struct {
+	vulns Vulns
+	rules map[string]int // cve -> rule index
+	run   SARIFRun
+}
+
+func NewSARIFBuilder(version string, vulns Vulns) *SARIFBuilder {
+	return &SARIFBuilder{
+		vulns: vulns,
+		rules: make(map[string]int),
+		run: SARIFRun{
+			Tool: SARIFTool{Driver: SARIFDriver{
+				Name:    ToolName,
+				Version: version,
+				Rules:   make([]SARIFRule, 0),
+			}},
+			Results: make([]SARIFResult, 0),
+		},
+	}
+}
+
+func (b *SARIFBuilder) Add(f *models.BhaFinding) {
+	b.run.Results = append(b.run.Results, b.result(f))
+}
+
+// AddDiff 添加任务对比结果，消失的匹配以基准任务中的位置输出
+func (b *SARIFBuilder) AddDiff(item *models.BhaDiffItem) {
+	result := b.result(item.Finding())
+	result.BaselineState = sarifBaselineState(item.Status)
+	result.Properties["delta"] = item.Delta
+	if item.Base != nil {
+		result.Properties["baseSimilarity"] = item.Base.Sim
+	}
+	b.run.Results = append(b.run.Results, result)
+}
+
+func (b *SARIFBuilder) result(f *models.BhaFinding) SARIFResult {
+	result := SARIFResult{
+		RuleId:    f.CVE,
+		RuleIndex: b.rule(f.CVE),
+		Level:     sarifLevel(b.vulns.severity(f.CVE)),
+		Message: SARIFMessage{Text: fmt.Sprintf(
+			"Function %s at 0x%s in %s is similar (%.4f) to %s of %s, which is affected by %s",
+			displayName(f.FName), strings.TrimPrefix(f.Addr, "0x"), f.FilePath, f.Sim, displayName(f.RefFName), displayPackage(f.Purl, f.Version), f.CVE,
+		)},
+		Locations: []SARIFLocation{{
+			PhysicalLocation: SARIFPhysicalLocation{
+				ArtifactLocation: SARIFArtifactLocation{Uri: f.FilePath},
+				Address:          sarifAddress(f),
+			},
+			LogicalLocations: []SARIFLogicalLocation{{Name: displayName(f.FName), Kind: "function"}},
+		}},
+		PartialFingerprints: map[string]string{
+			"binaryFunction/v1": fingerprint(f.FilePath, f.Addr, f.CVE),
+		},
+		Properties: map[string]any{
+			"similarity":  f.Sim,
+			"purl":        f.Purl,
+			"version":     f.Version,
+			"refFunction": f.RefFName,
+			"arch":        f.Arch,
+			"optLevel":    f.OptLevel,
+			"fileArch":    f.FileArch,
+		},
+	}
+	if f.SuppressionId != "" {
+		result.Suppressions = []SARIFSuppression{sarifSuppression(f)}
+	}
+	return result
+}
+
+// sarifSuppression 抑制规则在服务端管理，以 external 类型输出
+func sarifSuppression(f *models.BhaFinding) SARIFSuppression {
+	s := SARIFSuppression{Kind: "external", Status: "accepted"}
+	if f.Triage != nil {
+		s.Justification = f.Triage.Justification
+		if n := len(f.Triage.Comments); n > 0 {
+			s.Justification = f.Triage.Comments[n-1].Content
+		}
+	}
+	return s
+}
+
+func (b *SARIFBuilder) Build() *SARIF {
+	return &SARIF{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []SARIFRun{b.run},
+	}
+}
+
+// rule 返回CVE对应规则的序号，不存在时新增
+func (b *SARIFBuilder) rule(cve string) int {
+	if i, ok := b.rules[cve]; ok {
+		return i
+	}
+
+	rule := SARIFRule{
+		Id:               cve,
+		Name:             cve,
+		ShortDescription: SARIFMessage{Text: cve},
+		HelpUri:          nvdURL(cve),
+		Properties:       map[string]any{"tags": []string{"security", "vulnerability"}},
+	}
+	if vuln := b.vulns[cve]; vuln != nil {
+		if vuln.Description != "" {
+			rule.FullDescription = &SARIFMessage{Text: vuln.Description}
+		}
+		// code scanning 根据 security-severity 显示严重等级
+		rule.Properties["security-severity"] = strconv.FormatFloat(vuln.Score, 'f', 1, 64)
+		rule.Properties["tags"] = append([]string{"security", "vulnerability"}, vuln.CWEs...)
+	}
+
+	b.run.Tool.Driver.Rules = append(b.run.Tool.Driver.Rules, rule)
+	b.rules[cve] = len(b.run.Tool.Driver.Rules) - 1
+	return b.rules[cve]
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case models.SeverityCritical, models.SeverityHigh:
+		return "error"
+	case models.SeverityLow, models.SeverityNone:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+func sarifBaselineState(status string) string {
+	switch status {
+	case models.BhaDiffFixed:
+		return "absent"
+	case models.BhaDiffIntroduced:
+		return "new"
+	case models.BhaDiffChanged:
+		return "updated"
+	default:
+		return "unchanged"
+	}
+}
+
+func sarifAddress(f *models.BhaFinding) *SARIFAddress {
+	addr, err := strconv.ParseUint(strings.TrimPrefix(f.Addr, "0x"), 16, 64)
+	if err != nil {
+		return nil
+	}
+	return &SARIFAddress{AbsoluteAddress: addr, Name: f.FName, Kind: "function"}
+}
+
+func fingerprint(values ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func displayName(fname string) string {
+	if fname == "" {
+		return "<unknown>"
+	}
+	return fname
+}
+
+func displayPackage(purl, version string) string {
+	switch {
+	case purl == "":
+		return "an unknown component"
+	case version == "" || strings.Contains(purl, "@"):
+		return purl
+	default:
+		return purl + "@" + version
+	}
+}
@@ -0,0 +1,40 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/report"
+)
+
+func TestSARIFBuilder(t *testing.T) {
+	vulns := report.Vulns{
+		"CVE-2014-0160": {CVE: "CVE-2014-0160", Severity: models.SeverityHigh, Score: 7.5, CWEs: []string{"CWE-125"}},
+	}
+	builder := report.NewSARIFBuilder("1.0.0", vulns)
+	builder.Add(&models.BhaFinding{FilePath: "usr/lib/libssl.so", Addr: "4a2f0", FName: "sub_4a2f0", RefFName: "tls1_process_heartbeat", CVE: "CVE-2014-0160", Purl: "pkg:generic/openssl", Version: "1.0.1f", Sim: 0.93})
+	builder.Add(&models.BhaFinding{FilePath: "usr/bin/curl", Addr: "1000", FName: "main", CVE: "CVE-2014-0160", Sim: 0.8})
+	builder.Add(&models.BhaFinding{FilePath: "usr/bin/curl", Addr: "zz", FName: "foo", CVE: "CVE-2099-0001", Sim: 0.7})
+
+	sarif := builder.Build()
+	assert.Equal(t, "2.1.0", sarif.Version)
+
+	run := sarif.Runs[0]
+	assert.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Equal(t, "7.5", run.Tool.Driver.Rules[0].Properties["security-severity"])
+	assert.Len(t, run.Results, 3)
+
+	r := run.Results[0]
+	assert.Equal(t, "CVE-2014-0160", r.RuleId)
+	assert.Equal(t, "error", r.Level)
+	assert.Equal(t, uint64(0x4a2f0), r.Locations[0].PhysicalLocation.Address.AbsoluteAddress)
+	assert.Equal(t, 0.93, r.Properties["similarity"])
+	assert.Equal(t, 0, run.Results[1].RuleIndex)
+
+	// 漏洞库中不存在的CVE
+	assert.Equal(t, 1, run.Results[2].RuleIndex)
+	assert.Equal(t, "warning", run.Results[2].Level)
+	assert.Nil(t, run.Results[2].Locations[0].PhysicalLocation.Address)
+}
@@ -0,0 +1,132 @@
+package sca
+
+import (
+	"regexp"
+	"strings"
+)
+
+// matcher 汇总单个文件的匹配结果
+type matcher struct {
+	sigs    []*signature
+	results []*sigResult
+}
+
+// sigResult 单个组件的匹配结果
+type sigResult struct {
+	versions  []string              // 字符串匹配的版本，按出现顺序
+	evidences map[string][]Evidence // 各版本的识别依据
+	symbol    string                // 符号版本中的最高版本
+	extra     []Evidence            // SONAME、符号版本等不确定发布版本的识别依据
+}
+
+func newMatcher(sigs []*signature) *matcher {
+	m := &matcher{sigs: sigs, results: make([]*sigResult, len(sigs))}
+	for i := range m.results {
+		m.results[i] = &sigResult{evidences: make(map[string][]Evidence)}
+	}
+	return m
+}
+
+func (s *signature) patterns(typ string) []*regexp.Regexp {
+	switch typ {
+	case EvidenceString:
+		return s.strings
+	case EvidenceSoname:
+		return s.sonames
+	case EvidenceSymbol:
+		return s.symbols
+	}
+	return nil
+}
+
+func (m *matcher) match(typ, value string) {
+	for i, sig := range m.sigs {
+		for _, re := range sig.patterns(typ) {
+			sub := re.FindStringSubmatch(value)
+			if sub == nil {
+				continue
+			}
+			var version string
+			if len(sub) > 1 {
+				version = strings.ReplaceAll(sub[1], "_", ".")
+			}
+			m.results[i].add(typ, value, version)
+			break
+		}
+	}
+}
+
+func (r *sigResult) add(typ, value, version string) {
+	e := Evidence{Type: typ, Value: value}
+	switch {
+	case typ == EvidenceSymbol:
+		if version != "" && CompareVersion(version, r.symbol) > 0 {
+			r.symbol = version
+		}
+		// 符号版本通常有多个，仅保留最高版本作为依据
+		for i := range r.extra {
+			if r.extra[i].Type == EvidenceSymbol {
+				if version != "" && version == r.symbol {
+					r.extra[i] = e
+				}
+				return
+			}
+		}
+		r.extra = append(r.extra, e)
+	case version == "":
+		r.extra = appendEvidence(r.extra, e)
+	default:
+		if _, ok := r.evidences[version]; !ok {
+			r.versions = append(r.versions, version)
+		}
+		r.evidences[version] = appendEvidence(r.evidences[version], e)
+	}
+}
+
+func appendEvidence(list []Evidence, e Evidence) []Evidence {
+	if len(list) >= maxEvidences {
+		return list
+	}
+	for _, v := range list {
+		if v == e {
+			return list
+		}
+	}
+	return append(list, e)
+}
+
+// components 按字符串匹配的版本输出组件，未匹配时版本为空
+// 符号版本仅说明组件不低于该版本，不作为发布版本，另记录在 SymbolVersion 中
+func (m *matcher) components() []Component {
+	var list []Component
+	for i, sig := range m.sigs {
+		r := m.results[i]
+		newComponent := func(version string, evidence []Evidence) Component {
+			c := Component{
+				Name:     sig.Name,
+				Version:  version,
+				Purl:     sig.Purl,
+				Vendor:   sig.Vendor,
+				Product:  sig.Product,
+				Evidence: evidence,
+			}
+			if version != "" {
+				c.Purl += "@" + version
+			}
+			return c
+		}
+
+		switch {
+		case len(r.versions) > 0:
+			for _, version := range r.versions {
+				evidence := append(r.evidences[version][:len(r.evidences[version]):len(r.evidences[version])], r.extra...)
+				list = append(list, newComponent(version, evidence))
+			}
+		case len(r.extra) > 0:
+			c := newComponent("", r.extra)
+			c.SymbolVersion = r.symbol
+			list = append(list, c)
+		}
+	}
+	return list
+}
@@ -0,0 +1,185 @@
+// Package sca 根据特征库识别可执行文件中的第三方组件
+//
+// 提取文件中的可打印字符串，及 ELF 共享库的 SONAME、符号版本，与特征库匹配得到组件名称及版本
+package sca
+
+import (
+	"bufio"
+	"debug/elf"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// 组件识别依据
+const (
+	EvidenceString = "string" // 可打印字符串
+	EvidenceSoname = "soname" // 共享库 SONAME
+	EvidenceSymbol = "symbol" // 共享库定义的符号版本
+)
+
+const (
+	defaultMinLength = 4
+	maxStringLength  = 256 // 超出部分不参与匹配
+	maxEvidences     = 5   // 每个组件保留的识别依据数
+)
+
+// Evidence 组件识别依据
+type Evidence struct {
+	Type  string `json:"type"`  // string, soname, symbol
+	Value string `json:"value"` // 匹配的内容
+}
+
+// Component 识别出的组件，版本无法确定时为空
+type Component struct {
+	Name          string     `json:"name"`
+	Version       string     `json:"version"`
+	SymbolVersion string     `json:"symbol_version,omitempty"` // 符号版本中的最高版本，仅说明组件不低于该版本，不用于关联 CVE
+	Purl          string     `json:"purl"`
+	Vendor        string     `json:"vendor"`
+	Product       string     `json:"product"`
+	Evidence      []Evidence `json:"evidence"`
+}
+
+type Option func(*Detector)
+
+// WithMinLength 可打印字符串的最小长度
+func WithMinLength(n int) Option {
+	return func(d *Detector) {
+		if n > 0 {
+			d.minLength = n
+		}
+	}
+}
+
+// Detector 按特征库识别组件
+type Detector struct {
+	sigs      []*signature
+	minLength int
+}
+
+func NewDetector(sigs []Signature, opts ...Option) (*Detector, error) {
+	d := &Detector{minLength: defaultMinLength}
+	for _, s := range sigs {
+		sig, err := compile(s)
+		if err != nil {
+			return nil, err
+		}
+		d.sigs = append(d.sigs, sig)
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d, nil
+}
+
+// Detect 识别文件中的组件，同一组件的不同版本分别返回
+func (d *Detector) Detect(name string) ([]Component, error) {
+	m := newMatcher(d.sigs)
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	if err = printableStrings(bufio.NewReaderSize(f, 64<<10), d.minLength, func(s string) {
+		m.match(EvidenceString, s)
+	}); err != nil {
+		return nil, err
+	}
+
+	// 非 ELF 文件仅匹配字符串
+	if ef, err := elf.NewFile(f); err == nil {
+		if sonames, err := ef.DynString(elf.DT_SONAME); err == nil {
+			for _, soname := range sonames {
+				m.match(EvidenceSoname, soname)
+			}
+		}
+		for _, v := range definedVersions(ef) {
+			m.match(EvidenceSymbol, v)
+		}
+	}
+	return m.components(), nil
+}
+
+// printableStrings 提取连续的 ASCII 可打印字符(含制表符)
+func printableStrings(r io.ByteReader, minLength int, fn func(s string)) error {
+	var b strings.Builder
+	n := 0
+	flush := func() {
+		if n >= minLength {
+			fn(b.String())
+		}
+		b.Reset()
+		n = 0
+	}
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			flush()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if c == '\t' || (c >= 0x20 && c < 0x7f) {
+			if n < maxStringLength {
+				b.WriteByte(c)
+			}
+			n++
+			continue
+		}
+		flush()
+	}
+}
+
+// definedVersions 解析 .gnu.version_d，返回共享库定义的符号版本，不含以文件名命名的基础版本
+//
+//	Elf_Verdef:  vd_version(2) vd_flags(2) vd_ndx(2) vd_cnt(2) vd_hash(4) vd_aux(4) vd_next(4)
+//	Elf_Verdaux: vda_name(4) vda_next(4)
+func definedVersions(f *elf.File) []string {
+	s := f.SectionByType(elf.SHT_GNU_VERDEF)
+	if s == nil || int(s.Link) >= len(f.Sections) {
+		return nil
+	}
+	data, err := s.Data()
+	if err != nil {
+		return nil
+	}
+	str, err := f.Sections[s.Link].Data()
+	if err != nil {
+		return nil
+	}
+
+	const verFlagBase = 0x1
+	var versions []string
+	order := f.ByteOrder
+	for off, i := 0, 0; off+20 <= len(data) && i < int(s.Info); i++ {
+		flags := order.Uint16(data[off+2:])
+		aux := off + int(order.Uint32(data[off+12:]))
+		if flags&verFlagBase == 0 && aux+8 <= len(data) {
+			if name := cstring(str, int(order.Uint32(data[aux:]))); name != "" {
+				versions = append(versions, name)
+			}
+		}
+		next := int(order.Uint32(data[off+16:]))
+		if next == 0 {
+			break
+		}
+		off += next
+	}
+	return versions
+}
+
+func cstring(data []byte, off int) string {
+	if off < 0 || off >= len(data) {
+		return ""
+	}
+	end := off
+	for end < len(data) && data[end] != 0 {
+		end++
+	}
+	return string(data[off:end])
+}
@@ -0,0 +1,89 @@
+package sca_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/sca"
+)
+
+func TestDetector_Detect(t *testing.T) {
+	d, err := sca.NewDetector(sca.DefaultSignatures())
+	require.NoError(t, err)
+
+	name := filepath.Join(t.TempDir(), "firmware.bin")
+	data := "\x7f\x00\x01OpenSSL 1.0.1f 6 Jan 2014\x00\x02\x03BusyBox v1.24.1 (2016-04-01 10:00:00 CST)\x00" +
+		"\x00OpenSSH_7.4*\x00abc\x00"
+	require.NoError(t, os.WriteFile(name, []byte(data), 0o644))
+
+	components, err := d.Detect(name)
+	require.NoError(t, err)
+	assert.Equal(t, []sca.Component{
+		{
+			Name: "openssl", Version: "1.0.1f", Purl: "pkg:generic/openssl@1.0.1f", Vendor: "openssl", Product: "openssl",
+			Evidence: []sca.Evidence{{Type: sca.EvidenceString, Value: "OpenSSL 1.0.1f 6 Jan 2014"}},
+		},
+		{
+			Name: "busybox", Version: "1.24.1", Purl: "pkg:generic/busybox@1.24.1", Vendor: "busybox", Product: "busybox",
+			Evidence: []sca.Evidence{{Type: sca.EvidenceString, Value: "BusyBox v1.24.1 (2016-04-01 10:00:00 CST)"}},
+		},
+	}, components)
+}
+
+func TestMergeSignatures(t *testing.T) {
+	extra, err := sca.ParseSignatures([]byte(`[
+		{"name": "busybox", "purl": "pkg:generic/busybox", "strings": ["^BB v(\\d+\\.\\d+)"]},
+		{"name": "acme", "purl": "pkg:generic/acme", "strings": ["^acme-(\\d+)"]}
+	]`))
+	require.NoError(t, err)
+
+	sigs := sca.MergeSignatures(sca.DefaultSignatures(), extra)
+	assert.Equal(t, len(sca.DefaultSignatures())+1, len(sigs))
+	assert.Equal(t, "acme", sigs[len(sigs)-1].Name)
+	for _, s := range sigs {
+		if s.Name == "busybox" {
+			assert.Equal(t, []string{`^BB v(\d+\.\d+)`}, s.Strings)
+		}
+	}
+
+	_, err = sca.ParseSignatures([]byte(`[{"name": "bad", "purl": "pkg:generic/bad", "strings": ["("]}]`))
+	assert.Error(t, err)
+}
+
+func TestCompareVersion(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.1", "1.0.1f", -1},
+		{"1.0.1f", "1.0.1g", -1},
+		{"1.0.1g", "1.0.2", -1},
+		{"1.0.10", "1.0.9", 1},
+		{"7.4p1", "7.4-p1", 0},
+		{"7.4", "7.4p1", -1},
+		{"1.1.0-beta1", "1.1.0", -1},
+		{"1.2", "1.2.0", 0},
+		{"2016.74", "2020.81", -1},
+	} {
+		assert.Equal(t, c.want, sca.CompareVersion(c.a, c.b), "%s vs %s", c.a, c.b)
+		assert.Equal(t, -c.want, sca.CompareVersion(c.b, c.a), "%s vs %s", c.b, c.a)
+	}
+}
+
+func TestAffected(t *testing.T) {
+	heartbleed := models.VulnAffected{Vendor: "openssl", Product: "openssl", StartIncluding: "1.0.1", EndExcluding: "1.0.1g"}
+	assert.True(t, sca.Affected("1.0.1f", heartbleed))
+	assert.True(t, sca.Affected("1.0.1", heartbleed))
+	assert.False(t, sca.Affected("1.0.1g", heartbleed))
+	assert.False(t, sca.Affected("1.0.0t", heartbleed))
+	assert.False(t, sca.Affected("", heartbleed))
+
+	exact := models.VulnAffected{Vendor: "busybox", Product: "busybox", Version: "1.24.1"}
+	assert.True(t, sca.Affected("1.24.1", exact))
+	assert.False(t, sca.Affected("1.24.2", exact))
+}
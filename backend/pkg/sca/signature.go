@@ -0,0 +1,111 @@
+package sca
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+//go:embed signatures.json
+var defaultSignatures []byte
+
+// Signature 组件特征，正则中的第一个分组为版本号
+type Signature struct {
+	Name    string   `json:"name"`    // 组件名称
+	Purl    string   `json:"purl"`    // 不含版本的 purl，如 pkg:generic/openssl
+	Vendor  string   `json:"vendor"`  // CPE vendor，用于关联漏洞
+	Product string   `json:"product"` // CPE product，用于关联漏洞
+	Strings []string `json:"strings"` // 可打印字符串
+	Sonames []string `json:"sonames"` // 共享库 SONAME，通常不含发布版本，仅用于识别组件
+	Symbols []string `json:"symbols"` // 共享库定义的符号版本(.gnu.version_d)，取最高版本，仅在字符串未匹配到版本时使用
+}
+
+// DefaultSignatures 内置特征库
+func DefaultSignatures() []Signature {
+	sigs, err := ParseSignatures(defaultSignatures)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in signatures, %s", err))
+	}
+	return sigs
+}
+
+// LoadSignatures 从 JSON 文件加载特征库
+func LoadSignatures(name string) ([]Signature, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSignatures(data)
+}
+
+// ParseSignatures 解析并校验 JSON 格式的特征库
+func ParseSignatures(data []byte) ([]Signature, error) {
+	var sigs []Signature
+	if err := json.Unmarshal(data, &sigs); err != nil {
+		return nil, err
+	}
+	for i := range sigs {
+		if _, err := compile(sigs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return sigs, nil
+}
+
+// MergeSignatures 合并特征库，同名组件以 extra 为准
+func MergeSignatures(base, extra []Signature) []Signature {
+	list := make([]Signature, 0, len(base)+len(extra))
+	index := make(map[string]int, len(base)+len(extra))
+	for _, sigs := range [][]Signature{base, extra} {
+		for _, s := range sigs {
+			if i, ok := index[s.Name]; ok {
+				list[i] = s
+				continue
+			}
+			index[s.Name] = len(list)
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+type signature struct {
+	Signature
+	strings []*regexp.Regexp
+	sonames []*regexp.Regexp
+	symbols []*regexp.Regexp
+}
+
+func compile(s Signature) (*signature, error) {
+	if s.Name == "" || s.Purl == "" {
+		return nil, fmt.Errorf("signature name and purl are required")
+	}
+
+	sig := &signature{Signature: s}
+	for _, item := range []struct {
+		exprs []string
+		list  *[]*regexp.Regexp
+	}{
+		{s.Strings, &sig.strings},
+		{s.Sonames, &sig.sonames},
+		{s.Symbols, &sig.symbols},
+	} {
+		for _, expr := range item.exprs {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("signature %s, %w", s.Name, err)
+			}
+			*item.list = append(*item.list, re)
+		}
+	}
+	return sig, nil
+}
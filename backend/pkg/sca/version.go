@@ -0,0 +1,115 @@
+package sca
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+// preReleases 版本号末尾的预发布标记，低于正式版本
+var preReleases = []string{"alpha", "beta", "pre", "rc", "dev"}
+
+// CompareVersion 比较版本号，按连续的数字、字母分段依次比较，忽略分隔符
+//
+//	1.0.1 < 1.0.1f < 1.0.1g < 1.0.2
+//	7.4 < 7.4p1 == 7.4-p1
+//	1.1.0-beta1 < 1.1.0
+//	1.2 == 1.2.0
+func CompareVersion(a, b string) int {
+	as, bs := versionSegments(a), versionSegments(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var c int
+		switch {
+		case i >= len(as):
+			c = -compareTail(bs[i])
+		case i >= len(bs):
+			c = compareTail(as[i])
+		default:
+			c = compareSegment(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareTail 较长版本号多出的分段，为 0 时相等，为预发布标记时较小
+func compareTail(segment string) int {
+	if n, err := strconv.ParseUint(segment, 10, 64); err == nil && n == 0 {
+		return 0
+	}
+	for _, p := range preReleases {
+		if strings.EqualFold(segment, p) {
+			return -1
+		}
+	}
+	return 1
+}
+
+func compareSegment(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return 1
+	case bErr == nil:
+		return -1
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func versionSegments(version string) []string {
+	var segments []string
+	start := -1
+	for i, r := range version {
+		if start >= 0 && (!isVersionRune(r) || unicode.IsDigit(r) != unicode.IsDigit(rune(version[start]))) {
+			segments = append(segments, version[start:i])
+			start = -1
+		}
+		if start < 0 && isVersionRune(r) {
+			start = i
+		}
+	}
+	if start >= 0 {
+		segments = append(segments, version[start:])
+	}
+	return segments
+}
+
+func isVersionRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsDigit(r) || unicode.IsLetter(r))
+}
+
+// Affected 判断版本是否在受影响的范围内，版本未知时不关联
+func Affected(version string, a models.VulnAffected) bool {
+	if version == "" {
+		return false
+	}
+	if a.Version != "" {
+		return CompareVersion(version, a.Version) == 0
+	}
+	if a.StartIncluding != "" && CompareVersion(version, a.StartIncluding) < 0 {
+		return false
+	}
+	if a.StartExcluding != "" && CompareVersion(version, a.StartExcluding) <= 0 {
+		return false
+	}
+	if a.EndIncluding != "" && CompareVersion(version, a.EndIncluding) > 0 {
+		return false
+	}
+	if a.EndExcluding != "" && CompareVersion(version, a.EndExcluding) >= 0 {
+		return false
+	}
+	return true
+}
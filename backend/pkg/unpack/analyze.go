@@ -0,0 +1,303 @@
+package unpack
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+// ErrNotBinary 不是 ELF、PE、Mach-O 可执行文件
+var ErrNotBinary = errors.New("not an executable")
+
+const (
+	endianLittle = "little"
+	endianBig    = "big"
+)
+
+// Analyze 解析可执行文件，记录格式、架构、位数、字节序、符号表、build-id、依赖库等信息
+func Analyze(name string) (*models.BinaryInfo, error) {
+	format, err := BinaryFormat(name)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case models.BinaryFormatELF:
+		return analyzeELF(name)
+	case models.BinaryFormatPE:
+		return analyzePE(name)
+	case models.BinaryFormatMachO:
+		return analyzeMachO(name)
+	}
+	return nil, ErrNotBinary
+}
+
+func endian(order binary.ByteOrder) string {
+	if order == binary.BigEndian {
+		return endianBig
+	}
+	return endianLittle
+}
+
+func analyzeELF(name string) (*models.BinaryInfo, error) {
+	f, err := elf.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info := &models.BinaryInfo{
+		Format: models.BinaryFormatELF,
+		Arch:   elfArch(f),
+		Bits:   32,
+		Endian: endian(f.ByteOrder),
+	}
+	if f.Class == elf.ELFCLASS64 {
+		info.Bits = 64
+	}
+
+	// 没有 .symtab 时仅统计动态符号表中的函数
+	symbols, err := f.Symbols()
+	info.Stripped = err != nil || len(symbols) == 0
+	if info.Stripped {
+		symbols, _ = f.DynamicSymbols()
+	}
+	for _, s := range symbols {
+		if elf.ST_TYPE(s.Info) == elf.STT_FUNC && s.Section != elf.SHN_UNDEF {
+			info.FuncCount++
+		}
+	}
+
+	if s := f.Section(".note.gnu.build-id"); s != nil {
+		if data, err := s.Data(); err == nil {
+			info.BuildId = elfBuildId(data, f.ByteOrder)
+		}
+	}
+	if sonames, err := f.DynString(elf.DT_SONAME); err == nil && len(sonames) > 0 {
+		info.Soname = sonames[0]
+	}
+	info.Needed, _ = f.ImportedLibraries()
+	if s := f.Section(".comment"); s != nil {
+		if data, err := s.Data(); err == nil {
+			info.Compilers = comments(data)
+		}
+	}
+	return info, nil
+}
+
+func elfArch(f *elf.File) string {
+	is64 := f.Class == elf.ELFCLASS64
+	switch f.Machine {
+	case elf.EM_386:
+		return "x86"
+	case elf.EM_X86_64:
+		return "x86_64"
+	case elf.EM_ARM:
+		return "arm"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_MIPS, elf.EM_MIPS_RS3_LE:
+		if is64 {
+			return "mips64"
+		}
+		return "mips"
+	case elf.EM_PPC:
+		return "ppc"
+	case elf.EM_PPC64:
+		return "ppc64"
+	case elf.EM_RISCV:
+		if is64 {
+			return "riscv64"
+		}
+		return "riscv32"
+	}
+	return strings.ToLower(strings.TrimPrefix(f.Machine.String(), "EM_"))
+}
+
+// elfBuildId 解析 GNU build-id 注释: namesz, descsz, type(3), "GNU\0", desc
+func elfBuildId(data []byte, order binary.ByteOrder) string {
+	for len(data) >= 12 {
+		namesz := int(order.Uint32(data))
+		descsz := int(order.Uint32(data[4:]))
+		typ := order.Uint32(data[8:])
+		nameEnd := 12 + alignUp4(namesz)
+		if namesz < 0 || descsz < 0 || nameEnd+descsz > len(data) {
+			return ""
+		}
+		if typ == 3 && string(data[12:12+namesz]) == "GNU\x00" {
+			return hex.EncodeToString(data[nameEnd : nameEnd+descsz])
+		}
+		data = data[nameEnd+alignUp4(descsz):]
+	}
+	return ""
+}
+
+func alignUp4(n int) int {
+	return (n + 3) &^ 3
+}
+
+// comments 解析 .comment 段中以 \0 分隔的编译器信息，去除重复
+func comments(data []byte) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, b := range bytes.Split(data, []byte{0}) {
+		s := strings.TrimSpace(string(b))
+		if s != "" && !seen[s] {
+			seen[s] = true
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+const (
+	peDebugStripped = 0x0200 // IMAGE_FILE_DEBUG_STRIPPED
+	peSymFunction   = 0x20   // IMAGE_SYM_DTYPE_FUNCTION << 4
+)
+
+func analyzePE(name string) (*models.BinaryInfo, error) {
+	f, err := pe.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info := &models.BinaryInfo{
+		Format: models.BinaryFormatPE,
+		Arch:   peArch(f.Machine),
+		Bits:   32,
+		Endian: endianLittle,
+	}
+	if _, ok := f.OptionalHeader.(*pe.OptionalHeader64); ok {
+		info.Bits = 64
+	}
+
+	// 发布版本的 PE 通常不包含 COFF 符号表
+	info.Stripped = f.Characteristics&peDebugStripped != 0 || len(f.COFFSymbols) == 0
+	for _, s := range f.COFFSymbols {
+		if s.Type&0xf0 == peSymFunction && s.SectionNumber > 0 {
+			info.FuncCount++
+		}
+	}
+	if libs, err := f.ImportedLibraries(); err == nil {
+		info.Needed = libs
+	}
+	return info, nil
+}
+
+func peArch(machine uint16) string {
+	switch machine {
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return "x86"
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return "x86_64"
+	case pe.IMAGE_FILE_MACHINE_ARM, pe.IMAGE_FILE_MACHINE_ARMNT, pe.IMAGE_FILE_MACHINE_THUMB:
+		return "arm"
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return "arm64"
+	case pe.IMAGE_FILE_MACHINE_RISCV64:
+		return "riscv64"
+	}
+	return fmt.Sprintf("unknown(0x%x)", machine)
+}
+
+const (
+	machoLoadIdDylib = 0xd  // LC_ID_DYLIB
+	machoLoadUUID    = 0x1b // LC_UUID
+	machoStab        = 0xe0 // N_STAB
+	machoTypeMask    = 0x0e // N_TYPE
+	machoSect        = 0x0e // N_SECT
+)
+
+// analyzeMachO 通用二进制仅解析第一个架构
+func analyzeMachO(name string) (*models.BinaryInfo, error) {
+	f, err := macho.Open(name)
+	if err != nil {
+		fat, fatErr := macho.OpenFat(name)
+		if fatErr != nil {
+			return nil, err
+		}
+		defer func() { _ = fat.Close() }()
+		if len(fat.Arches) == 0 {
+			return nil, ErrNotBinary
+		}
+		return machoInfo(fat.Arches[0].File), nil
+	}
+	defer func() { _ = f.Close() }()
+	return machoInfo(f), nil
+}
+
+func machoInfo(f *macho.File) *models.BinaryInfo {
+	info := &models.BinaryInfo{
+		Format: models.BinaryFormatMachO,
+		Arch:   machoArch(f.Cpu),
+		Bits:   32,
+		Endian: endian(f.ByteOrder),
+	}
+	if f.Magic == macho.Magic64 {
+		info.Bits = 64
+	}
+
+	// __text 段中的非调试符号
+	var text uint8
+	for i, s := range f.Sections {
+		if s.Name == "__text" && s.Seg == "__TEXT" {
+			text = uint8(i + 1)
+		}
+	}
+	if f.Symtab != nil {
+		for _, s := range f.Symtab.Syms {
+			if s.Type&machoStab == 0 && s.Type&machoTypeMask == machoSect && text != 0 && s.Sect == text {
+				info.FuncCount++
+			}
+		}
+	}
+	info.Stripped = info.FuncCount == 0
+
+	for _, l := range f.Loads {
+		raw := l.Raw()
+		if len(raw) < 8 {
+			continue
+		}
+		switch f.ByteOrder.Uint32(raw) {
+		case machoLoadUUID:
+			if len(raw) >= 24 {
+				info.BuildId = hex.EncodeToString(raw[8:24])
+			}
+		case machoLoadIdDylib:
+			if len(raw) >= 12 {
+				off := int(f.ByteOrder.Uint32(raw[8:]))
+				if off < len(raw) {
+					info.Soname = string(bytes.TrimRight(raw[off:], "\x00"))
+				}
+			}
+		}
+	}
+	info.Needed, _ = f.ImportedLibraries()
+	return info
+}
+
+func machoArch(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.Cpu386:
+		return "x86"
+	case macho.CpuAmd64:
+		return "x86_64"
+	case macho.CpuArm:
+		return "arm"
+	case macho.CpuArm64:
+		return "arm64"
+	case macho.CpuPpc:
+		return "ppc"
+	case macho.CpuPpc64:
+		return "ppc64"
+	}
+	return strings.ToLower(strings.TrimPrefix(cpu.String(), "Cpu"))
+}
@@ -0,0 +1,128 @@
+package unpack_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/unpack"
+)
+
+// elfWithSections 构造仅包含 .comment 及 .note.gnu.build-id 段的 aarch64 ELF
+func elfWithSections(buildId []byte, comment string) []byte {
+	le := binary.LittleEndian
+	note := make([]byte, 16, 16+len(buildId))
+	le.PutUint32(note, 4)
+	le.PutUint32(note[4:], uint32(len(buildId)))
+	le.PutUint32(note[8:], 3)
+	copy(note[12:], "GNU\x00")
+	note = append(note, buildId...)
+	shstrtab := []byte("\x00.comment\x00.note.gnu.build-id\x00.shstrtab\x00")
+
+	type section struct {
+		name, typ uint32
+		data      []byte
+	}
+	sections := []section{
+		{name: 1, typ: 1, data: []byte(comment)}, // SHT_PROGBITS
+		{name: 10, typ: 7, data: note},           // SHT_NOTE
+		{name: 29, typ: 3, data: shstrtab},       // SHT_STRTAB
+	}
+
+	var body bytes.Buffer
+	offsets := make([]int, len(sections))
+	for i, s := range sections {
+		offsets[i] = 64 + body.Len()
+		body.Write(s.data)
+	}
+	shoff := 64 + body.Len()
+
+	hdr := make([]byte, 64)
+	copy(hdr, "\x7fELF\x02\x01\x01")
+	le.PutUint16(hdr[16:], 3)   // ET_DYN
+	le.PutUint16(hdr[18:], 183) // EM_AARCH64
+	le.PutUint32(hdr[20:], 1)
+	le.PutUint64(hdr[40:], uint64(shoff))
+	le.PutUint16(hdr[52:], 64)
+	le.PutUint16(hdr[58:], 64)
+	le.PutUint16(hdr[60:], uint16(len(sections)+1))
+	le.PutUint16(hdr[62:], uint16(len(sections)))
+
+	out := append(hdr, body.Bytes()...)
+	out = append(out, make([]byte, 64)...) // SHN_UNDEF
+	for i, s := range sections {
+		sh := make([]byte, 64)
+		le.PutUint32(sh, s.name)
+		le.PutUint32(sh[4:], s.typ)
+		le.PutUint64(sh[24:], uint64(offsets[i]))
+		le.PutUint64(sh[32:], uint64(len(s.data)))
+		le.PutUint64(sh[48:], 1)
+		out = append(out, sh...)
+	}
+	return out
+}
+
+func TestAnalyze(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "libfoo.so")
+	require.NoError(t, os.WriteFile(name, elfWithSections([]byte{0xde, 0xad, 0xbe, 0xef}, "GCC: (GNU) 9.3.0\x00clang version 14.0.0\x00GCC: (GNU) 9.3.0\x00"), 0o644))
+
+	info, err := unpack.Analyze(name)
+	require.NoError(t, err)
+	assert.Equal(t, &models.BinaryInfo{
+		Format:    models.BinaryFormatELF,
+		Arch:      "arm64",
+		Bits:      64,
+		Endian:    "little",
+		Stripped:  true,
+		BuildId:   "deadbeef",
+		Compilers: []string{"GCC: (GNU) 9.3.0", "clang version 14.0.0"},
+	}, info)
+
+	name = filepath.Join(dir, "readme.txt")
+	require.NoError(t, os.WriteFile(name, []byte("hello"), 0o644))
+	_, err = unpack.Analyze(name)
+	assert.ErrorIs(t, err, unpack.ErrNotBinary)
+}
+
+func TestSniff(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		name = filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(name, data, 0o644))
+		return name
+	}
+
+	info, err := unpack.Sniff(write("libfoo.so", elfWithSections([]byte{0xde, 0xad, 0xbe, 0xef}, "GCC: (GNU) 9.3.0\x00")))
+	require.NoError(t, err)
+	assert.Equal(t, &models.BinaryInfo{Format: models.BinaryFormatELF, Arch: "arm64", Bits: 64, Endian: "little"}, info)
+
+	// MZ 头后为 PE 签名、COFF 文件头及 PE32+ 可选头魔数
+	pe := make([]byte, 0x40+26)
+	copy(pe, "MZ")
+	binary.LittleEndian.PutUint32(pe[0x3c:], 0x40)
+	copy(pe[0x40:], "PE\x00\x00")
+	binary.LittleEndian.PutUint16(pe[0x44:], 0x8664)
+	binary.LittleEndian.PutUint16(pe[0x40+24:], 0x20b)
+	info, err = unpack.Sniff(write("foo.dll", pe))
+	require.NoError(t, err)
+	assert.Equal(t, &models.BinaryInfo{Format: models.BinaryFormatPE, Arch: "x86_64", Bits: 64, Endian: "little"}, info)
+
+	macho := make([]byte, 32)
+	binary.LittleEndian.PutUint32(macho, 0xfeedfacf)
+	binary.LittleEndian.PutUint32(macho[4:], 0x0100000c)
+	info, err = unpack.Sniff(write("foo.dylib", macho))
+	require.NoError(t, err)
+	assert.Equal(t, &models.BinaryInfo{Format: models.BinaryFormatMachO, Arch: "arm64", Bits: 64, Endian: "little"}, info)
+
+	_, err = unpack.Sniff(write("truncated.dll", pe[:0x40]))
+	assert.ErrorIs(t, err, unpack.ErrNotBinary)
+	_, err = unpack.Sniff(write("readme.txt", []byte("hello")))
+	assert.ErrorIs(t, err, unpack.ErrNotBinary)
+}
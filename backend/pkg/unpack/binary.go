@@ -0,0 +1,187 @@
+package unpack
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+const headerSize = 64
+
+// BinaryFormat 根据文件头识别可执行文件格式 elf, pe, macho，不是可执行文件时返回空
+func BinaryFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, headerSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	buf = buf[:n]
+
+	switch {
+	case elfMatcher(buf):
+		return models.BinaryFormatELF, nil
+	case machoMatcher(buf):
+		return models.BinaryFormatMachO, nil
+	case len(buf) == headerSize && buf[0] == 'M' && buf[1] == 'Z':
+		ok, err := peMatcher(f, buf)
+		if err != nil || !ok {
+			return "", err
+		}
+		return models.BinaryFormatPE, nil
+	}
+	return "", nil
+}
+
+// Sniff 仅根据文件头识别可执行文件的格式、架构、位数及字节序，不解析符号表、依赖库等，不是可执行文件时返回 ErrNotBinary
+func Sniff(path string) (*models.BinaryInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, headerSize)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	buf = buf[:n]
+
+	switch {
+	case elfMatcher(buf) && len(buf) >= 20:
+		return sniffELF(buf), nil
+	case machoMatcher(buf):
+		return sniffMachO(f, buf)
+	case len(buf) == headerSize && buf[0] == 'M' && buf[1] == 'Z':
+		return sniffPE(f, buf)
+	}
+	return nil, ErrNotBinary
+}
+
+// sniffELF e_ident 中的位数、字节序及 e_machine
+func sniffELF(buf []byte) *models.BinaryInfo {
+	var order binary.ByteOrder = binary.LittleEndian
+	if elf.Data(buf[elf.EI_DATA]) == elf.ELFDATA2MSB {
+		order = binary.BigEndian
+	}
+	header := elf.FileHeader{Class: elf.Class(buf[elf.EI_CLASS]), Machine: elf.Machine(order.Uint16(buf[18:]))}
+
+	info := &models.BinaryInfo{
+		Format: models.BinaryFormatELF,
+		Arch:   elfArch(&elf.File{FileHeader: header}),
+		Bits:   32,
+		Endian: endian(order),
+	}
+	if header.Class == elf.ELFCLASS64 {
+		info.Bits = 64
+	}
+	return info
+}
+
+// sniffMachO 通用二进制读取第一个架构的文件头
+func sniffMachO(r io.ReaderAt, buf []byte) (*models.BinaryInfo, error) {
+	if magic := binary.BigEndian.Uint32(buf); magic == 0xcafebabe || magic == 0xcafebabf {
+		// fat_arch: cputype, cpusubtype, offset
+		header := make([]byte, 8)
+		n, err := r.ReadAt(header, int64(binary.BigEndian.Uint32(buf[16:])))
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if n < len(header) {
+			return nil, ErrNotBinary
+		}
+		buf = header
+	}
+
+	var order binary.ByteOrder = binary.BigEndian
+	magic := order.Uint32(buf)
+	if magic == 0xcefaedfe || magic == 0xcffaedfe {
+		order = binary.LittleEndian
+		magic = order.Uint32(buf)
+	}
+	if magic != macho.Magic32 && magic != macho.Magic64 {
+		return nil, ErrNotBinary
+	}
+
+	info := &models.BinaryInfo{
+		Format: models.BinaryFormatMachO,
+		Arch:   machoArch(macho.Cpu(order.Uint32(buf[4:]))),
+		Bits:   32,
+		Endian: endian(order),
+	}
+	if magic == macho.Magic64 {
+		info.Bits = 64
+	}
+	return info, nil
+}
+
+// sniffPE PE 签名后的 COFF 文件头中的 Machine 及可选头的魔数
+func sniffPE(r io.ReaderAt, buf []byte) (*models.BinaryInfo, error) {
+	// PE\0\0, Machine(2), ..., 可选头 Magic 位于签名后 24 字节
+	header := make([]byte, 26)
+	n, err := r.ReadAt(header, int64(binary.LittleEndian.Uint32(buf[0x3c:])))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n < len(header) || string(header[:4]) != "PE\x00\x00" {
+		return nil, ErrNotBinary
+	}
+
+	info := &models.BinaryInfo{
+		Format: models.BinaryFormatPE,
+		Arch:   peArch(binary.LittleEndian.Uint16(header[4:])),
+		Bits:   32,
+		Endian: endianLittle,
+	}
+	if binary.LittleEndian.Uint16(header[24:]) == 0x20b {
+		info.Bits = 64
+	}
+	return info, nil
+}
+
+// elfMatcher
+// 0x7f 0x45 0x4c 0x46
+func elfMatcher(buf []byte) bool {
+	return len(buf) > 3 &&
+		buf[0] == 0x7f && buf[1] == 'E' && buf[2] == 'L' && buf[3] == 'F'
+}
+
+// machoMatcher
+// 0xfeedface 0xfeedfacf 及其小端序, 以及 fat 格式 0xcafebabe 0xcafebabf
+func machoMatcher(buf []byte) bool {
+	if len(buf) < 8 {
+		return false
+	}
+	switch binary.BigEndian.Uint32(buf) {
+	case 0xfeedface, 0xfeedfacf, 0xcefaedfe, 0xcffaedfe:
+		return true
+	case 0xcafebabe, 0xcafebabf:
+		// java class 与 fat 格式魔数相同，java class 随后为版本号(主版本号>=45)，fat 格式为架构数
+		n := binary.BigEndian.Uint32(buf[4:])
+		return n > 0 && n < 45
+	}
+	return false
+}
+
+// peMatcher MZ 头中 e_lfanew 指向 PE\0\0 签名
+func peMatcher(r io.ReaderAt, buf []byte) (bool, error) {
+	offset := int64(binary.LittleEndian.Uint32(buf[0x3c:]))
+	sig := make([]byte, 4)
+	if _, err := r.ReadAt(sig, offset); err != nil {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return string(sig) == "PE\x00\x00", nil
+}
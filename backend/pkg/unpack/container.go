@@ -0,0 +1,551 @@
+package unpack
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mholt/archiver/v4"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/utils"
+	"bin-vul-inspector/pkg/utils/archive"
+)
+
+// 容器镜像格式
+const (
+	ContainerDocker = "docker" // docker save 的 tar 包
+	ContainerOCI    = "oci"    // OCI 镜像布局的 tar 包
+)
+
+// ErrNotContainer 上传文件不是 docker save 或 OCI 镜像布局的 tar 包
+var ErrNotContainer = errors.New("not a docker save or OCI image layout tarball")
+
+const (
+	whiteoutPrefix     = ".wh."
+	whiteoutMetaPrefix = ".wh..wh."
+	whiteoutOpaque     = ".wh..wh..opq"
+	maxLinkDepth       = 8
+	maxIndexDepth      = 4
+)
+
+// UnpackContainer 按顺序应用容器镜像的各层得到最终的文件系统，识别其中的文件
+//
+// 支持 docker save 及 OCI 镜像布局的 tar 包，包含多个镜像时仅处理第一个；
+// 文件系统中的文件记录引入该文件的镜像层，层中的 whiteout 文件删除下层的同名文件或目录
+func (u *Unpacker) UnpackContainer(src, dst string) (*Result, error) {
+	kind, err := archive.Identify(src)
+	if err != nil {
+		return nil, err
+	}
+	if !IsTar(kind) {
+		return nil, ErrNotContainer
+	}
+
+	// 镜像 tar 包中为清单、配置及各层的 tar 包，重复的层可能以符号链接表示
+	imageDir := filepath.Join(dst, "image")
+	links := make(map[string]string)
+	skip := func(f archiver.File, reason error) {
+		if f.LinkTarget != "" {
+			name := cleanPath(f.NameInArchive)
+			links[name] = path.Join(path.Dir(name), f.LinkTarget)
+		}
+	}
+	if err = archive.NewCompressor(archive.WithTypeOption(kind)).ExtractFunc(imageDir, src, skip, archive.WithMaxFileSizeRule(u.maxFileSize)); err != nil {
+		return nil, err
+	}
+	img, err := readImage(&imageLayout{dir: imageDir, links: links})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Archive: img.format, Image: &img.TaskImage}
+	w := &walker{
+		Unpacker: u,
+		dst:      filepath.Join(dst, "unpack"),
+		res:      res,
+		rules: []archive.Rule{
+			archive.WithMaxFileCountRule(u.maxFileCount),
+			archive.WithMaxFileSizeRule(u.maxFileSize),
+		},
+	}
+	fs := &rootfs{dir: w.nextDir(), entries: make(map[string]*layerEntry)}
+	if err = utils.NewFile(fs.dir).CreateDirIfNotExist(); err != nil {
+		return nil, err
+	}
+	for i := range img.Layers {
+		if err = fs.applyLayer(w, i, img.Layers[i], img.blobs[i]); err != nil {
+			return nil, fmt.Errorf("apply layer %s error, %w", img.Layers[i], err)
+		}
+	}
+
+	// 未写入磁盘的文件
+	for name, e := range fs.entries {
+		if !e.dir && !e.written {
+			e.file.Path = name
+			w.add(e.file)
+		}
+	}
+	if err = w.walk(fs.dir, "", 1); err != nil {
+		return nil, err
+	}
+
+	for i := range res.Files {
+		if res.Files[i].Layer == "" {
+			res.Files[i].Layer = img.layerOf(fs.entryOf(res.Files[i].Path))
+		}
+	}
+	sort.Slice(res.Files, func(i, j int) bool {
+		return res.Files[i].Path < res.Files[j].Path
+	})
+	return res, nil
+}
+
+// IsTar 是否为 tar 包，包括压缩的 tar 包
+func IsTar(kind archive.Kind) bool {
+	return kind == archive.Tar || strings.HasPrefix(string(kind), string(archive.Tar)+".")
+}
+
+// cleanPath 规范化 tar 包中的路径，去除开头的 / 及 ./
+func cleanPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// image 解析出的镜像
+type image struct {
+	models.TaskImage
+	format string
+	blobs  []string // 各层 tar 包的本地路径，与 Layers 对应
+}
+
+func (img *image) layerOf(e *layerEntry) string {
+	if e == nil {
+		return ""
+	}
+	return img.Layers[e.layer]
+}
+
+// imageLayout 解压后的镜像 tar 包
+type imageLayout struct {
+	dir   string
+	links map[string]string // 符号链接 -> 目标
+}
+
+// path 返回镜像 tar 包中文件的本地路径
+func (l *imageLayout) path(name string) (string, error) {
+	name = cleanPath(name)
+	for i := 0; i < maxLinkDepth; i++ {
+		target, ok := l.links[name]
+		if !ok {
+			break
+		}
+		name = cleanPath(target)
+	}
+	p, err := archive.SafeJoin(l.dir, name)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(p); err != nil || !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s not found in image", name)
+	}
+	return p, nil
+}
+
+func (l *imageLayout) readJSON(name string, v interface{}) ([]byte, error) {
+	p, err := l.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("invalid %s, %w", name, err)
+	}
+	return data, nil
+}
+
+// blob OCI 镜像布局中 digest 对应的文件
+func (l *imageLayout) blob(digest string) (string, error) {
+	alg, hash, ok := strings.Cut(digest, ":")
+	if !ok || alg == "" || hash == "" || strings.Contains(digest, "/") {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	return path.Join("blobs", alg, hash), nil
+}
+
+type dockerManifest struct {
+	Config   string   `json:"Config"`
+	RepoTags []string `json:"RepoTags"`
+	Layers   []string `json:"Layers"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+	Platform    *struct {
+		OS string `json:"os"`
+	} `json:"platform"`
+}
+
+type ociIndex struct {
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	Config ociDescriptor   `json:"config"`
+	Layers []ociDescriptor `json:"layers"`
+}
+
+type imageConfig struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant"`
+	RootFS       struct {
+		DiffIds []string `json:"diff_ids"`
+	} `json:"rootfs"`
+}
+
+func (c *imageConfig) platform() string {
+	if c.OS == "" || c.Architecture == "" {
+		return ""
+	}
+	p := c.OS + "/" + c.Architecture
+	if c.Variant != "" {
+		p += "/" + c.Variant
+	}
+	return p
+}
+
+// readImage 解析镜像的清单，docker 25 起 docker save 同样输出 OCI 镜像布局，优先按 OCI 解析
+func readImage(l *imageLayout) (*image, error) {
+	if _, err := l.path("index.json"); err == nil {
+		return readOCIImage(l)
+	}
+	if _, err := l.path("manifest.json"); err == nil {
+		return readDockerImage(l)
+	}
+	return nil, ErrNotContainer
+}
+
+func readDockerImage(l *imageLayout) (*image, error) {
+	var manifests []dockerManifest
+	if _, err := l.readJSON("manifest.json", &manifests); err != nil {
+		return nil, err
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("%w: empty manifest.json", ErrNotContainer)
+	}
+	m := manifests[0]
+
+	var config imageConfig
+	data, err := l.readJSON(m.Config, &config)
+	if err != nil {
+		return nil, err
+	}
+	// 镜像 id 为配置文件的 sha256
+	sum := sha256.Sum256(data)
+	img := &image{
+		TaskImage: models.TaskImage{
+			Digest:   "sha256:" + hex.EncodeToString(sum[:]),
+			Tags:     m.RepoTags,
+			Platform: config.platform(),
+		},
+		format: ContainerDocker,
+	}
+
+	for i, layer := range m.Layers {
+		blob, err := l.path(layer)
+		if err != nil {
+			return nil, err
+		}
+		// docker save 中的层未压缩，diff_id 即层的 digest
+		var digest string
+		if len(config.RootFS.DiffIds) == len(m.Layers) {
+			digest = config.RootFS.DiffIds[i]
+		} else if digest, err = fileDigest(blob); err != nil {
+			return nil, err
+		}
+		img.Layers = append(img.Layers, digest)
+		img.blobs = append(img.blobs, blob)
+	}
+	return img, nil
+}
+
+func readOCIImage(l *imageLayout) (*image, error) {
+	var index ociIndex
+	if _, err := l.readJSON("index.json", &index); err != nil {
+		return nil, err
+	}
+	desc, manifest, err := selectManifest(l, &index, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	configName, err := l.blob(manifest.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	var config imageConfig
+	if _, err = l.readJSON(configName, &config); err != nil {
+		return nil, err
+	}
+
+	img := &image{
+		TaskImage: models.TaskImage{Digest: desc.Digest, Platform: config.platform()},
+		format:    ContainerOCI,
+	}
+	for _, key := range []string{"io.containerd.image.name", "org.opencontainers.image.ref.name"} {
+		if tag := desc.Annotations[key]; tag != "" {
+			img.Tags = append(img.Tags, tag)
+			break
+		}
+	}
+	for _, layer := range manifest.Layers {
+		name, err := l.blob(layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		blob, err := l.path(name)
+		if err != nil {
+			return nil, err
+		}
+		img.Layers = append(img.Layers, layer.Digest)
+		img.blobs = append(img.blobs, blob)
+	}
+	return img, nil
+}
+
+// selectManifest 选择索引中的第一个镜像，多平台镜像的索引逐层展开，返回 index.json 中的描述符
+func selectManifest(l *imageLayout, index *ociIndex, depth int) (*ociDescriptor, *ociManifest, error) {
+	for i := range index.Manifests {
+		desc := &index.Manifests[i]
+		// 构建证明等附件的平台为 unknown
+		if desc.Platform != nil && desc.Platform.OS == "unknown" {
+			continue
+		}
+		name, err := l.blob(desc.Digest)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch desc.MediaType {
+		case "application/vnd.oci.image.index.v1+json", "application/vnd.docker.distribution.manifest.list.v2+json":
+			if depth >= maxIndexDepth {
+				return nil, nil, fmt.Errorf("%w: image index too deep", ErrNotContainer)
+			}
+			var sub ociIndex
+			if _, err = l.readJSON(name, &sub); err != nil {
+				return nil, nil, err
+			}
+			if _, manifest, err := selectManifest(l, &sub, depth+1); err != nil || manifest != nil {
+				return desc, manifest, err
+			}
+		default:
+			var manifest ociManifest
+			if _, err = l.readJSON(name, &manifest); err != nil {
+				return nil, nil, err
+			}
+			return desc, &manifest, nil
+		}
+	}
+	if depth > 0 {
+		return nil, nil, nil
+	}
+	return nil, nil, fmt.Errorf("%w: no image manifest in index.json", ErrNotContainer)
+}
+
+func fileDigest(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// rootfs 按顺序应用镜像层得到的文件系统
+type rootfs struct {
+	dir     string
+	entries map[string]*layerEntry // 路径 -> 当前可见的文件
+}
+
+type layerEntry struct {
+	layer   int  // 引入该文件的层
+	dir     bool // 目录仅用于删除时查找其中的文件
+	written bool // 是否已写入磁盘
+	file    File // 未写入磁盘的文件的记录，如非普通文件、超出限制的文件
+}
+
+// entryOf 查找文件所在的层，嵌套压缩包中的文件取压缩包所在的层
+func (fs *rootfs) entryOf(name string) *layerEntry {
+	for name != "." && name != "/" && name != "" {
+		if e, ok := fs.entries[name]; ok {
+			return e
+		}
+		name = path.Dir(name)
+	}
+	return nil
+}
+
+func (fs *rootfs) applyLayer(w *walker, layer int, digest, blob string) error {
+	kind, err := archive.Identify(blob)
+	if err != nil {
+		return err
+	}
+	if !IsTar(kind) {
+		return fmt.Errorf("layer %s is not a tar archive", digest)
+	}
+	return archive.NewCompressor(archive.WithTypeOption(kind)).Walk(blob, func(ctx context.Context, f archiver.File) error {
+		return fs.apply(w, layer, digest, f)
+	})
+}
+
+// apply 将层中的一个文件应用到文件系统
+func (fs *rootfs) apply(w *walker, layer int, digest string, f archiver.File) error {
+	for _, part := range strings.Split(f.NameInArchive, "/") {
+		if part == ".." {
+			w.add(File{TaskFile: models.TaskFile{
+				Path:    f.NameInArchive,
+				Size:    f.Size(),
+				Type:    models.TaskFileOther,
+				Skipped: fmt.Errorf("%w: %s", archive.ErrUnsafePath, f.NameInArchive).Error(),
+				Layer:   digest,
+			}})
+			return nil
+		}
+	}
+	name := cleanPath(f.NameInArchive)
+	if name == "" {
+		return nil
+	}
+
+	// whiteout 仅作用于下层的文件
+	dir, base := path.Split(name)
+	dir = strings.TrimSuffix(dir, "/")
+	if strings.HasPrefix(base, whiteoutMetaPrefix) {
+		if base == whiteoutOpaque {
+			fs.removeUnder(dir, layer)
+		}
+		return nil
+	}
+	if strings.HasPrefix(base, whiteoutPrefix) {
+		fs.remove(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), layer)
+		return nil
+	}
+
+	local := filepath.Join(fs.dir, filepath.FromSlash(name))
+	if old, ok := fs.entries[name]; ok {
+		if old.dir && f.IsDir() {
+			return nil
+		}
+		// 替换下层或同一层中先出现的同名文件
+		fs.remove(name, layer+1)
+		if old.dir {
+			_ = os.RemoveAll(local)
+		}
+	}
+	if f.IsDir() {
+		fs.entries[name] = &layerEntry{layer: layer, dir: true}
+		return nil
+	}
+
+	e := &layerEntry{layer: layer, file: File{TaskFile: models.TaskFile{Size: f.Size(), Type: models.TaskFileOther}}}
+	fs.entries[name] = e
+
+	open := f.Open
+	if hdr, ok := f.Header.(*tar.Header); ok && hdr.Typeflag == tar.TypeLink {
+		// 硬链接复制同一镜像中已写入的文件
+		src := fs.entries[cleanPath(f.LinkTarget)]
+		if src == nil || !src.written {
+			e.file.Skipped = fmt.Sprintf("hard link to missing file %s", f.LinkTarget)
+			return nil
+		}
+		srcPath := filepath.Join(fs.dir, filepath.FromSlash(cleanPath(f.LinkTarget)))
+		info, err := os.Stat(srcPath)
+		if err != nil {
+			return err
+		}
+		f = archiver.File{FileInfo: info, NameInArchive: f.NameInArchive}
+		e.file.Size = info.Size()
+		open = func() (io.ReadCloser, error) { return os.Open(srcPath) }
+	} else if !f.Mode().IsRegular() {
+		e.file.Skipped = fmt.Sprintf("not a regular file (%s)", f.Mode().Type())
+		return nil
+	}
+
+	if err := archive.Chain([]archiver.File{f}, w.rules...); err != nil {
+		e.file.Skipped = err.Error()
+		return nil
+	}
+	if err := writeFile(local, open); err != nil {
+		return err
+	}
+	e.written = true
+	return nil
+}
+
+func writeFile(name string, open func() (io.ReadCloser, error)) error {
+	r, err := open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	out, err := utils.NewFile(name).Create()
+	if err != nil {
+		return fmt.Errorf("create file failed, err: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	if _, err = io.Copy(out, r); err != nil {
+		return fmt.Errorf("write file failed, err: %w", err)
+	}
+	return nil
+}
+
+// remove 删除 below 之前的层中的文件，目录同时删除其中的文件
+func (fs *rootfs) remove(name string, below int) {
+	e, ok := fs.entries[name]
+	if !ok || e.layer >= below {
+		return
+	}
+	fs.delete(name, e)
+	if e.dir {
+		fs.removeUnder(name, below)
+	}
+}
+
+// removeUnder 删除目录 dir 中 below 之前的层中的文件，dir 为空时为根目录
+func (fs *rootfs) removeUnder(dir string, below int) {
+	prefix := dir + "/"
+	for name, e := range fs.entries {
+		if (dir == "" || strings.HasPrefix(name, prefix)) && e.layer < below {
+			fs.delete(name, e)
+		}
+	}
+}
+
+func (fs *rootfs) delete(name string, e *layerEntry) {
+	delete(fs.entries, name)
+	if e.written {
+		_ = os.Remove(filepath.Join(fs.dir, filepath.FromSlash(name)))
+	}
+}
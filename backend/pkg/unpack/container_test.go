@@ -0,0 +1,185 @@
+package unpack_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bin-vul-inspector/pkg/unpack"
+)
+
+type tarEntry struct {
+	hdr  tar.Header
+	data []byte
+}
+
+func regular(name string, data []byte) tarEntry {
+	return tarEntry{hdr: tar.Header{Name: name, Mode: 0o755, Typeflag: tar.TypeReg, Size: int64(len(data))}, data: data}
+}
+
+func tarFile(t *testing.T, entries ...tarEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := e.hdr
+		require.NoError(t, tw.WriteHeader(&hdr))
+		_, err := tw.Write(e.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+// imageLayers 两层镜像，第二层删除 libold.so 及 /opt/app 中第一层的文件
+func imageLayers(t *testing.T) [][]byte {
+	base := tarFile(t,
+		tarEntry{hdr: tar.Header{Name: "bin/", Mode: 0o755, Typeflag: tar.TypeDir}},
+		regular("bin/busybox", elfFile()),
+		regular("usr/lib/libold.so", elfFile()),
+		regular("opt/app/a", elfFile()),
+		regular("etc/passwd", []byte("root:x:0:0::/root:/bin/sh\n")),
+	)
+	top := tarFile(t,
+		tarEntry{hdr: tar.Header{Name: "usr/lib/.wh.libold.so", Typeflag: tar.TypeReg}},
+		tarEntry{hdr: tar.Header{Name: "opt/app/.wh..wh..opq", Typeflag: tar.TypeReg}},
+		regular("opt/app/b", elfFile()),
+		tarEntry{hdr: tar.Header{Name: "bin/ls", Typeflag: tar.TypeLink, Linkname: "bin/busybox"}},
+		tarEntry{hdr: tar.Header{Name: "bin/sh", Typeflag: tar.TypeSymlink, Linkname: "busybox"}},
+	)
+	return [][]byte{base, top}
+}
+
+func assertImageFiles(t *testing.T, res *unpack.Result, layers []string) {
+	files := make(map[string]unpack.File)
+	for _, f := range res.Files {
+		files[f.Path] = f
+	}
+	assert.NotContains(t, files, "usr/lib/libold.so")
+	assert.NotContains(t, files, "opt/app/a")
+	assert.Contains(t, files["bin/sh"].Skipped, "not a regular file")
+	assert.Equal(t, layers[1], files["bin/sh"].Layer)
+	assert.Equal(t, unpack.SkipNotExecutable, files["etc/passwd"].Skipped)
+	assert.Equal(t, layers[0], files["etc/passwd"].Layer)
+
+	units := make(map[string]string)
+	for _, f := range res.Units() {
+		units[f.Path] = f.Layer
+	}
+	assert.Equal(t, map[string]string{
+		"bin/busybox": layers[0],
+		"bin/ls":      layers[1],
+		"opt/app/b":   layers[1],
+	}, units)
+}
+
+func TestUnpacker_UnpackContainer_Docker(t *testing.T) {
+	layers := imageLayers(t)
+	diffIds := []string{digest(layers[0]), digest(layers[1])}
+	config := mustJSON(t, map[string]interface{}{
+		"os":           "linux",
+		"architecture": "arm64",
+		"rootfs":       map[string]interface{}{"type": "layers", "diff_ids": diffIds},
+	})
+	manifest := mustJSON(t, []map[string]interface{}{{
+		"Config":   "config.json",
+		"RepoTags": []string{"demo:latest"},
+		"Layers":   []string{"l1/layer.tar", "l2/layer.tar"},
+	}})
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "demo.tar")
+	require.NoError(t, os.WriteFile(src, tarFile(t,
+		regular("manifest.json", manifest),
+		regular("config.json", config),
+		regular("l1/layer.tar", layers[0]),
+		regular("l2/layer.tar", layers[1]),
+	), 0o644))
+
+	res, err := unpack.New().UnpackContainer(src, filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, unpack.ContainerDocker, res.Archive)
+	require.NotNil(t, res.Image)
+	assert.Equal(t, digest(config), res.Image.Digest)
+	assert.Equal(t, []string{"demo:latest"}, res.Image.Tags)
+	assert.Equal(t, "linux/arm64", res.Image.Platform)
+	assert.Equal(t, diffIds, res.Image.Layers)
+	assertImageFiles(t, res, diffIds)
+}
+
+func TestUnpacker_UnpackContainer_OCI(t *testing.T) {
+	layers := imageLayers(t)
+	// 第一层为 gzip 压缩
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, err := gw.Write(layers[0])
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	blobs := [][]byte{gz.Bytes(), layers[1]}
+	layerDigests := []string{digest(blobs[0]), digest(blobs[1])}
+
+	config := mustJSON(t, map[string]interface{}{"os": "linux", "architecture": "amd64"})
+	manifest := mustJSON(t, map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config":        map[string]interface{}{"digest": digest(config)},
+		"layers": []map[string]interface{}{
+			{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": layerDigests[0]},
+			{"mediaType": "application/vnd.oci.image.layer.v1.tar", "digest": layerDigests[1]},
+		},
+	})
+	index := mustJSON(t, map[string]interface{}{
+		"schemaVersion": 2,
+		"manifests": []map[string]interface{}{{
+			"mediaType":   "application/vnd.oci.image.manifest.v1+json",
+			"digest":      digest(manifest),
+			"annotations": map[string]string{"io.containerd.image.name": "docker.io/library/demo:1.0"},
+		}},
+	})
+
+	entries := []tarEntry{
+		regular("oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)),
+		regular("index.json", index),
+	}
+	for _, blob := range [][]byte{config, manifest, blobs[0], blobs[1]} {
+		entries = append(entries, regular("blobs/sha256/"+digest(blob)[len("sha256:"):], blob))
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "demo.tar")
+	require.NoError(t, os.WriteFile(src, tarFile(t, entries...), 0o644))
+
+	res, err := unpack.New().UnpackContainer(src, filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, unpack.ContainerOCI, res.Archive)
+	require.NotNil(t, res.Image)
+	assert.Equal(t, digest(manifest), res.Image.Digest)
+	assert.Equal(t, []string{"docker.io/library/demo:1.0"}, res.Image.Tags)
+	assert.Equal(t, "linux/amd64", res.Image.Platform)
+	assert.Equal(t, layerDigests, res.Image.Layers)
+	assertImageFiles(t, res, layerDigests)
+
+	// 普通的 tar 包不是容器镜像
+	src = filepath.Join(dir, "plain.tar")
+	require.NoError(t, os.WriteFile(src, tarFile(t, regular("bin/busybox", elfFile())), 0o644))
+	_, err = unpack.New().UnpackContainer(src, filepath.Join(dir, "out2"))
+	assert.ErrorIs(t, err, unpack.ErrNotContainer)
+}
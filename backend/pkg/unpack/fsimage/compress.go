@@ -0,0 +1,101 @@
+package fsimage
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+// 压缩算法
+const (
+	compNone    = "none"
+	compZlib    = "zlib"
+	compDeflate = "deflate" // 无 zlib 头
+	compLZMA    = "lzma"    // 带 13 字节头的 lzma alone 格式
+	compLZO     = "lzo"
+	compXZ      = "xz"
+	compLZ4     = "lz4"
+	compZstd    = "zstd"
+)
+
+var (
+	zstdOnce    sync.Once
+	zstdDecoder *zstd.Decoder // DecodeAll 可并发调用
+	zstdErr     error
+)
+
+// decompress 解压数据块，size 为解压后大小的上限
+func decompress(comp string, src []byte, size int) ([]byte, error) {
+	switch comp {
+	case compNone:
+		return src, nil
+	case compZlib:
+		r, err := zlib.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = r.Close() }()
+		return readAtMost(r, size)
+	case compDeflate:
+		r := flate.NewReader(bytes.NewReader(src))
+		defer func() { _ = r.Close() }()
+		return readAtMost(r, size)
+	case compLZMA:
+		r, err := lzma.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		return readAtMost(r, size)
+	case compXZ:
+		r, err := xz.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		return readAtMost(r, size)
+	case compLZO:
+		return lzo1xDecompress(src, size)
+	case compLZ4:
+		dst := make([]byte, size)
+		n, err := lz4.UncompressBlock(src, dst)
+		if err != nil {
+			return nil, err
+		}
+		return dst[:n], nil
+	case compZstd:
+		zstdOnce.Do(func() {
+			zstdDecoder, zstdErr = zstd.NewReader(nil)
+		})
+		if zstdErr != nil {
+			return nil, zstdErr
+		}
+		return zstdDecoder.DecodeAll(src, make([]byte, 0, size))
+	default:
+		return nil, fmt.Errorf("unsupported compression %s", comp)
+	}
+}
+
+// rawLZMA 为无头的 lzma 数据(jffs2)补充 lzma alone 格式的头
+func rawLZMA(src []byte, props byte, dictSize uint32, size int) []byte {
+	header := make([]byte, 13, 13+len(src))
+	header[0] = props
+	binary.LittleEndian.PutUint32(header[1:], dictSize)
+	binary.LittleEndian.PutUint64(header[5:], uint64(size))
+	return append(header, src...)
+}
+
+func readAtMost(r io.Reader, size int) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	if _, err := io.Copy(buf, io.LimitReader(r, int64(size))); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,178 @@
+package fsimage
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const cpioTrailer = "TRAILER!!!"
+
+type cpioHeader struct {
+	name     string
+	mode     uint32
+	size     int64
+	dataOff  int64 // 文件内容的偏移
+	nextOff  int64 // 下一个文件头的偏移
+	trailing bool
+}
+
+// readCPIOHeader 解析 off 处的 newc/crc(070701, 070702) 或 odc(070707) 文件头
+func readCPIOHeader(r io.ReaderAt, off, size int64) (*cpioHeader, error) {
+	magic, err := readAt(r, off, 6)
+	if err != nil {
+		return nil, err
+	}
+
+	var h cpioHeader
+	var nameSize int64
+	var headerSize int64
+	var align int64
+	switch string(magic) {
+	case "070701", "070702":
+		headerSize, align = 110, 4
+		buf, err := readAt(r, off, int(headerSize))
+		if err != nil {
+			return nil, err
+		}
+		field := func(i int) (uint64, error) {
+			return strconv.ParseUint(string(buf[6+i*8:14+i*8]), 16, 32)
+		}
+		var v [13]uint64
+		for i := range v {
+			if v[i], err = field(i); err != nil {
+				return nil, fmt.Errorf("cpio header: %w", err)
+			}
+		}
+		h.mode, h.size, nameSize = uint32(v[1]), int64(v[6]), int64(v[11])
+	case "070707":
+		headerSize, align = 76, 1
+		buf, err := readAt(r, off, int(headerSize))
+		if err != nil {
+			return nil, err
+		}
+		mode, err1 := strconv.ParseUint(string(buf[18:24]), 8, 32)
+		ns, err2 := strconv.ParseUint(string(buf[59:65]), 8, 32)
+		fileSize, err3 := strconv.ParseUint(string(buf[65:76]), 8, 63)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, fmt.Errorf("cpio header: invalid odc header")
+		}
+		h.mode, h.size, nameSize = uint32(mode), int64(fileSize), int64(ns)
+	default:
+		return nil, fmt.Errorf("cpio header: bad magic")
+	}
+
+	if nameSize == 0 || nameSize > maxNameLen {
+		return nil, fmt.Errorf("cpio header: bad name size %d", nameSize)
+	}
+	name, err := readAt(r, off+headerSize, int(nameSize))
+	if err != nil {
+		return nil, err
+	}
+	h.name = strings.TrimRight(string(name), "\x00")
+	h.dataOff = alignUp(off+headerSize+nameSize, align)
+	h.nextOff = alignUp(h.dataOff+h.size, align)
+	if h.nextOff > size {
+		return nil, fmt.Errorf("cpio header: %s exceeds image", h.name)
+	}
+	h.trailing = h.name == cpioTrailer
+	return &h, nil
+}
+
+func headerCPIO(r io.ReaderAt, off, size int64) bool {
+	_, err := readCPIOHeader(r, off, size)
+	return err == nil
+}
+
+func probeCPIO(r io.ReaderAt, off, size int64) int64 {
+	for pos := off; ; {
+		h, err := readCPIOHeader(r, pos, size)
+		if err != nil {
+			return 0
+		}
+		pos = h.nextOff
+		if h.trailing {
+			return pos - off
+		}
+	}
+}
+
+func walkCPIO(r io.ReaderAt, size int64, fn WalkFunc) error {
+	for pos := int64(0); ; {
+		h, err := readCPIOHeader(r, pos, size)
+		if err != nil {
+			return err
+		}
+		if h.trailing {
+			return nil
+		}
+		pos = h.nextOff
+
+		name := cleanName(h.name)
+		if name == "" {
+			continue
+		}
+		e := &Entry{Name: name, Mode: unixMode(h.mode)}
+		switch {
+		case e.Mode.IsRegular():
+			e.Size = h.size
+			h := h
+			e.Open = func() (io.Reader, error) { return io.NewSectionReader(r, h.dataOff, h.size), nil }
+		case e.Mode&fs.ModeSymlink != 0:
+			if h.size > maxNameLen {
+				continue
+			}
+			target, err := readAt(r, h.dataOff, int(h.size))
+			if err != nil {
+				return err
+			}
+			e.Link = string(target)
+		}
+		if err = fn(e); err != nil {
+			return err
+		}
+	}
+}
+
+// cleanName 规范化归档中的路径，去除开头的 / 及 ./，包含 .. 的路径返回空
+func cleanName(name string) string {
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return ""
+		}
+	}
+	name = strings.TrimLeft(path.Clean("/"+name), "/")
+	for _, part := range strings.Split(name, "/") {
+		if !validName(part) {
+			return ""
+		}
+	}
+	return name
+}
+
+// unixMode 将 st_mode 转换为 fs.FileMode
+func unixMode(mode uint32) fs.FileMode {
+	m := fs.FileMode(mode & 0o777)
+	switch mode & 0o170000 {
+	case 0o040000:
+		m |= fs.ModeDir
+	case 0o120000:
+		m |= fs.ModeSymlink
+	case 0o020000:
+		m |= fs.ModeDevice | fs.ModeCharDevice
+	case 0o060000:
+		m |= fs.ModeDevice
+	case 0o010000:
+		m |= fs.ModeNamedPipe
+	case 0o140000:
+		m |= fs.ModeSocket
+	}
+	return m
+}
+
+func alignUp(n, align int64) int64 {
+	return (n + align - 1) / align * align
+}
@@ -0,0 +1,229 @@
+// Package fsimage 在固件镜像中查找并读取 SquashFS、CPIO、JFFS2、UBI/UBIFS 文件系统
+package fsimage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+// 文件系统类型
+const (
+	SquashFS = "squashfs"
+	CPIO     = "cpio"
+	JFFS2    = "jffs2"
+	UBI      = "ubi"
+	UBIFS    = "ubifs"
+)
+
+const (
+	scanChunkSize = 1 << 20
+	maxNameLen    = 4096
+)
+
+// ErrUnsupported 识别到文件系统但不支持其版本或压缩算法
+var ErrUnsupported = errors.New("unsupported filesystem")
+
+// Image 镜像中的文件系统
+type Image struct {
+	Type   string
+	Offset int64 // 在镜像中的偏移
+	Size   int64 // 文件系统大小
+}
+
+// Name 文件系统在解包路径中的名称，如 squashfs@0x40000
+func (img Image) Name() string {
+	return fmt.Sprintf("%s@0x%x", img.Type, img.Offset)
+}
+
+// Entry 文件系统中的文件
+type Entry struct {
+	Name string      // 以 / 分隔的相对路径
+	Mode fs.FileMode // 文件类型及权限
+	Size int64       // 普通文件的大小
+	Link string      // 符号链接的目标
+
+	Open func() (io.Reader, error) // 读取普通文件的内容
+}
+
+// WalkFunc 处理文件系统中的文件，返回错误时中止遍历
+type WalkFunc func(e *Entry) error
+
+// format 文件系统格式
+type format struct {
+	name  string
+	magic [][]byte
+	// probe 校验 off 处的文件系统，返回文件系统大小，不是该文件系统时返回 0
+	probe func(r io.ReaderAt, off, size int64) int64
+	// header 仅校验 off 处的文件系统头，不读取整个文件系统
+	header func(r io.ReaderAt, off, size int64) bool
+	walk   func(r io.ReaderAt, size int64, fn WalkFunc) error
+}
+
+var formats = []format{
+	{name: SquashFS, magic: [][]byte{[]byte("hsqs"), []byte("sqsh")}, probe: probeSquashFS, header: headerSquashFS, walk: walkSquashFS},
+	{name: CPIO, magic: [][]byte{[]byte("070701"), []byte("070702"), []byte("070707")}, probe: probeCPIO, header: headerCPIO, walk: walkCPIO},
+	{name: JFFS2, magic: [][]byte{{0x85, 0x19}, {0x19, 0x85}}, probe: probeJFFS2, header: headerJFFS2, walk: walkJFFS2},
+	{name: UBI, magic: [][]byte{[]byte("UBI#")}, probe: probeUBI, header: headerUBI, walk: walkUBI},
+	{name: UBIFS, magic: [][]byte{{0x31, 0x18, 0x10, 0x06}}, probe: probeUBIFS, header: headerUBIFS, walk: walkUBIFS},
+}
+
+func formatOf(name string) *format {
+	for i := range formats {
+		if formats[i].name == name {
+			return &formats[i]
+		}
+	}
+	return nil
+}
+
+// Scan 在 r 中查找文件系统，结果按偏移排序且互不重叠
+func Scan(r io.ReaderAt, size int64) ([]Image, error) {
+	var images []Image
+	var end int64 // 已识别的文件系统的结束位置
+	err := scanMagic(r, size, func(c Image) bool {
+		if c.Offset < end {
+			return true
+		}
+		if c.Size = formatOf(c.Type).probe(r, c.Offset, size); c.Size > 0 {
+			images = append(images, c)
+			end = c.Offset + c.Size
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// Detect 在 r 的前 limit 字节中查找文件系统头，返回第一个文件系统的类型，未找到时返回空
+//
+// 仅校验文件系统头，不保证文件系统完整，用于快速识别上传的文件是否为固件镜像
+func Detect(r io.ReaderAt, size, limit int64) (string, error) {
+	if limit > size {
+		limit = size
+	}
+	var found string
+	err := scanMagic(r, limit, func(c Image) bool {
+		if formatOf(c.Type).header(r, c.Offset, size) {
+			found = c.Type
+		}
+		return found == ""
+	})
+	return found, err
+}
+
+// scanMagic 按块查找文件系统的魔数，按偏移依次处理候选位置，fn 返回 false 时停止查找
+func scanMagic(r io.ReaderAt, size int64, fn func(c Image) bool) error {
+	var overlap int
+	for _, f := range formats {
+		for _, m := range f.magic {
+			if len(m) > overlap {
+				overlap = len(m)
+			}
+		}
+	}
+
+	buf := make([]byte, scanChunkSize+overlap)
+	for base := int64(0); base < size; base += scanChunkSize {
+		n, err := r.ReadAt(buf, base)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		chunk := buf[:n]
+
+		var candidates []Image
+		for i := range formats {
+			for _, m := range formats[i].magic {
+				for pos := 0; ; {
+					idx := bytes.Index(chunk[pos:], m)
+					if idx < 0 || pos+idx >= scanChunkSize || base+int64(pos+idx) >= size {
+						break
+					}
+					pos += idx
+					candidates = append(candidates, Image{Type: formats[i].name, Offset: base + int64(pos)})
+					pos++
+				}
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Offset < candidates[j].Offset
+		})
+
+		for _, c := range candidates {
+			if !fn(c) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Walk 遍历 img 中的文件
+func Walk(r io.ReaderAt, img Image, fn WalkFunc) error {
+	f := formatOf(img.Type)
+	if f == nil {
+		return fmt.Errorf("%w: %s", ErrUnsupported, img.Type)
+	}
+	return f.walk(io.NewSectionReader(r, img.Offset, img.Size), img.Size, fn)
+}
+
+// readAt 读取 off 处的 n 个字节，不足时返回错误
+func readAt(r io.ReaderAt, off int64, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, off); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// tree 由 inode 及目录项还原目录树，用于日志结构的文件系统(jffs2, ubifs)
+type tree struct {
+	children map[uint64][]dirent // 父目录 inode -> 目录项
+}
+
+type dirent struct {
+	name string
+	ino  uint64
+}
+
+// walk 从 root 开始深度优先遍历，visit 返回 false 时不进入该目录
+func (t *tree) walk(root uint64, visit func(name string, ino uint64) (bool, error)) error {
+	seen := map[uint64]bool{root: true}
+	var walkDir func(dir uint64, prefix string) error
+	walkDir = func(dir uint64, prefix string) error {
+		entries := t.children[dir]
+		sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+		for _, e := range entries {
+			name := e.name
+			if prefix != "" {
+				name = prefix + "/" + e.name
+			}
+			descend, err := visit(name, e.ino)
+			if err != nil {
+				return err
+			}
+			// 硬链接的目录或损坏的镜像可能形成环
+			if descend && !seen[e.ino] {
+				seen[e.ino] = true
+				if err = walkDir(e.ino, name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return walkDir(root, "")
+}
+
+// validName 目录项名称不能包含路径分隔符
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && len(name) <= maxNameLen && !bytes.ContainsAny([]byte(name), "/\x00")
+}
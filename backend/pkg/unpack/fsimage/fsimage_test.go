@@ -0,0 +1,536 @@
+package fsimage_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bin-vul-inspector/pkg/unpack/fsimage"
+)
+
+var elf = append([]byte("\x7fELF\x02\x01\x01"), bytes.Repeat([]byte{0x90}, 5000)...)
+
+// walkAll 返回镜像中的全部文件，目录为 dir/，符号链接为 -> 目标
+func walkAll(t *testing.T, data []byte, img fsimage.Image) map[string]string {
+	files := map[string]string{}
+	err := fsimage.Walk(bytes.NewReader(data), img, func(e *fsimage.Entry) error {
+		switch {
+		case e.Mode.IsDir():
+			files[e.Name] = "dir/"
+		case e.Mode&fs.ModeSymlink != 0:
+			files[e.Name] = "-> " + e.Link
+		case e.Mode.IsRegular():
+			r, err := e.Open()
+			if err != nil {
+				return err
+			}
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			assert.Equal(t, e.Size, int64(len(b)), e.Name)
+			files[e.Name] = content(b)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+// content 较长的文件内容以摘要表示
+func content(b []byte) string {
+	if len(b) > 64 {
+		return fmt.Sprintf("%d bytes, md5 %x", len(b), md5.Sum(b))
+	}
+	return string(b)
+}
+
+func scanOne(t *testing.T, data []byte) fsimage.Image {
+	images, err := fsimage.Scan(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	return images[0]
+}
+
+func cpioNewc(files [][3]string) []byte {
+	var buf bytes.Buffer
+	pad := func() {
+		for buf.Len()%4 != 0 {
+			buf.WriteByte(0)
+		}
+	}
+	write := func(ino int, mode uint32, name, data string) {
+		fmt.Fprintf(&buf, "070701%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+			ino, mode, 0, 0, 1, 0, len(data), 0, 0, 0, 0, len(name)+1, 0)
+		buf.WriteString(name + "\x00")
+		pad()
+		buf.WriteString(data)
+		pad()
+	}
+	for i, f := range files {
+		var mode uint32
+		switch f[1] {
+		case "dir":
+			mode = 0o040755
+		case "link":
+			mode = 0o120777
+		default:
+			mode = 0o100755
+		}
+		write(i+1, mode, f[0], f[2])
+	}
+	write(0, 0, "TRAILER!!!", "")
+	return buf.Bytes()
+}
+
+func TestScan_CPIO(t *testing.T) {
+	image := cpioNewc([][3]string{
+		{".", "dir", ""},
+		{"bin", "dir", ""},
+		{"bin/busybox", "file", string(elf)},
+		{"bin/sh", "link", "busybox"},
+		{"../evil", "file", "x"},
+	})
+	// 文件系统前后为内核等其他数据
+	data := append(bytes.Repeat([]byte{0xAA}, 0x1234), image...)
+	data = append(data, bytes.Repeat([]byte{0xBB}, 100)...)
+
+	img := scanOne(t, data)
+	assert.Equal(t, fsimage.Image{Type: fsimage.CPIO, Offset: 0x1234, Size: int64(len(image))}, img)
+	assert.Equal(t, "cpio@0x1234", img.Name())
+	assert.Equal(t, map[string]string{
+		"bin":         "dir/",
+		"bin/busybox": content(elf),
+		"bin/sh":      "-> busybox",
+	}, walkAll(t, data, img))
+}
+
+// squashfsImage 构造一个 squashfs v4 镜像：/bin/busybox(两个数据块，第一个 zlib 压缩)、/bin/sh -> busybox、/etc/passwd(分片)
+func squashfsImage() []byte {
+	const blockSize = 4096
+	le := binary.LittleEndian
+	var img bytes.Buffer
+	img.Write(make([]byte, 96))
+
+	// 数据块
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	_, _ = zw.Write(elf[:blockSize])
+	_ = zw.Close()
+	blocksStart := img.Len()
+	img.Write(z.Bytes())
+	img.Write(elf[blockSize:])
+	blockSizes := []uint32{uint32(z.Len()), uint32(len(elf)-blockSize) | 1<<24}
+
+	passwd := "root:x:0:0::/root:/bin/sh\n"
+	fragStart := img.Len()
+	img.WriteString(passwd)
+
+	meta := func(data []byte) []byte {
+		return append(le.AppendUint16(nil, uint16(len(data))|0x8000), data...)
+	}
+	header := func(typ uint16, mode uint16, number uint32) []byte {
+		b := le.AppendUint16(nil, typ)
+		b = le.AppendUint16(b, mode)
+		b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+		return le.AppendUint32(b, number)
+	}
+
+	// 目录表：/ 包含 bin、etc，/bin 包含 busybox、sh
+	type dent struct {
+		name   string
+		offset uint16
+		number uint32
+		typ    uint16
+	}
+	listing := func(entries []dent) []byte {
+		b := le.AppendUint32(nil, uint32(len(entries)-1))
+		b = le.AppendUint32(b, 0)
+		b = le.AppendUint32(b, entries[0].number)
+		for _, e := range entries {
+			b = le.AppendUint16(b, e.offset)
+			b = le.AppendUint16(b, uint16(e.number-entries[0].number))
+			b = le.AppendUint16(b, e.typ)
+			b = le.AppendUint16(b, uint16(len(e.name)-1))
+			b = append(b, e.name...)
+		}
+		return b
+	}
+
+	// inode 表：root(1) bin(2) etc(3) busybox(4) sh(5) passwd(6)
+	var inodes []byte
+	offsets := map[uint32]uint16{}
+	file := header(2, 0o755, 4)
+	file = le.AppendUint32(file, uint32(blocksStart))
+	file = le.AppendUint32(file, 0xffffffff)
+	file = le.AppendUint32(file, 0)
+	file = le.AppendUint32(file, uint32(len(elf)))
+	for _, s := range blockSizes {
+		file = le.AppendUint32(file, s)
+	}
+	link := header(3, 0o777, 5)
+	link = le.AppendUint32(link, 1)
+	link = le.AppendUint32(link, uint32(len("busybox")))
+	link = append(link, "busybox"...)
+	frag := header(2, 0o644, 6)
+	frag = le.AppendUint32(frag, 0)
+	frag = le.AppendUint32(frag, 0)
+	frag = le.AppendUint32(frag, 0)
+	frag = le.AppendUint32(frag, uint32(len(passwd)))
+
+	// 目录 inode 的大小依赖于 inode 的偏移，先确定文件 inode 的位置
+	dirInodeSize := 32
+	offsets[4] = uint16(3 * dirInodeSize)
+	offsets[5] = offsets[4] + uint16(len(file))
+	offsets[6] = offsets[5] + uint16(len(link))
+	binList := listing([]dent{{"busybox", offsets[4], 4, 2}, {"sh", offsets[5], 5, 3}})
+	etcList := listing([]dent{{"passwd", offsets[6], 6, 2}})
+	offsets[2], offsets[3] = uint16(dirInodeSize), uint16(2*dirInodeSize)
+	rootList := listing([]dent{{"bin", offsets[2], 2, 1}, {"etc", offsets[3], 3, 1}})
+	dirs := append(append(append([]byte(nil), rootList...), binList...), etcList...)
+
+	dir := func(number uint32, offset, size int) []byte {
+		b := header(1, 0o755, number)
+		b = le.AppendUint32(b, 0)
+		b = le.AppendUint32(b, 2)
+		b = le.AppendUint16(b, uint16(size+3))
+		b = le.AppendUint16(b, uint16(offset))
+		return le.AppendUint32(b, 1)
+	}
+	inodes = append(inodes, dir(1, 0, len(rootList))...)
+	inodes = append(inodes, dir(2, len(rootList), len(binList))...)
+	inodes = append(inodes, dir(3, len(rootList)+len(binList), len(etcList))...)
+	inodes = append(append(append(inodes, file...), link...), frag...)
+
+	inodeTable := img.Len()
+	img.Write(meta(inodes))
+	dirTable := img.Len()
+	img.Write(meta(dirs))
+	fragMeta := img.Len()
+	entry := le.AppendUint64(nil, uint64(fragStart))
+	entry = le.AppendUint32(entry, uint32(len(passwd))|1<<24)
+	entry = le.AppendUint32(entry, 0)
+	img.Write(meta(entry))
+	fragTable := img.Len()
+	img.Write(le.AppendUint64(nil, uint64(fragMeta)))
+
+	b := img.Bytes()
+	copy(b, "hsqs")
+	le.PutUint32(b[4:], 6)
+	le.PutUint32(b[12:], blockSize)
+	le.PutUint32(b[16:], 1)
+	le.PutUint16(b[20:], 1)
+	le.PutUint16(b[22:], 12)
+	le.PutUint16(b[28:], 4)
+	le.PutUint64(b[32:], 0)
+	le.PutUint64(b[40:], uint64(len(b)))
+	le.PutUint64(b[48:], uint64(len(b)))
+	le.PutUint64(b[56:], 0xffffffffffffffff)
+	le.PutUint64(b[64:], uint64(inodeTable))
+	le.PutUint64(b[72:], uint64(dirTable))
+	le.PutUint64(b[80:], uint64(fragTable))
+	le.PutUint64(b[88:], 0xffffffffffffffff)
+	return b
+}
+
+func TestScan_SquashFS(t *testing.T) {
+	image := squashfsImage()
+	data := append(bytes.Repeat([]byte{0xAA}, 0x40000), image...)
+
+	img := scanOne(t, data)
+	assert.Equal(t, fsimage.Image{Type: fsimage.SquashFS, Offset: 0x40000, Size: int64(len(image))}, img)
+	assert.Equal(t, map[string]string{
+		"bin":         "dir/",
+		"bin/busybox": content(elf),
+		"bin/sh":      "-> busybox",
+		"etc":         "dir/",
+		"etc/passwd":  "root:x:0:0::/root:/bin/sh\n",
+	}, walkAll(t, data, img))
+}
+
+func TestDetect(t *testing.T) {
+	image := squashfsImage()
+	data := append(bytes.Repeat([]byte{0xAA}, 0x40000), image...)
+
+	typ, err := fsimage.Detect(bytes.NewReader(data), int64(len(data)), 1<<20)
+	require.NoError(t, err)
+	assert.Equal(t, fsimage.SquashFS, typ)
+
+	// 文件系统位于查找范围之外
+	typ, err = fsimage.Detect(bytes.NewReader(data), int64(len(data)), 0x40000)
+	require.NoError(t, err)
+	assert.Empty(t, typ)
+
+	// 不包含文件系统
+	typ, err = fsimage.Detect(bytes.NewReader(elf), int64(len(elf)), 1<<20)
+	require.NoError(t, err)
+	assert.Empty(t, typ)
+}
+
+func jffs2CRC(b []byte) uint32 {
+	return ^crc32.Update(0xffffffff, crc32.IEEETable, b)
+}
+
+func jffs2Node(typ uint16, body []byte) []byte {
+	le := binary.LittleEndian
+	b := le.AppendUint16(nil, 0x1985)
+	b = le.AppendUint16(b, typ)
+	b = le.AppendUint32(b, uint32(12+len(body)))
+	b = le.AppendUint32(b, jffs2CRC(b))
+	b = append(b, body...)
+	for len(b)%4 != 0 {
+		b = append(b, 0xff)
+	}
+	return b
+}
+
+func jffs2Dirent(pino, version, ino uint32, name string) []byte {
+	le := binary.LittleEndian
+	hdr := le.AppendUint16(nil, 0x1985)
+	hdr = le.AppendUint16(hdr, 0xE001)
+	hdr = le.AppendUint32(hdr, uint32(40+len(name)))
+	hdr = le.AppendUint32(hdr, jffs2CRC(hdr))
+	hdr = le.AppendUint32(hdr, pino)
+	hdr = le.AppendUint32(hdr, version)
+	hdr = le.AppendUint32(hdr, ino)
+	hdr = le.AppendUint32(hdr, 0)
+	hdr = append(hdr, byte(len(name)), 0, 0, 0)
+	hdr = le.AppendUint32(hdr, jffs2CRC(hdr))
+	hdr = le.AppendUint32(hdr, jffs2CRC([]byte(name)))
+	b := append(hdr, name...)
+	for len(b)%4 != 0 {
+		b = append(b, 0xff)
+	}
+	return b
+}
+
+func jffs2Inode(ino, version, mode, isize, offset uint32, compr byte, dsize uint32, data []byte) []byte {
+	le := binary.LittleEndian
+	hdr := le.AppendUint16(nil, 0x1985)
+	hdr = le.AppendUint16(hdr, 0xE002)
+	hdr = le.AppendUint32(hdr, uint32(68+len(data)))
+	hdr = le.AppendUint32(hdr, jffs2CRC(hdr))
+	hdr = le.AppendUint32(hdr, ino)
+	hdr = le.AppendUint32(hdr, version)
+	hdr = le.AppendUint32(hdr, mode)
+	hdr = append(hdr, 0, 0, 0, 0)
+	hdr = le.AppendUint32(hdr, isize)
+	hdr = append(hdr, make([]byte, 12)...)
+	hdr = le.AppendUint32(hdr, offset)
+	hdr = le.AppendUint32(hdr, uint32(len(data)))
+	hdr = le.AppendUint32(hdr, dsize)
+	hdr = append(hdr, compr, 0, 0, 0)
+	hdr = le.AppendUint32(hdr, jffs2CRC(data))
+	hdr = le.AppendUint32(hdr, jffs2CRC(hdr[:60]))
+	b := append(hdr, data...)
+	for len(b)%4 != 0 {
+		b = append(b, 0xff)
+	}
+	return b
+}
+
+func TestScan_JFFS2(t *testing.T) {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	_, _ = zw.Write(elf[:4096])
+	_ = zw.Close()
+
+	var image []byte
+	image = append(image, jffs2Node(0x2003, nil)...) // cleanmarker
+	image = append(image, jffs2Dirent(1, 1, 2, "bin")...)
+	image = append(image, jffs2Inode(2, 1, 0o040755, 0, 0, 0, 0, nil)...)
+	image = append(image, jffs2Dirent(2, 2, 3, "busybox")...)
+	image = append(image, jffs2Inode(3, 1, 0o100755, uint32(len(elf)), 0, 6, 4096, z.Bytes())...)
+	image = append(image, jffs2Inode(3, 2, 0o100755, uint32(len(elf)), 4096, 0, uint32(len(elf)-4096), elf[4096:])...)
+	image = append(image, jffs2Dirent(2, 3, 4, "sh")...)
+	image = append(image, jffs2Inode(4, 1, 0o120777, 7, 0, 0, 7, []byte("busybox"))...)
+	// lzo 压缩的 "abcabc" 及 rtime 压缩的 "aaaab"
+	image = append(image, jffs2Dirent(1, 4, 5, "lzo")...)
+	image = append(image, jffs2Inode(5, 1, 0o100644, 6, 0, 7, 6, []byte{20, 'a', 'b', 'c', 72, 0, 0x11, 0, 0})...)
+	image = append(image, jffs2Dirent(1, 5, 6, "rtime")...)
+	image = append(image, jffs2Inode(6, 1, 0o100644, 5, 0, 2, 5, []byte{'a', 3, 'b', 0})...)
+	// 已删除的文件
+	image = append(image, jffs2Dirent(1, 6, 7, "deleted")...)
+	image = append(image, jffs2Inode(7, 1, 0o100644, 1, 0, 0, 1, []byte("x"))...)
+	image = append(image, jffs2Dirent(1, 7, 0, "deleted")...)
+	// 擦除块剩余部分
+	size := len(image)
+	image = append(image, bytes.Repeat([]byte{0xff}, 0x10000-len(image))...)
+
+	data := append(bytes.Repeat([]byte{0x00}, 0x20000), image...)
+	data = append(data, []byte("trailing data")...)
+	img := scanOne(t, data)
+	assert.Equal(t, fsimage.Image{Type: fsimage.JFFS2, Offset: 0x20000, Size: int64(size)}, img)
+	assert.Equal(t, map[string]string{
+		"bin":         "dir/",
+		"bin/busybox": content(elf),
+		"bin/sh":      "-> busybox",
+		"lzo":         "abcabc",
+		"rtime":       "aaaab",
+	}, walkAll(t, data, img))
+}
+
+func ubiCRC(b []byte) uint32 {
+	return ^crc32.ChecksumIEEE(b)
+}
+
+// ubifsImage 构造一个包含 /bin/busybox 的 ubifs，LEB 大小为 lebSize
+func ubifsImage(lebSize int) []byte {
+	le := binary.LittleEndian
+	var sqnum uint64
+	node := func(typ byte, body []byte) []byte {
+		sqnum++
+		b := le.AppendUint32(nil, 0x06101831)
+		b = le.AppendUint32(b, 0)
+		b = le.AppendUint64(b, sqnum)
+		b = le.AppendUint32(b, uint32(24+len(body)))
+		b = append(b, typ, 0, 0, 0)
+		b = append(b, body...)
+		le.PutUint32(b[4:], ubiCRC(b[8:]))
+		for len(b)%8 != 0 {
+			b = append(b, 0)
+		}
+		return b
+	}
+	key := func(inum uint32, typ uint32, v uint32) []byte {
+		b := le.AppendUint32(nil, inum)
+		b = le.AppendUint32(b, typ<<29|v)
+		return append(b, make([]byte, 8)...)
+	}
+	ino := func(inum uint32, mode uint32, size uint64, data []byte) []byte {
+		b := key(inum, 0, 0)
+		b = append(b, make([]byte, 8)...)
+		b = le.AppendUint64(b, size)
+		b = append(b, make([]byte, 48)...)
+		b = le.AppendUint32(b, mode)
+		b = le.AppendUint32(b, 0)
+		b = le.AppendUint32(b, uint32(len(data)))
+		b = append(b, make([]byte, 44)...)
+		return node(0, append(b, data...))
+	}
+	dent := func(parent, inum uint32, name string) []byte {
+		b := key(parent, 2, 0x1234)
+		b = le.AppendUint64(b, uint64(inum))
+		b = append(b, 0, 0)
+		b = le.AppendUint16(b, uint16(len(name)))
+		b = append(b, 0, 0, 0, 0)
+		return node(2, append(append(b, name...), 0))
+	}
+	block := func(inum, n uint32, data []byte) []byte {
+		b := key(inum, 1, n)
+		b = le.AppendUint32(b, uint32(len(data)))
+		b = append(b, 0, 0, 0, 0)
+		return node(1, append(b, data...))
+	}
+
+	sb := make([]byte, 0, 64)
+	sb = append(sb, make([]byte, 12)...)
+	sb = le.AppendUint32(sb, uint32(lebSize))
+	sb = le.AppendUint32(sb, 64)
+	sb = append(sb, make([]byte, 40)...)
+
+	leb := func(nodes ...[]byte) []byte {
+		b := bytes.Join(nodes, nil)
+		return append(b, bytes.Repeat([]byte{0xff}, lebSize-len(b))...)
+	}
+	return bytes.Join([][]byte{
+		leb(node(6, sb)),
+		leb(
+			ino(1, 0o040755, 0, nil),
+			dent(1, 2, "bin"),
+			ino(2, 0o040755, 0, nil),
+			dent(2, 3, "busybox"),
+			ino(3, 0o100755, uint64(len(elf)), nil),
+			block(3, 0, elf[:4096]),
+			block(3, 1, elf[4096:]),
+		),
+	}, nil)
+}
+
+// ubiImage 将 volume 写入名为 rootfs 的卷
+func ubiImage(volume []byte, peb, lebSize int) []byte {
+	be := binary.BigEndian
+	dataOffset := peb - lebSize
+	ec := func() []byte {
+		b := append([]byte("UBI#"), 1, 0, 0, 0)
+		b = append(b, make([]byte, 8)...)
+		b = be.AppendUint32(b, 64)
+		b = be.AppendUint32(b, uint32(dataOffset))
+		b = append(b, make([]byte, 36)...)
+		return be.AppendUint32(b, ubiCRC(b))
+	}
+	vid := func(volID, lnum uint32, sqnum uint64) []byte {
+		b := append([]byte("UBI!"), 1, 1, 0, 0)
+		b = be.AppendUint32(b, volID)
+		b = be.AppendUint32(b, lnum)
+		b = append(b, make([]byte, 24)...)
+		b = be.AppendUint64(b, sqnum)
+		b = append(b, make([]byte, 12)...)
+		return be.AppendUint32(b, ubiCRC(b))
+	}
+	block := func(volID, lnum uint32, sqnum uint64, data []byte) []byte {
+		b := append(ec(), vid(volID, lnum, sqnum)...)
+		b = append(b, bytes.Repeat([]byte{0xff}, dataOffset-len(b))...)
+		b = append(b, data...)
+		return append(b, bytes.Repeat([]byte{0xff}, peb-len(b))...)
+	}
+
+	record := make([]byte, 172)
+	be.PutUint32(record, 2)
+	be.PutUint16(record[14:], 6)
+	copy(record[16:], "rootfs")
+	be.PutUint32(record[168:], ubiCRC(record[:168]))
+	table := record
+	for i := 1; i < 128; i++ {
+		empty := make([]byte, 172)
+		be.PutUint32(empty[168:], ubiCRC(empty[:168]))
+		table = append(table, empty...)
+	}
+
+	var img []byte
+	img = append(img, block(0x7FFFEFFF, 0, 1, table)...)
+	for i := 0; i < len(volume)/lebSize; i++ {
+		img = append(img, block(0, uint32(i), uint64(i+2), volume[i*lebSize:(i+1)*lebSize])...)
+	}
+	// 旧版本的 LEB 0
+	img = append(img, block(0, 0, 0, bytes.Repeat([]byte{0}, lebSize))...)
+	return img
+}
+
+func TestScan_UBI(t *testing.T) {
+	const peb, lebSize = 0x8000, 0x8000 - 0x800
+	volume := ubifsImage(lebSize)
+	image := ubiImage(volume, peb, lebSize)
+	data := append(bytes.Repeat([]byte{0xAA}, 0x100), image...)
+
+	img := scanOne(t, data)
+	assert.Equal(t, fsimage.Image{Type: fsimage.UBI, Offset: 0x100, Size: int64(len(image))}, img)
+	files := walkAll(t, data, img)
+	require.Equal(t, []string{"rootfs"}, keys(files))
+	assert.Equal(t, content(volume), files["rootfs"])
+
+	// 卷中的 ubifs 由上层继续解包
+	img = scanOne(t, volume)
+	assert.Equal(t, fsimage.Image{Type: fsimage.UBIFS, Offset: 0, Size: int64(len(volume))}, img)
+	assert.Equal(t, map[string]string{
+		"bin":         "dir/",
+		"bin/busybox": content(elf),
+	}, walkAll(t, volume, img))
+}
+
+func keys(m map[string]string) []string {
+	var s []string
+	for k := range m {
+		s = append(s, k)
+	}
+	return s
+}
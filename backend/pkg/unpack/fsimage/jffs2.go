@@ -0,0 +1,363 @@
+package fsimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+const (
+	jffs2Magic         = 0x1985
+	jffs2HeaderSize    = 12
+	jffs2DirentSize    = 40
+	jffs2InodeSize     = 68
+	jffs2NodeDirent    = 0xE001
+	jffs2NodeInode     = 0xE002
+	jffs2RootIno       = 1
+	jffs2ResyncLimit   = 128 << 10 // 遇到损坏节点时向后查找的范围(擦除块大小)
+	jffs2WindowSize    = 64 << 10
+	jffs2MaxInodeSize  = 1 << 31
+	jffs2ComprNone     = 0x00
+	jffs2ComprZero     = 0x01
+	jffs2ComprRtime    = 0x02
+	jffs2ComprZlib     = 0x06
+	jffs2ComprLZO      = 0x07
+	jffs2ComprLZMA     = 0x08
+	jffs2LZMADictSize  = 0x2000
+	jffs2LZMAPropsByte = 0 // lc=0, lp=0, pb=0
+)
+
+// jffs2Node 节点的位置
+type jffs2Node struct {
+	off  int64
+	typ  uint16
+	size int64
+}
+
+// window 按块缓存读取，避免逐个节点读取时的小 IO
+type window struct {
+	r    io.ReaderAt
+	size int64
+	base int64
+	buf  []byte
+}
+
+func (w *window) bytes(off int64, n int) ([]byte, error) {
+	if off < 0 || off+int64(n) > w.size {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if off < w.base || off+int64(n) > w.base+int64(len(w.buf)) {
+		l := int64(jffs2WindowSize)
+		if int64(n) > l {
+			l = int64(n)
+		}
+		if off+l > w.size {
+			l = w.size - off
+		}
+		buf, err := readAt(w.r, off, int(l))
+		if err != nil {
+			return nil, err
+		}
+		w.base, w.buf = off, buf
+	}
+	return w.buf[off-w.base : off-w.base+int64(n)], nil
+}
+
+func jffs2CRC(b []byte) uint32 {
+	// linux crc32(0, ...) 不做首尾取反
+	return ^crc32.Update(0xffffffff, crc32.IEEETable, b)
+}
+
+// jffs2ByteOrder 由 off 处的魔数判断字节序
+func jffs2ByteOrder(w *window, off int64) binary.ByteOrder {
+	b, err := w.bytes(off, 2)
+	if err != nil {
+		return nil
+	}
+	switch {
+	case binary.LittleEndian.Uint16(b) == jffs2Magic:
+		return binary.LittleEndian
+	case binary.BigEndian.Uint16(b) == jffs2Magic:
+		return binary.BigEndian
+	}
+	return nil
+}
+
+// jffs2Header 校验 off 处的节点头，返回节点
+func jffs2Header(w *window, order binary.ByteOrder, off int64) (jffs2Node, bool) {
+	b, err := w.bytes(off, jffs2HeaderSize)
+	if err != nil || order.Uint16(b) != jffs2Magic {
+		return jffs2Node{}, false
+	}
+	n := jffs2Node{off: off, typ: order.Uint16(b[2:]), size: int64(order.Uint32(b[4:]))}
+	if n.size < jffs2HeaderSize || off+n.size > w.size || order.Uint32(b[8:]) != jffs2CRC(b[:8]) {
+		return jffs2Node{}, false
+	}
+	return n, true
+}
+
+// jffs2Scan 从 off 开始遍历节点，跳过擦除后的填充及损坏的数据，返回最后一个有效节点的结束位置
+func jffs2Scan(w *window, order binary.ByteOrder, off int64, fn func(n jffs2Node) error) (int64, error) {
+	end := off
+	for pos := off; pos+jffs2HeaderSize <= w.size; {
+		if n, ok := jffs2Header(w, order, pos); ok {
+			if fn != nil {
+				if err := fn(n); err != nil {
+					return 0, err
+				}
+			}
+			pos = alignUp(pos+n.size, 4)
+			end = pos
+			continue
+		}
+		b, err := w.bytes(pos, 4)
+		if err != nil {
+			return 0, err
+		}
+		if v := binary.LittleEndian.Uint32(b); v == 0xffffffff || v == 0 {
+			pos += 4
+			continue
+		}
+		if pos-end > jffs2ResyncLimit {
+			break
+		}
+		pos += 4
+	}
+	return end, nil
+}
+
+func headerJFFS2(r io.ReaderAt, off, size int64) bool {
+	w := &window{r: r, size: size}
+	order := jffs2ByteOrder(w, off)
+	if order == nil {
+		return false
+	}
+	_, ok := jffs2Header(w, order, off)
+	return ok
+}
+
+func probeJFFS2(r io.ReaderAt, off, size int64) int64 {
+	w := &window{r: r, size: size}
+	order := jffs2ByteOrder(w, off)
+	if order == nil {
+		return 0
+	}
+	if _, ok := jffs2Header(w, order, off); !ok {
+		return 0
+	}
+	end, err := jffs2Scan(w, order, off, nil)
+	if err != nil {
+		return 0
+	}
+	return end - off
+}
+
+type jffs2Inode struct {
+	version uint32
+	mode    uint32
+	isize   uint32
+	data    []jffs2Data
+}
+
+// jffs2Data 文件内容节点
+type jffs2Data struct {
+	version uint32
+	off     int64 // 压缩数据在镜像中的偏移
+	csize   uint32
+	dsize   uint32
+	offset  uint32 // 在文件中的偏移
+	compr   byte
+}
+
+type jffs2Dirent struct {
+	version uint32
+	ino     uint32
+}
+
+func walkJFFS2(r io.ReaderAt, size int64, fn WalkFunc) error {
+	w := &window{r: r, size: size}
+	order := jffs2ByteOrder(w, 0)
+	if order == nil {
+		return fmt.Errorf("jffs2: bad magic")
+	}
+
+	inodes := map[uint32]*jffs2Inode{}
+	type direntKey struct {
+		pino uint32
+		name string
+	}
+	dirents := map[direntKey]jffs2Dirent{}
+
+	_, err := jffs2Scan(w, order, 0, func(n jffs2Node) error {
+		switch n.typ {
+		case jffs2NodeDirent:
+			if n.size < jffs2DirentSize {
+				return nil
+			}
+			b, err := w.bytes(n.off, int(n.size))
+			if err != nil {
+				return err
+			}
+			if order.Uint32(b[32:]) != jffs2CRC(b[:32]) {
+				return nil
+			}
+			nsize := int(b[28])
+			if jffs2DirentSize+nsize > len(b) {
+				return nil
+			}
+			key := direntKey{pino: order.Uint32(b[12:]), name: string(b[jffs2DirentSize : jffs2DirentSize+nsize])}
+			d := jffs2Dirent{version: order.Uint32(b[16:]), ino: order.Uint32(b[20:])}
+			if old, ok := dirents[key]; !ok || d.version > old.version {
+				dirents[key] = d
+			}
+		case jffs2NodeInode:
+			if n.size < jffs2InodeSize {
+				return nil
+			}
+			b, err := w.bytes(n.off, jffs2InodeSize)
+			if err != nil {
+				return err
+			}
+			if order.Uint32(b[64:]) != jffs2CRC(b[:60]) {
+				return nil
+			}
+			ino := order.Uint32(b[12:])
+			d := jffs2Data{
+				version: order.Uint32(b[16:]),
+				off:     n.off + jffs2InodeSize,
+				csize:   order.Uint32(b[48:]),
+				dsize:   order.Uint32(b[52:]),
+				offset:  order.Uint32(b[44:]),
+				compr:   b[56],
+			}
+			if jffs2InodeSize+int64(d.csize) > n.size && d.compr != jffs2ComprZero {
+				return nil
+			}
+			in := inodes[ino]
+			if in == nil {
+				in = &jffs2Inode{}
+				inodes[ino] = in
+			}
+			if d.version >= in.version {
+				in.version, in.mode, in.isize = d.version, order.Uint32(b[20:]), order.Uint32(b[28:])
+			}
+			if d.dsize > 0 {
+				in.data = append(in.data, d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	t := &tree{children: map[uint64][]dirent{}}
+	for key, d := range dirents {
+		// ino 为 0 的目录项表示已删除
+		if d.ino == 0 || !validName(key.name) {
+			continue
+		}
+		t.children[uint64(key.pino)] = append(t.children[uint64(key.pino)], dirent{name: key.name, ino: uint64(d.ino)})
+	}
+
+	return t.walk(jffs2RootIno, func(name string, ino uint64) (bool, error) {
+		in := inodes[uint32(ino)]
+		if in == nil {
+			return false, nil
+		}
+		e := &Entry{Name: name, Mode: unixMode(in.mode)}
+		switch {
+		case e.Mode.IsRegular():
+			e.Size = int64(in.isize)
+			e.Open = func() (io.Reader, error) {
+				data, err := in.read(w)
+				if err != nil {
+					return nil, err
+				}
+				return bytes.NewReader(data), nil
+			}
+		case e.Mode&fs.ModeSymlink != 0 && in.isize <= maxNameLen:
+			// 符号链接的目标保存在数据节点中
+			data, err := in.read(w)
+			if err != nil {
+				return false, err
+			}
+			e.Link = string(data)
+		}
+		if err := fn(e); err != nil {
+			return false, err
+		}
+		return e.Mode.IsDir(), nil
+	})
+}
+
+// read 按版本顺序应用数据节点，还原文件内容
+func (in *jffs2Inode) read(w *window) ([]byte, error) {
+	if in.isize > jffs2MaxInodeSize {
+		return nil, fmt.Errorf("jffs2: inode too large (%d)", in.isize)
+	}
+	data := make([]byte, in.isize)
+	nodes := append([]jffs2Data(nil), in.data...)
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].version < nodes[j].version })
+	for _, n := range nodes {
+		if int64(n.offset) >= int64(len(data)) {
+			continue
+		}
+		var chunk []byte
+		if n.compr == jffs2ComprZero {
+			chunk = make([]byte, n.dsize)
+		} else {
+			src, err := w.bytes(n.off, int(n.csize))
+			if err != nil {
+				return nil, err
+			}
+			if chunk, err = jffs2Decompress(n.compr, src, int(n.dsize)); err != nil {
+				return nil, err
+			}
+		}
+		copy(data[n.offset:], chunk)
+	}
+	return data, nil
+}
+
+func jffs2Decompress(compr byte, src []byte, size int) ([]byte, error) {
+	switch compr {
+	case jffs2ComprNone:
+		return src, nil
+	case jffs2ComprRtime:
+		return rtimeDecompress(src, size)
+	case jffs2ComprZlib:
+		return decompress(compZlib, src, size)
+	case jffs2ComprLZO:
+		return lzo1xDecompress(src, size)
+	case jffs2ComprLZMA:
+		return decompress(compLZMA, rawLZMA(src, jffs2LZMAPropsByte, jffs2LZMADictSize, size), size)
+	default:
+		return nil, fmt.Errorf("%w: jffs2 compression %d", ErrUnsupported, compr)
+	}
+}
+
+// rtimeDecompress 解压 jffs2 的 rtime 数据
+func rtimeDecompress(src []byte, size int) ([]byte, error) {
+	var positions [256]int
+	out := make([]byte, 0, size)
+	for pos := 0; len(out) < size; {
+		if pos+2 > len(src) {
+			return nil, fmt.Errorf("jffs2: corrupt rtime data")
+		}
+		value, repeat := src[pos], int(src[pos+1])
+		pos += 2
+		out = append(out, value)
+		backoffs := positions[value]
+		positions[value] = len(out)
+		for ; repeat > 0 && len(out) < size; repeat-- {
+			out = append(out, out[backoffs])
+			backoffs++
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,160 @@
+package fsimage
+
+import (
+	"errors"
+)
+
+var errLZO = errors.New("lzo: corrupt input")
+
+// lzo1xDecompress 解压 LZO1X 数据块(squashfs, jffs2, ubifs 使用)，与 linux lzo1x_decompress_safe 一致
+func lzo1xDecompress(in []byte, outLen int) ([]byte, error) {
+	out := make([]byte, 0, outLen)
+	ip := 0
+	state := 0
+
+	readByte := func() (int, error) {
+		if ip >= len(in) {
+			return 0, errLZO
+		}
+		b := in[ip]
+		ip++
+		return int(b), nil
+	}
+	// 长度为 0 时后续的 0 字节每个代表 255
+	readLen := func(base int) (int, error) {
+		zeros := 0
+		for ip < len(in) && in[ip] == 0 {
+			ip++
+			zeros++
+		}
+		b, err := readByte()
+		if err != nil {
+			return 0, err
+		}
+		return base + zeros*255 + b, nil
+	}
+	copyLiterals := func(n int) error {
+		if ip+n > len(in) || len(out)+n > outLen {
+			return errLZO
+		}
+		out = append(out, in[ip:ip+n]...)
+		ip += n
+		return nil
+	}
+	copyMatch := func(dist, n int) error {
+		pos := len(out) - dist
+		if pos < 0 || len(out)+n > outLen {
+			return errLZO
+		}
+		// 匹配可能与输出重叠，逐字节复制
+		for i := 0; i < n; i++ {
+			out = append(out, out[pos+i])
+		}
+		return nil
+	}
+
+	if len(in) > 0 && in[0] > 17 {
+		t := int(in[0]) - 17
+		ip++
+		if err := copyLiterals(t); err != nil {
+			return nil, err
+		}
+		state = 4
+		if t < 4 {
+			state = t
+		}
+	}
+
+	for {
+		t, err := readByte()
+		if err != nil {
+			return nil, err
+		}
+
+		var dist, n, next int
+		switch {
+		case t < 16:
+			switch state {
+			case 0:
+				// 字面量
+				if t == 0 {
+					if t, err = readLen(15); err != nil {
+						return nil, err
+					}
+				}
+				if err = copyLiterals(t + 3); err != nil {
+					return nil, err
+				}
+				state = 4
+				continue
+			case 4:
+				b, err := readByte()
+				if err != nil {
+					return nil, err
+				}
+				dist = 1 + 0x0800 + (t >> 2) + (b << 2)
+				n = 3
+			default:
+				b, err := readByte()
+				if err != nil {
+					return nil, err
+				}
+				dist = 1 + (t >> 2) + (b << 2)
+				n = 2
+			}
+			next = t & 3
+		case t >= 64:
+			b, err := readByte()
+			if err != nil {
+				return nil, err
+			}
+			dist = 1 + ((t >> 2) & 7) + (b << 3)
+			n = (t >> 5) + 1
+			next = t & 3
+		case t >= 32:
+			n = (t & 31) + 2
+			if n == 2 {
+				if n, err = readLen(31 + 2); err != nil {
+					return nil, err
+				}
+			}
+			if ip+2 > len(in) {
+				return nil, errLZO
+			}
+			v := int(in[ip]) | int(in[ip+1])<<8
+			ip += 2
+			dist = 1 + (v >> 2)
+			next = v & 3
+		default:
+			n = (t & 7) + 2
+			if n == 2 {
+				if n, err = readLen(7 + 2); err != nil {
+					return nil, err
+				}
+			}
+			if ip+2 > len(in) {
+				return nil, errLZO
+			}
+			v := int(in[ip]) | int(in[ip+1])<<8
+			ip += 2
+			dist = ((t & 8) << 11) + (v >> 2)
+			next = v & 3
+			// 结束标记
+			if dist == 0 {
+				if n != 3 {
+					return nil, errLZO
+				}
+				return out, nil
+			}
+			dist += 0x4000
+		}
+
+		if err = copyMatch(dist, n); err != nil {
+			return nil, err
+		}
+		state = next
+		if err = copyLiterals(next); err != nil {
+			return nil, err
+		}
+	}
+}
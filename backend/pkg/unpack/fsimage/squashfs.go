@@ -0,0 +1,500 @@
+package fsimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+const (
+	squashfsSuperblockSize = 96
+	squashfsMetadataSize   = 8192
+	squashfsNoFragment     = 0xffffffff
+	squashfsUncompressed   = 1 << 24 // 数据块、分片未压缩标志
+)
+
+// squashfs inode 类型
+const (
+	sqDir = iota + 1
+	sqFile
+	sqSymlink
+	sqBlockDev
+	sqCharDev
+	sqFifo
+	sqSocket
+	sqLDir
+	sqLFile
+	sqLSymlink
+	sqLBlockDev
+	sqLCharDev
+	sqLFifo
+	sqLSocket
+)
+
+var squashfsCompressors = map[uint16]string{
+	1: compZlib,
+	2: compLZMA,
+	3: compLZO,
+	4: compXZ,
+	5: compLZ4,
+	6: compZstd,
+}
+
+type squashfsSuperblock struct {
+	Magic          uint32
+	Inodes         uint32
+	MkfsTime       uint32
+	BlockSize      uint32
+	Fragments      uint32
+	Compressor     uint16
+	BlockLog       uint16
+	Flags          uint16
+	NoIds          uint16
+	Major          uint16
+	Minor          uint16
+	RootInode      uint64
+	BytesUsed      uint64
+	IdTable        uint64
+	XattrTable     uint64
+	InodeTable     uint64
+	DirectoryTable uint64
+	FragmentTable  uint64
+	ExportTable    uint64
+}
+
+// headerSquashFS 仅读取超级块，与 probeSquashFS 相同
+func headerSquashFS(r io.ReaderAt, off, size int64) bool {
+	return probeSquashFS(r, off, size) > 0
+}
+
+// probeSquashFS 支持识别 v3、v4 及大端序，仅 v4 小端序可读取
+func probeSquashFS(r io.ReaderAt, off, size int64) int64 {
+	buf, err := readAt(r, off, squashfsSuperblockSize)
+	if err != nil {
+		return 0
+	}
+
+	order := binary.ByteOrder(binary.LittleEndian)
+	if string(buf[:4]) == "sqsh" {
+		order = binary.BigEndian
+	}
+
+	var bytesUsed uint64
+	switch order.Uint16(buf[28:]) {
+	case 4:
+		blockSize, blockLog := order.Uint32(buf[12:]), order.Uint16(buf[22:])
+		if blockLog < 12 || blockLog > 20 || blockSize != 1<<blockLog {
+			return 0
+		}
+		bytesUsed = order.Uint64(buf[40:])
+		if inodeTable := order.Uint64(buf[64:]); inodeTable >= bytesUsed {
+			return 0
+		}
+	case 3:
+		bytesUsed = order.Uint64(buf[63:])
+	default:
+		return 0
+	}
+	if bytesUsed <= squashfsSuperblockSize || int64(bytesUsed) > size-off {
+		return 0
+	}
+	return int64(bytesUsed)
+}
+
+type squashfs struct {
+	r    io.ReaderAt
+	size int64
+	sb   squashfsSuperblock
+	comp string
+	meta map[int64]*squashfsMeta // 已解压的元数据块
+
+	fragIndex uint32 // 最近读取的分片数据块，小文件通常连续位于同一分片中
+	fragData  []byte
+}
+
+type squashfsMeta struct {
+	data []byte
+	next int64 // 下一个元数据块的位置
+}
+
+func walkSquashFS(r io.ReaderAt, size int64, fn WalkFunc) error {
+	s := &squashfs{r: r, size: size, meta: make(map[int64]*squashfsMeta)}
+	buf, err := readAt(r, 0, squashfsSuperblockSize)
+	if err != nil {
+		return err
+	}
+	if err = binary.Read(bytes.NewReader(buf), binary.LittleEndian, &s.sb); err != nil {
+		return err
+	}
+	if string(buf[:4]) != "hsqs" || s.sb.Major != 4 {
+		return fmt.Errorf("%w: squashfs %d.%d", ErrUnsupported, s.sb.Major, s.sb.Minor)
+	}
+	var ok bool
+	if s.comp, ok = squashfsCompressors[s.sb.Compressor]; !ok {
+		return fmt.Errorf("%w: squashfs compressor %d", ErrUnsupported, s.sb.Compressor)
+	}
+
+	root, err := s.inode(s.sb.RootInode)
+	if err != nil {
+		return fmt.Errorf("squashfs root inode: %w", err)
+	}
+	return s.walkDir(root, "", map[uint32]bool{root.number: true}, fn)
+}
+
+// metaReader 顺序读取跨越多个元数据块的数据
+type metaReader struct {
+	s      *squashfs
+	block  int64
+	offset int
+}
+
+func (m *metaReader) read(n int) ([]byte, error) {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		meta, err := m.s.metadata(m.block)
+		if err != nil {
+			return nil, err
+		}
+		if m.offset >= len(meta.data) {
+			m.block, m.offset = meta.next, m.offset-len(meta.data)
+			continue
+		}
+		c := n - len(out)
+		if rest := len(meta.data) - m.offset; c > rest {
+			c = rest
+		}
+		out = append(out, meta.data[m.offset:m.offset+c]...)
+		m.offset += c
+	}
+	return out, nil
+}
+
+func (m *metaReader) uint32() (uint32, error) {
+	b, err := m.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (s *squashfs) metadata(pos int64) (*squashfsMeta, error) {
+	if meta, ok := s.meta[pos]; ok {
+		return meta, nil
+	}
+	if pos < 0 || pos+2 > s.size {
+		return nil, fmt.Errorf("squashfs metadata block 0x%x out of range", pos)
+	}
+
+	header, err := readAt(s.r, pos, 2)
+	if err != nil {
+		return nil, err
+	}
+	h := binary.LittleEndian.Uint16(header)
+	n := int(h & 0x7fff)
+	if n == 0 || pos+2+int64(n) > s.size {
+		return nil, fmt.Errorf("squashfs metadata block 0x%x corrupt", pos)
+	}
+	data, err := readAt(s.r, pos+2, n)
+	if err != nil {
+		return nil, err
+	}
+	if h&0x8000 == 0 {
+		if data, err = decompress(s.comp, data, squashfsMetadataSize); err != nil {
+			return nil, fmt.Errorf("squashfs metadata block 0x%x: %w", pos, err)
+		}
+	}
+
+	meta := &squashfsMeta{data: data, next: pos + 2 + int64(n)}
+	s.meta[pos] = meta
+	return meta, nil
+}
+
+type squashfsInode struct {
+	typ    uint16
+	mode   fs.FileMode
+	number uint32
+
+	// 目录
+	dirBlock  uint32
+	dirOffset uint16
+	dirSize   uint32
+
+	// 文件
+	blocksStart uint64
+	fileSize    uint64
+	fragment    uint32
+	fragOffset  uint32
+	blockSizes  []uint32
+
+	link string
+}
+
+func (s *squashfs) inode(ref uint64) (*squashfsInode, error) {
+	m := &metaReader{s: s, block: int64(s.sb.InodeTable + ref>>16), offset: int(ref & 0xffff)}
+	header, err := m.read(16)
+	if err != nil {
+		return nil, err
+	}
+
+	ino := &squashfsInode{
+		typ:    binary.LittleEndian.Uint16(header),
+		mode:   fs.FileMode(binary.LittleEndian.Uint16(header[2:]) & 0o777),
+		number: binary.LittleEndian.Uint32(header[12:]),
+	}
+
+	le := binary.LittleEndian
+	switch ino.typ {
+	case sqDir:
+		b, err := m.read(16)
+		if err != nil {
+			return nil, err
+		}
+		ino.mode |= fs.ModeDir
+		ino.dirBlock, ino.dirSize, ino.dirOffset = le.Uint32(b), uint32(le.Uint16(b[8:])), le.Uint16(b[10:])
+	case sqLDir:
+		b, err := m.read(24)
+		if err != nil {
+			return nil, err
+		}
+		ino.mode |= fs.ModeDir
+		ino.dirSize, ino.dirBlock, ino.dirOffset = le.Uint32(b[4:]), le.Uint32(b[8:]), le.Uint16(b[18:])
+	case sqFile:
+		b, err := m.read(16)
+		if err != nil {
+			return nil, err
+		}
+		ino.blocksStart, ino.fragment, ino.fragOffset, ino.fileSize = uint64(le.Uint32(b)), le.Uint32(b[4:]), le.Uint32(b[8:]), uint64(le.Uint32(b[12:]))
+	case sqLFile:
+		b, err := m.read(40)
+		if err != nil {
+			return nil, err
+		}
+		ino.blocksStart, ino.fileSize, ino.fragment, ino.fragOffset = le.Uint64(b), le.Uint64(b[8:]), le.Uint32(b[28:]), le.Uint32(b[32:])
+	case sqSymlink, sqLSymlink:
+		b, err := m.read(8)
+		if err != nil {
+			return nil, err
+		}
+		n := le.Uint32(b[4:])
+		if n > maxNameLen {
+			return nil, fmt.Errorf("squashfs symlink too long")
+		}
+		target, err := m.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		ino.mode |= fs.ModeSymlink
+		ino.link = string(target)
+	case sqBlockDev, sqLBlockDev:
+		ino.mode |= fs.ModeDevice
+	case sqCharDev, sqLCharDev:
+		ino.mode |= fs.ModeDevice | fs.ModeCharDevice
+	case sqFifo, sqLFifo:
+		ino.mode |= fs.ModeNamedPipe
+	case sqSocket, sqLSocket:
+		ino.mode |= fs.ModeSocket
+	default:
+		return nil, fmt.Errorf("squashfs unknown inode type %d", ino.typ)
+	}
+
+	if ino.typ == sqFile || ino.typ == sqLFile {
+		if int64(ino.fileSize) > s.size*1024 {
+			return nil, fmt.Errorf("squashfs file size %d corrupt", ino.fileSize)
+		}
+		bs := uint64(s.sb.BlockSize)
+		n := ino.fileSize / bs
+		if ino.fragment == squashfsNoFragment && ino.fileSize%bs != 0 {
+			n++
+		}
+		ino.blockSizes = make([]uint32, n)
+		for i := range ino.blockSizes {
+			if ino.blockSizes[i], err = m.uint32(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ino, nil
+}
+
+func (s *squashfs) walkDir(dir *squashfsInode, prefix string, seen map[uint32]bool, fn WalkFunc) error {
+	// 目录大小包含 . 及 .. 的 3 字节
+	if dir.dirSize <= 3 {
+		return nil
+	}
+	m := &metaReader{s: s, block: int64(s.sb.DirectoryTable) + int64(dir.dirBlock), offset: int(dir.dirOffset)}
+	data, err := m.read(int(dir.dirSize - 3))
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		name string
+		ref  uint64
+	}
+	var entries []entry
+	le := binary.LittleEndian
+	for pos := 0; pos+12 <= len(data); {
+		count, start := le.Uint32(data[pos:])+1, le.Uint32(data[pos+4:])
+		pos += 12
+		for i := uint32(0); i < count; i++ {
+			if pos+8 > len(data) {
+				return fmt.Errorf("squashfs directory entry corrupt")
+			}
+			offset, nameSize := le.Uint16(data[pos:]), int(le.Uint16(data[pos+6:]))+1
+			pos += 8
+			if pos+nameSize > len(data) {
+				return fmt.Errorf("squashfs directory entry corrupt")
+			}
+			entries = append(entries, entry{name: string(data[pos : pos+nameSize]), ref: uint64(start)<<16 | uint64(offset)})
+			pos += nameSize
+		}
+	}
+
+	for _, e := range entries {
+		if !validName(e.name) {
+			continue
+		}
+		ino, err := s.inode(e.ref)
+		if err != nil {
+			return fmt.Errorf("squashfs inode of %s: %w", e.name, err)
+		}
+
+		name := path.Join(prefix, e.name)
+		entry := &Entry{Name: name, Mode: ino.mode, Link: ino.link}
+		if ino.typ == sqFile || ino.typ == sqLFile {
+			entry.Size = int64(ino.fileSize)
+			ino := ino
+			entry.Open = func() (io.Reader, error) { return &squashfsFile{s: s, ino: ino}, nil }
+		}
+		if err = fn(entry); err != nil {
+			return err
+		}
+
+		if ino.mode.IsDir() && !seen[ino.number] {
+			seen[ino.number] = true
+			if err = s.walkDir(ino, name, seen, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fragment 读取分片所在的数据块
+func (s *squashfs) fragment(index uint32) ([]byte, error) {
+	if s.fragData != nil && s.fragIndex == index {
+		return s.fragData, nil
+	}
+	if index >= s.sb.Fragments {
+		return nil, fmt.Errorf("squashfs fragment %d out of range", index)
+	}
+	pos := uint64(index) * 16
+	ptr, err := readAt(s.r, int64(s.sb.FragmentTable+pos/squashfsMetadataSize*8), 8)
+	if err != nil {
+		return nil, err
+	}
+	m := &metaReader{s: s, block: int64(binary.LittleEndian.Uint64(ptr)), offset: int(pos % squashfsMetadataSize)}
+	b, err := m.read(16)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.block(binary.LittleEndian.Uint64(b), binary.LittleEndian.Uint32(b[8:]))
+	if err != nil {
+		return nil, err
+	}
+	s.fragIndex, s.fragData = index, data
+	return data, nil
+}
+
+// block 读取数据块
+func (s *squashfs) block(start uint64, size uint32) ([]byte, error) {
+	n := size &^ squashfsUncompressed
+	if int64(start)+int64(n) > s.size || n > s.sb.BlockSize*2 {
+		return nil, fmt.Errorf("squashfs data block 0x%x out of range", start)
+	}
+	data, err := readAt(s.r, int64(start), int(n))
+	if err != nil {
+		return nil, err
+	}
+	if size&squashfsUncompressed != 0 {
+		return data, nil
+	}
+	return decompress(s.comp, data, int(s.sb.BlockSize))
+}
+
+// squashfsFile 按数据块顺序读取文件内容
+type squashfsFile struct {
+	s     *squashfs
+	ino   *squashfsInode
+	index int    // 下一个数据块
+	pos   uint64 // 下一个数据块在磁盘中的位置
+	read  uint64 // 已读取的字节数
+	buf   []byte
+}
+
+func (f *squashfsFile) Read(p []byte) (int, error) {
+	for len(f.buf) == 0 {
+		if f.read >= f.ino.fileSize {
+			return 0, io.EOF
+		}
+		if err := f.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+func (f *squashfsFile) next() error {
+	bs := uint64(f.s.sb.BlockSize)
+	want := f.ino.fileSize - f.read
+	if want > bs {
+		want = bs
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case f.index < len(f.ino.blockSizes):
+		if f.index == 0 {
+			f.pos = f.ino.blocksStart
+		}
+		size := f.ino.blockSizes[f.index]
+		f.index++
+		if size == 0 {
+			// 稀疏块
+			data = make([]byte, want)
+			break
+		}
+		if data, err = f.s.block(f.pos, size); err != nil {
+			return err
+		}
+		f.pos += uint64(size &^ squashfsUncompressed)
+	case f.ino.fragment != squashfsNoFragment:
+		block, err := f.s.fragment(f.ino.fragment)
+		if err != nil {
+			return err
+		}
+		start := uint64(f.ino.fragOffset)
+		if start+want > uint64(len(block)) {
+			return fmt.Errorf("squashfs fragment corrupt")
+		}
+		data = block[start : start+want]
+	default:
+		return io.ErrUnexpectedEOF
+	}
+
+	if uint64(len(data)) > want {
+		data = data[:want]
+	}
+	if len(data) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	f.read += uint64(len(data))
+	f.buf = data
+	return nil
+}
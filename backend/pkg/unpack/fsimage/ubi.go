@@ -0,0 +1,229 @@
+package fsimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sort"
+)
+
+const (
+	ubiHeaderSize     = 64
+	ubiVolumeTableID  = 0x7FFFEFFF
+	ubiVolumeRecord   = 172
+	ubiMaxVolumes     = 128
+	ubiMinPEBSize     = 16 << 10
+	ubiMaxPEBSize     = 2 << 20
+	ubiVolumeNameSize = 128
+)
+
+var ubiVIDMagic = []byte("UBI!")
+
+func ubiCRC(b []byte) uint32 {
+	// linux crc32(UBI_CRC32_INIT, ...) 不做首尾取反
+	return ^crc32.ChecksumIEEE(b)
+}
+
+// ubiEC 擦除计数头
+type ubiEC struct {
+	vidOffset  int64
+	dataOffset int64
+}
+
+func readUBIEC(r io.ReaderAt, off int64) (*ubiEC, bool) {
+	b, err := readAt(r, off, ubiHeaderSize)
+	if err != nil || !bytes.Equal(b[:4], []byte("UBI#")) || binary.BigEndian.Uint32(b[60:]) != ubiCRC(b[:60]) {
+		return nil, false
+	}
+	return &ubiEC{
+		vidOffset:  int64(binary.BigEndian.Uint32(b[16:])),
+		dataOffset: int64(binary.BigEndian.Uint32(b[20:])),
+	}, true
+}
+
+// ubiPEBSize 以下一个擦除计数头的位置确定物理擦除块的大小
+func ubiPEBSize(r io.ReaderAt, off, size int64) int64 {
+	for peb := int64(ubiMinPEBSize); peb <= ubiMaxPEBSize && off+peb < size; peb <<= 1 {
+		if _, ok := readUBIEC(r, off+peb); ok {
+			return peb
+		}
+	}
+	return 0
+}
+
+func headerUBI(r io.ReaderAt, off, _ int64) bool {
+	_, ok := readUBIEC(r, off)
+	return ok
+}
+
+func probeUBI(r io.ReaderAt, off, size int64) int64 {
+	if _, ok := readUBIEC(r, off); !ok {
+		return 0
+	}
+	peb := ubiPEBSize(r, off, size)
+	if peb == 0 {
+		return 0
+	}
+	n := int64(0)
+	for off+(n+1)*peb <= size {
+		if _, ok := readUBIEC(r, off+n*peb); !ok {
+			break
+		}
+		n++
+	}
+	return n * peb
+}
+
+// ubiLEB 逻辑擦除块的位置
+type ubiLEB struct {
+	sqnum uint64
+	off   int64 // 数据在镜像中的偏移
+	size  int64
+}
+
+type ubiVolume struct {
+	id     uint32
+	static bool
+	lebs   map[uint32]ubiLEB
+}
+
+// walkUBI 将 UBI 中的每个卷作为一个文件，卷中的 UBIFS 由上层继续解包
+func walkUBI(r io.ReaderAt, size int64, fn WalkFunc) error {
+	peb := ubiPEBSize(r, 0, size)
+	if peb == 0 {
+		return fmt.Errorf("ubi: unknown PEB size")
+	}
+
+	volumes := map[uint32]*ubiVolume{}
+	var lebSize int64
+	for off := int64(0); off+peb <= size; off += peb {
+		ec, ok := readUBIEC(r, off)
+		if !ok || ec.vidOffset+ubiHeaderSize > peb || ec.dataOffset >= peb {
+			continue
+		}
+		b, err := readAt(r, off+ec.vidOffset, ubiHeaderSize)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(b[:4], ubiVIDMagic) || binary.BigEndian.Uint32(b[60:]) != ubiCRC(b[:60]) {
+			continue
+		}
+		lebSize = peb - ec.dataOffset
+
+		id := binary.BigEndian.Uint32(b[8:])
+		vol := volumes[id]
+		if vol == nil {
+			// vol_type 2 为静态卷，数据大小记录在 VID 头中
+			vol = &ubiVolume{id: id, static: b[5] == 2, lebs: map[uint32]ubiLEB{}}
+			volumes[id] = vol
+		}
+		leb := ubiLEB{sqnum: binary.BigEndian.Uint64(b[40:]), off: off + ec.dataOffset, size: lebSize}
+		if vol.static {
+			if dataSize := int64(binary.BigEndian.Uint32(b[20:])); dataSize <= lebSize {
+				leb.size = dataSize
+			}
+		}
+		lnum := binary.BigEndian.Uint32(b[12:])
+		if old, ok := vol.lebs[lnum]; !ok || leb.sqnum > old.sqnum {
+			vol.lebs[lnum] = leb
+		}
+	}
+
+	names, err := ubiVolumeNames(r, volumes[ubiVolumeTableID])
+	if err != nil {
+		return err
+	}
+	delete(volumes, ubiVolumeTableID)
+
+	ids := make([]uint32, 0, len(volumes))
+	for id := range volumes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		vol := volumes[id]
+		name := cleanName(names[id])
+		if name == "" || bytes.ContainsRune([]byte(name), '/') {
+			name = fmt.Sprintf("vol%d", id)
+		}
+		e := &Entry{Name: name, Mode: 0o644}
+		e.Size, e.Open = vol.reader(r, lebSize)
+		if err = fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ubiVolumeNames 读取卷表中的卷名
+func ubiVolumeNames(r io.ReaderAt, table *ubiVolume) (map[uint32]string, error) {
+	names := map[uint32]string{}
+	if table == nil {
+		return names, nil
+	}
+	leb, ok := table.lebs[0]
+	if !ok {
+		return names, nil
+	}
+	n := leb.size / ubiVolumeRecord
+	if n > ubiMaxVolumes {
+		n = ubiMaxVolumes
+	}
+	b, err := readAt(r, leb.off, int(n*ubiVolumeRecord))
+	if err != nil {
+		return nil, err
+	}
+	for i := int64(0); i < n; i++ {
+		rec := b[i*ubiVolumeRecord : (i+1)*ubiVolumeRecord]
+		if binary.BigEndian.Uint32(rec[168:]) != ubiCRC(rec[:168]) {
+			continue
+		}
+		nameLen := int(binary.BigEndian.Uint16(rec[14:]))
+		if nameLen == 0 || nameLen > ubiVolumeNameSize {
+			continue
+		}
+		names[uint32(i)] = string(rec[16 : 16+nameLen])
+	}
+	return names, nil
+}
+
+// reader 按逻辑块号拼接卷的内容，缺失的块以 0xff 填充
+func (vol *ubiVolume) reader(r io.ReaderAt, lebSize int64) (int64, func() (io.Reader, error)) {
+	var last uint32
+	for lnum := range vol.lebs {
+		if lnum > last {
+			last = lnum
+		}
+	}
+	var size int64
+	readers := make([]func() io.Reader, 0, last+1)
+	for lnum := uint32(0); lnum <= last; lnum++ {
+		leb, ok := vol.lebs[lnum]
+		if !ok {
+			readers = append(readers, func() io.Reader { return io.LimitReader(erased{}, lebSize) })
+			size += lebSize
+			continue
+		}
+		readers = append(readers, func() io.Reader { return io.NewSectionReader(r, leb.off, leb.size) })
+		size += leb.size
+	}
+	return size, func() (io.Reader, error) {
+		rs := make([]io.Reader, len(readers))
+		for i, f := range readers {
+			rs[i] = f()
+		}
+		return io.MultiReader(rs...), nil
+	}
+}
+
+// erased 擦除后的闪存内容
+type erased struct{}
+
+func (erased) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0xff
+	}
+	return len(p), nil
+}
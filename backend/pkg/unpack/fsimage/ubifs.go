@@ -0,0 +1,276 @@
+package fsimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+const (
+	ubifsMagic      = 0x06101831
+	ubifsHeaderSize = 24
+	ubifsNodeIno    = 0
+	ubifsNodeData   = 1
+	ubifsNodeDent   = 2
+	ubifsNodeSB     = 6
+	ubifsInoSize    = 160
+	ubifsDataSize   = 48
+	ubifsDentSize   = 56
+	ubifsBlockSize  = 4096
+	ubifsMaxNode    = ubifsInoSize + ubifsBlockSize // 最大的节点(带内联数据的 inode)
+	ubifsRootIno    = 1
+	ubifsBlockMask  = 0x1fffffff
+	ubifsComprNone  = 0
+	ubifsComprLZO   = 1
+	ubifsComprZlib  = 2
+	ubifsComprZstd  = 3
+)
+
+// ubifsNode 校验 off 处的节点，返回节点类型、序号及完整的节点数据
+func ubifsNode(w *window, off int64) (typ byte, sqnum uint64, node []byte, ok bool) {
+	b, err := w.bytes(off, ubifsHeaderSize)
+	if err != nil || binary.LittleEndian.Uint32(b) != ubifsMagic {
+		return 0, 0, nil, false
+	}
+	l := binary.LittleEndian.Uint32(b[16:])
+	if l < ubifsHeaderSize || l > ubifsMaxNode {
+		return 0, 0, nil, false
+	}
+	node, err = w.bytes(off, int(l))
+	if err != nil || binary.LittleEndian.Uint32(node[4:]) != ubiCRC(node[8:]) {
+		return 0, 0, nil, false
+	}
+	return node[20], binary.LittleEndian.Uint64(node[8:]), node, true
+}
+
+// ubifsSuper 读取超级块中的 LEB 大小及数量
+func ubifsSuper(w *window, off int64) (lebSize, lebCount int64, ok bool) {
+	typ, _, node, ok := ubifsNode(w, off)
+	if !ok || typ != ubifsNodeSB || len(node) < 44 {
+		return 0, 0, false
+	}
+	lebSize = int64(binary.LittleEndian.Uint32(node[36:]))
+	lebCount = int64(binary.LittleEndian.Uint32(node[40:]))
+	if lebSize < ubifsMaxNode || lebSize%8 != 0 || lebCount == 0 {
+		return 0, 0, false
+	}
+	return lebSize, lebCount, true
+}
+
+func headerUBIFS(r io.ReaderAt, off, size int64) bool {
+	_, _, ok := ubifsSuper(&window{r: r, size: size}, off)
+	return ok
+}
+
+func probeUBIFS(r io.ReaderAt, off, size int64) int64 {
+	w := &window{r: r, size: size}
+	lebSize, lebCount, ok := ubifsSuper(w, off)
+	if !ok {
+		return 0
+	}
+	// 以最后一个以节点开头的 LEB 作为结束，空的 LEB 全部为 0xff
+	var end int64
+	for i := int64(0); i < lebCount && off+(i+1)*lebSize <= size; i++ {
+		pos := off + i*lebSize
+		if _, _, _, ok = ubifsNode(w, pos); ok {
+			end = (i + 1) * lebSize
+			continue
+		}
+		b, err := w.bytes(pos, ubifsHeaderSize)
+		if err != nil || !bytes.Equal(b, bytes.Repeat([]byte{0xff}, ubifsHeaderSize)) {
+			break
+		}
+	}
+	return end
+}
+
+type ubifsVersion struct {
+	sqnum uint64
+	off   int64
+}
+
+type ubifsDent struct {
+	sqnum uint64
+	inum  uint64
+}
+
+type ubifsIno struct {
+	ubifsVersion
+	mode uint32
+	size int64
+	data []byte // 符号链接的目标
+}
+
+type ubifsBlock struct {
+	inum  uint32
+	block uint32
+}
+
+func walkUBIFS(r io.ReaderAt, size int64, fn WalkFunc) error {
+	w := &window{r: r, size: size}
+	lebSize, _, ok := ubifsSuper(w, 0)
+	if !ok {
+		return fmt.Errorf("ubifs: bad superblock")
+	}
+
+	type dentKey struct {
+		parent uint64
+		name   string
+	}
+	inodes := map[uint64]*ubifsIno{}
+	blocks := map[ubifsBlock]ubifsVersion{}
+	dents := map[dentKey]ubifsDent{}
+
+	for leb := int64(0); leb+lebSize <= size; leb += lebSize {
+		for pos := leb; pos+ubifsHeaderSize <= leb+lebSize; {
+			typ, sqnum, node, ok := ubifsNode(w, pos)
+			if !ok {
+				// LEB 的剩余部分为空或已损坏
+				b, err := w.bytes(pos, 4)
+				if err != nil || binary.LittleEndian.Uint32(b) == 0xffffffff {
+					break
+				}
+				pos += 8
+				continue
+			}
+			switch typ {
+			case ubifsNodeIno:
+				if len(node) < ubifsInoSize {
+					break
+				}
+				inum := uint64(binary.LittleEndian.Uint32(node[24:]))
+				if old := inodes[inum]; old != nil && old.sqnum > sqnum {
+					break
+				}
+				dataLen := int(binary.LittleEndian.Uint32(node[112:]))
+				if ubifsInoSize+dataLen > len(node) {
+					break
+				}
+				inodes[inum] = &ubifsIno{
+					ubifsVersion: ubifsVersion{sqnum: sqnum, off: pos},
+					mode:         binary.LittleEndian.Uint32(node[104:]),
+					size:         int64(binary.LittleEndian.Uint64(node[48:])),
+					data:         append([]byte(nil), node[ubifsInoSize:ubifsInoSize+dataLen]...),
+				}
+			case ubifsNodeData:
+				if len(node) < ubifsDataSize {
+					break
+				}
+				key := ubifsBlock{inum: binary.LittleEndian.Uint32(node[24:]), block: binary.LittleEndian.Uint32(node[28:]) & ubifsBlockMask}
+				if old, ok := blocks[key]; !ok || sqnum > old.sqnum {
+					blocks[key] = ubifsVersion{sqnum: sqnum, off: pos}
+				}
+			case ubifsNodeDent:
+				if len(node) < ubifsDentSize {
+					break
+				}
+				nlen := int(binary.LittleEndian.Uint16(node[50:]))
+				if ubifsDentSize+nlen > len(node) {
+					break
+				}
+				key := dentKey{parent: uint64(binary.LittleEndian.Uint32(node[24:])), name: string(node[ubifsDentSize : ubifsDentSize+nlen])}
+				if old, ok := dents[key]; !ok || sqnum > old.sqnum {
+					dents[key] = ubifsDent{sqnum: sqnum, inum: binary.LittleEndian.Uint64(node[40:])}
+				}
+			}
+			pos += alignUp(int64(len(node)), 8)
+		}
+	}
+
+	t := &tree{children: map[uint64][]dirent{}}
+	for key, d := range dents {
+		// inum 为 0 的目录项表示已删除
+		if d.inum == 0 || !validName(key.name) {
+			continue
+		}
+		t.children[key.parent] = append(t.children[key.parent], dirent{name: key.name, ino: d.inum})
+	}
+
+	return t.walk(ubifsRootIno, func(name string, inum uint64) (bool, error) {
+		in := inodes[inum]
+		if in == nil {
+			return false, nil
+		}
+		e := &Entry{Name: name, Mode: unixMode(in.mode)}
+		switch {
+		case e.Mode.IsRegular():
+			e.Size = in.size
+			e.Open = func() (io.Reader, error) {
+				return &ubifsFile{w: w, blocks: blocks, inum: uint32(inum), size: in.size}, nil
+			}
+		case e.Mode&fs.ModeSymlink != 0:
+			e.Link = string(in.data)
+		}
+		if err := fn(e); err != nil {
+			return false, err
+		}
+		return e.Mode.IsDir(), nil
+	})
+}
+
+// ubifsFile 按块读取文件内容，没有数据节点的块为空洞
+type ubifsFile struct {
+	w      *window
+	blocks map[ubifsBlock]ubifsVersion
+	inum   uint32
+	size   int64
+	pos    int64
+	buf    []byte
+}
+
+func (f *ubifsFile) Read(p []byte) (int, error) {
+	for len(f.buf) == 0 {
+		if f.pos >= f.size {
+			return 0, io.EOF
+		}
+		if err := f.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+func (f *ubifsFile) next() error {
+	l := f.size - f.pos
+	if l > ubifsBlockSize {
+		l = ubifsBlockSize
+	}
+	block := make([]byte, l)
+	if v, ok := f.blocks[ubifsBlock{inum: f.inum, block: uint32(f.pos / ubifsBlockSize)}]; ok {
+		_, _, node, ok := ubifsNode(f.w, v.off)
+		if !ok {
+			return fmt.Errorf("ubifs: corrupt data node at 0x%x", v.off)
+		}
+		dataSize := int(binary.LittleEndian.Uint32(node[40:]))
+		if dataSize > ubifsBlockSize {
+			return fmt.Errorf("ubifs: bad data node size %d", dataSize)
+		}
+		data, err := ubifsDecompress(binary.LittleEndian.Uint16(node[44:]), node[ubifsDataSize:], dataSize)
+		if err != nil {
+			return err
+		}
+		copy(block, data)
+	}
+	f.pos += l
+	f.buf = block
+	return nil
+}
+
+func ubifsDecompress(compr uint16, src []byte, size int) ([]byte, error) {
+	switch compr {
+	case ubifsComprNone:
+		return src, nil
+	case ubifsComprLZO:
+		return lzo1xDecompress(src, size)
+	case ubifsComprZlib:
+		return decompress(compDeflate, src, size)
+	case ubifsComprZstd:
+		return decompress(compZstd, src, size)
+	default:
+		return nil, fmt.Errorf("%w: ubifs compression %d", ErrUnsupported, compr)
+	}
+}
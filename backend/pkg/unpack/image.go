@@ -0,0 +1,138 @@
+package unpack
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/mholt/archiver/v4"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/unpack/fsimage"
+	"bin-vul-inspector/pkg/utils"
+	"bin-vul-inspector/pkg/utils/archive"
+)
+
+// ScanImages 在文件中查找 SquashFS、CPIO、JFFS2、UBI/UBIFS 等文件系统
+func ScanImages(name string) ([]fsimage.Image, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return fsimage.Scan(f, info.Size())
+}
+
+// DetectImage 仅在文件前 limit 字节中查找文件系统头，返回第一个文件系统的类型，未找到时返回空
+func DetectImage(name string, limit int64) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	return fsimage.Detect(f, info.Size(), limit)
+}
+
+// extractImages 提取固件镜像 src 中的文件系统并识别提取出的文件
+//
+// 每个文件系统记录为 <prefix>/<type>@<offset>，其中的文件以此为前缀；文件系统损坏时记录跳过原因，已提取的文件保留
+func (w *walker) extractImages(src string, images []fsimage.Image, prefix string, depth int) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	dir := w.nextDir()
+	for _, img := range images {
+		file := File{TaskFile: models.TaskFile{
+			Path:   path.Join(prefix, img.Name()),
+			Size:   img.Size,
+			Type:   models.TaskFileImage,
+			Format: img.Type,
+		}}
+		root := filepath.Join(dir, img.Name())
+		if err = fsimage.Walk(f, img, func(e *fsimage.Entry) error {
+			return w.writeEntry(root, file.Path, e)
+		}); err != nil {
+			file.Skipped = err.Error()
+		}
+		w.add(file)
+	}
+	return w.walk(dir, prefix, depth)
+}
+
+// writeEntry 将文件系统中的普通文件写入 root 目录，其他类型的文件及超出限制的文件记录跳过原因
+func (w *walker) writeEntry(root, prefix string, e *fsimage.Entry) error {
+	if e.Mode.IsDir() {
+		return nil
+	}
+
+	info := entryInfo{e}
+	skip := func(reason error) {
+		w.add(File{TaskFile: models.TaskFile{
+			Path:    path.Join(prefix, e.Name),
+			Size:    e.Size,
+			Type:    models.TaskFileOther,
+			Skipped: reason.Error(),
+		}})
+	}
+	if !e.Mode.IsRegular() {
+		skip(fmt.Errorf("not a regular file (%s)", e.Mode.Type()))
+		return nil
+	}
+	if err := archive.Chain([]archiver.File{{FileInfo: info}}, w.rules...); err != nil {
+		skip(err)
+		return nil
+	}
+	name, err := archive.SafeJoin(root, e.Name)
+	if err != nil {
+		skip(err)
+		return nil
+	}
+
+	r, err := e.Open()
+	if err != nil {
+		skip(err)
+		return nil
+	}
+	out, err := utils.NewFile(name).Create()
+	if err != nil {
+		return fmt.Errorf("create file failed, err: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	// 文件内容损坏时仅跳过该文件
+	if _, err = io.Copy(out, io.LimitReader(r, e.Size)); err != nil {
+		_ = out.Close()
+		_ = os.Remove(name)
+		skip(err)
+	}
+	return nil
+}
+
+// entryInfo 文件系统中文件的 fs.FileInfo，用于检查解压规则
+type entryInfo struct {
+	e *fsimage.Entry
+}
+
+func (i entryInfo) Name() string       { return path.Base(i.e.Name) }
+func (i entryInfo) Size() int64        { return i.e.Size }
+func (i entryInfo) Mode() fs.FileMode  { return i.e.Mode }
+func (i entryInfo) ModTime() time.Time { return time.Time{} }
+func (i entryInfo) IsDir() bool        { return i.e.Mode.IsDir() }
+func (i entryInfo) Sys() any           { return nil }
@@ -0,0 +1,180 @@
+package unpack
+
+import (
+	"archive/zip"
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/mholt/archiver/v4"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/utils"
+	"bin-vul-inspector/pkg/utils/archive"
+)
+
+// 安装包类型
+const (
+	PackageAPK = "apk" // Android 应用
+	PackageAAB = "aab" // Android App Bundle
+	PackageAAR = "aar" // Android 库
+	PackageJAR = "jar" // Java 库或应用
+)
+
+// errNotNative 安装包中 native 库以外的文件，不解压也不记录
+var errNotNative = errors.New("not a native library")
+
+// Package 识别 zip 格式的安装包，不是安装包时返回空
+func Package(name string) (string, error) {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = r.Close() }()
+
+	entries := make(map[string]bool, len(r.File))
+	for _, f := range r.File {
+		entries[f.Name] = true
+	}
+	switch {
+	case entries["BundleConfig.pb"] || entries["base/manifest/AndroidManifest.xml"]:
+		return PackageAAB, nil
+	case entries["AndroidManifest.xml"] && entries["classes.jar"]:
+		return PackageAAR, nil
+	case entries["AndroidManifest.xml"]:
+		return PackageAPK, nil
+	case entries["META-INF/MANIFEST.MF"]:
+		return PackageJAR, nil
+	}
+	return "", nil
+}
+
+// NativeLibrary 判断安装包中的文件是否为 native 库，返回其 ABI
+//
+//	apk: lib/<abi>/*.so
+//	aab: <module>/lib/<abi>/*.so
+//	aar: jni/<abi>/*.so
+//	jar: 任意目录下的 .so .dll .dylib .jnilib，ABI 为所在目录名，如 linux-x86-64
+func NativeLibrary(pkg, name string) (abi string, ok bool) {
+	parts := strings.Split(name, "/")
+	base := parts[len(parts)-1]
+	switch pkg {
+	case PackageAPK:
+		ok = len(parts) == 3 && parts[0] == "lib" && strings.HasSuffix(base, ".so")
+	case PackageAAB:
+		ok = len(parts) == 4 && parts[1] == "lib" && strings.HasSuffix(base, ".so")
+	case PackageAAR:
+		ok = len(parts) == 3 && parts[0] == "jni" && strings.HasSuffix(base, ".so")
+	case PackageJAR:
+		switch path.Ext(base) {
+		case ".so", ".dll", ".dylib", ".jnilib":
+			ok = true
+		}
+	}
+	if ok && len(parts) > 1 {
+		abi = parts[len(parts)-2]
+	}
+	return abi, ok
+}
+
+// abiArches Android ABI 对应的架构
+var abiArches = map[string]string{
+	"armeabi":     "arm",
+	"armeabi-v7a": "arm",
+	"arm64-v8a":   "arm64",
+	"x86":         "x86",
+	"x86_64":      "x86_64",
+	"mips":        "mips",
+	"mips64":      "mips64",
+	"riscv64":     "riscv64",
+}
+
+// jarArches jar 中 native 库目录名的架构部分对应的架构
+var jarArches = map[string]string{
+	"x86-64":  "x86_64",
+	"x86_64":  "x86_64",
+	"amd64":   "x86_64",
+	"x64":     "x86_64",
+	"aarch64": "arm64",
+	"arm64":   "arm64",
+	"x86":     "x86",
+	"i386":    "x86",
+	"i686":    "x86",
+	"arm":     "arm",
+	"armhf":   "arm",
+	"armel":   "arm",
+	"armv7":   "arm",
+	"riscv64": "riscv64",
+}
+
+// AbiArch 将 ABI 映射为 bha server 使用的架构名称 x86, x86_64, arm, arm64, mips, mips64, riscv64，无法识别时为空
+func AbiArch(abi string) string {
+	abi = strings.ToLower(abi)
+	if arch, ok := abiArches[abi]; ok {
+		return arch
+	}
+	// jar 中的目录名通常为 <os>-<arch>，如 linux-x86-64、darwin-aarch64
+	if arch, ok := jarArches[abi]; ok {
+		return arch
+	}
+	if _, arch, ok := strings.Cut(abi, "-"); ok {
+		return jarArches[arch]
+	}
+	return ""
+}
+
+// nativeRule 仅解压安装包中的 native 库，jar 中嵌套的 jar 同样解压
+type nativeRule struct {
+	pkg string
+}
+
+func (r nativeRule) Check(f archiver.File) error {
+	if _, ok := NativeLibrary(r.pkg, f.NameInArchive); ok {
+		return nil
+	}
+	if r.pkg == PackageJAR && strings.HasSuffix(f.NameInArchive, ".jar") {
+		return nil
+	}
+	return errNotNative
+}
+
+// extractPackage 解压安装包 src 中的 native 库，记录每个库的 ABI 及对应的架构
+func (w *walker) extractPackage(src, pkg, prefix string, depth int) error {
+	// 安装包中可能没有 native 库
+	dir := w.nextDir()
+	if err := utils.NewFile(dir).CreateDirIfNotExist(); err != nil {
+		return err
+	}
+
+	skip := func(f archiver.File, reason error) {
+		if errors.Is(reason, errNotNative) {
+			return
+		}
+		w.add(File{TaskFile: models.TaskFile{
+			Path:    path.Join(prefix, f.NameInArchive),
+			Size:    f.Size(),
+			Type:    models.TaskFileOther,
+			Skipped: reason.Error(),
+		}})
+	}
+	rules := append([]archive.Rule{nativeRule{pkg: pkg}}, w.rules...)
+	start := len(w.res.Files)
+	if err := archive.NewCompressor(archive.WithTypeOption(archive.Zip)).ExtractFunc(dir, src, skip, rules...); err != nil {
+		return err
+	}
+	if err := w.walk(dir, prefix, depth); err != nil {
+		return err
+	}
+
+	for i := start; i < len(w.res.Files); i++ {
+		file := &w.res.Files[i]
+		name := strings.TrimPrefix(file.Path, prefix+"/")
+		if prefix == "" {
+			name = file.Path
+		}
+		if abi, ok := NativeLibrary(pkg, name); ok && file.Abi == "" {
+			file.Abi, file.Arch = abi, AbiArch(abi)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package unpack
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/mholt/archiver/v4"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/unpack/fsimage"
+	"bin-vul-inspector/pkg/utils/archive"
+)
+
+const (
+	SkipNotExecutable = "not an executable"
+)
+
+// Firmware 上传文件不是压缩包，但其中包含可识别的文件系统
+const Firmware = "firmware"
+
+type Option func(*Unpacker)
+
+func WithMaxFileCount(count uint64) Option {
+	return func(u *Unpacker) {
+		u.maxFileCount = count
+	}
+}
+
+func WithMaxFileSize(size uint64) Option {
+	return func(u *Unpacker) {
+		u.maxFileSize = size
+	}
+}
+
+func WithMaxDepth(depth int) Option {
+	return func(u *Unpacker) {
+		u.maxDepth = depth
+	}
+}
+
+// Unpacker 递归解压上传的压缩包，识别其中的可执行文件作为扫描单元
+type Unpacker struct {
+	maxFileCount uint64 // 解压的文件总数上限，0 为不限制
+	maxFileSize  uint64 // 解压的文件总大小上限，0 为不限制
+	maxDepth     int    // 嵌套压缩包及固件镜像的解压层数上限
+}
+
+func New(opts ...Option) *Unpacker {
+	u := &Unpacker{maxDepth: 1}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// File 解包出的文件
+type File struct {
+	models.TaskFile
+	LocalPath string // 本地路径，仅扫描单元有效
+}
+
+// Result 解包结果
+type Result struct {
+	Archive string            // 上传文件的压缩包类型、安装包类型、firmware 或容器镜像格式，为空时上传文件不是压缩包
+	Image   *models.TaskImage // 容器镜像信息，仅容器镜像有效
+	Files   []File            // 按路径排序
+}
+
+// Units 扫描单元
+func (r *Result) Units() []File {
+	var units []File
+	for i := range r.Files {
+		if r.Files[i].IsUnit() {
+			units = append(units, r.Files[i])
+		}
+	}
+	return units
+}
+
+// Unpack 将压缩包 src 解压至 dst 目录，嵌套的压缩包在层数限制内继续解压，APK、AAB、AAR、JAR 等安装包仅解压其中的 native 库
+// src 不是压缩包时在其中查找 SquashFS、CPIO、JFFS2、UBI/UBIFS 等文件系统并提取
+//
+// 文件数及大小限制对全部层级累计，超出限制、路径越界及非普通文件均记录跳过原因，不中断解包
+func (u *Unpacker) Unpack(src, dst string) (*Result, error) {
+	kind, err := archive.Identify(src)
+	if err != nil {
+		return nil, err
+	}
+	var images []fsimage.Image
+	if kind == "" {
+		if images, err = ScanImages(src); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &Result{Archive: string(kind)}
+	if kind == archive.Zip {
+		if pkg, err := Package(src); err == nil && pkg != "" {
+			res.Archive = pkg
+		}
+	}
+	w := &walker{
+		Unpacker: u,
+		dst:      dst,
+		res:      res,
+		rules: []archive.Rule{
+			archive.WithMaxFileCountRule(u.maxFileCount),
+			archive.WithMaxFileSizeRule(u.maxFileSize),
+		},
+	}
+	switch {
+	case kind != "":
+		err = w.extract(src, kind, "", 1)
+	case len(images) > 0:
+		res.Archive = Firmware
+		err = w.extractImages(src, images, "", 1)
+	default:
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(res.Files, func(i, j int) bool {
+		return res.Files[i].Path < res.Files[j].Path
+	})
+	return res, nil
+}
+
+type walker struct {
+	*Unpacker
+	dst   string
+	dirs  int // 已使用的解压目录数
+	rules []archive.Rule
+	res   *Result
+}
+
+// extract 解压 src 并识别解压出的文件，prefix 为压缩包在上传文件中的路径
+func (w *walker) extract(src string, kind archive.Kind, prefix string, depth int) error {
+	// 安装包仅解压其中的 native 库
+	if kind == archive.Zip {
+		pkg, err := Package(src)
+		if err != nil {
+			return err
+		}
+		if pkg != "" {
+			return w.extractPackage(src, pkg, prefix, depth)
+		}
+	}
+
+	dir := w.nextDir()
+
+	skip := func(f archiver.File, reason error) {
+		w.add(File{TaskFile: models.TaskFile{
+			Path:    path.Join(prefix, f.NameInArchive),
+			Size:    f.Size(),
+			Type:    models.TaskFileOther,
+			Skipped: reason.Error(),
+		}})
+	}
+	if err := archive.NewCompressor(archive.WithTypeOption(kind)).ExtractFunc(dir, src, skip, w.rules...); err != nil {
+		return err
+	}
+	return w.walk(dir, prefix, depth)
+}
+
+func (w *walker) nextDir() string {
+	dir := filepath.Join(w.dst, strconv.Itoa(w.dirs))
+	w.dirs++
+	return dir
+}
+
+// nestedFile 嵌套的压缩包或固件镜像
+type nestedFile struct {
+	File
+	kind   archive.Kind
+	images []fsimage.Image
+}
+
+// walk 识别 dir 中解压出的文件，depth 为 dir 所在的层数
+func (w *walker) walk(dir, prefix string, depth int) error {
+	// 先完成当前目录的遍历，再解压嵌套的压缩包
+	var nested []nestedFile
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+
+		file := File{
+			TaskFile: models.TaskFile{
+				Path: path.Join(prefix, filepath.ToSlash(rel)),
+				Size: info.Size(),
+				Type: models.TaskFileOther,
+			},
+			LocalPath: name,
+		}
+		if file.Format, err = BinaryFormat(name); err != nil {
+			return err
+		}
+		if file.Format != "" {
+			file.Type = models.TaskFileBinary
+			w.add(file)
+			return nil
+		}
+
+		nf := nestedFile{File: file}
+		if nf.kind, err = archive.Identify(name); err == nil && nf.kind == "" {
+			nf.images, err = ScanImages(name)
+		}
+		switch {
+		case err != nil:
+			file.Skipped = err.Error()
+		case nf.kind != "":
+			nf.Type = models.TaskFileArchive
+			nested = append(nested, nf)
+			return nil
+		case len(nf.images) > 0:
+			nf.Type = models.TaskFileImage
+			nested = append(nested, nf)
+			return nil
+		default:
+			file.Skipped = SkipNotExecutable
+		}
+		file.LocalPath = ""
+		w.add(file)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range nested {
+		var err error
+		switch {
+		case depth >= w.maxDepth:
+			file.Skipped = fmt.Sprintf("exceeded the limit (%d) of max archive depth", w.maxDepth)
+		case file.kind != "":
+			err = w.extract(file.LocalPath, file.kind, file.Path, depth+1)
+		default:
+			err = w.extractImages(file.LocalPath, file.images, file.Path, depth+1)
+		}
+		if err != nil {
+			// 嵌套压缩包损坏时仅跳过该压缩包
+			file.Skipped = err.Error()
+		}
+		file.LocalPath = ""
+		w.add(file.File)
+	}
+	return nil
+}
+
+func (w *walker) add(file File) {
+	w.res.Files = append(w.res.Files, file)
+}
@@ -0,0 +1,282 @@
+package unpack_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/unpack"
+)
+
+func elfFile() []byte {
+	return append([]byte("\x7fELF\x02\x01\x01"), make([]byte, 57)...)
+}
+
+func peFile() []byte {
+	data := make([]byte, 0x80)
+	copy(data, "MZ")
+	binary.LittleEndian.PutUint32(data[0x3c:], 0x40)
+	copy(data[0x40:], "PE\x00\x00")
+	return data
+}
+
+func tarGz(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+// cpioNewc 构造 newc 格式的 cpio，mode 为 0 时为普通文件
+func cpioNewc(files ...struct {
+	name string
+	mode uint32
+	data []byte
+}) []byte {
+	var buf bytes.Buffer
+	write := func(mode uint32, name string, data []byte) {
+		_, _ = fmt.Fprintf(&buf, "070701%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+			0, mode, 0, 0, 1, 0, len(data), 0, 0, 0, 0, len(name)+1, 0)
+		buf.WriteString(name + "\x00")
+		for buf.Len()%4 != 0 {
+			buf.WriteByte(0)
+		}
+		buf.Write(data)
+		for buf.Len()%4 != 0 {
+			buf.WriteByte(0)
+		}
+	}
+	for _, f := range files {
+		if f.mode == 0 {
+			f.mode = 0o100755
+		}
+		write(f.mode, f.name, f.data)
+	}
+	write(0, "TRAILER!!!", nil)
+	return buf.Bytes()
+}
+
+func writeZip(t *testing.T, name string, files map[string][]byte) {
+	f, err := os.Create(name)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	// 按名称顺序写入，使文件数限制的结果确定
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zw := zip.NewWriter(f)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(files[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+}
+
+func TestUnpacker_Unpack(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "firmware.zip")
+	writeZip(t, src, map[string][]byte{
+		"bin/busybox":   elfFile(),
+		"etc/passwd":    []byte("root:x:0:0::/root:/bin/sh\n"),
+		"../evil":       elfFile(),
+		"rootfs.tar.gz": tarGz(t, map[string][]byte{"app.exe": peFile(), "lib/libc.so": elfFile()}),
+		"Main.class":    append([]byte{0xca, 0xfe, 0xba, 0xbe, 0, 0, 0, 52}, make([]byte, 56)...),
+	})
+
+	res, err := unpack.New(unpack.WithMaxDepth(2)).Unpack(src, filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.EqualValues(t, "zip", res.Archive)
+
+	files := make(map[string]unpack.File)
+	for _, f := range res.Files {
+		files[f.Path] = f
+	}
+
+	busybox := files["bin/busybox"]
+	assert.Equal(t, models.BinaryFormatELF, busybox.Format)
+	assert.True(t, busybox.IsUnit())
+	assert.Equal(t, models.BinaryFormatPE, files["rootfs.tar.gz/app.exe"].Format)
+	assert.Equal(t, models.BinaryFormatELF, files["rootfs.tar.gz/lib/libc.so"].Format)
+	assert.Equal(t, models.TaskFileArchive, files["rootfs.tar.gz"].Type)
+	assert.Empty(t, files["rootfs.tar.gz"].Skipped)
+
+	assert.Equal(t, unpack.SkipNotExecutable, files["etc/passwd"].Skipped)
+	assert.Equal(t, unpack.SkipNotExecutable, files["Main.class"].Skipped)
+	assert.Contains(t, files["../evil"].Skipped, "unsafe path")
+	assert.NoFileExists(t, filepath.Join(dir, "evil"))
+
+	var units []string
+	for _, f := range res.Units() {
+		units = append(units, f.Path)
+		assert.FileExists(t, f.LocalPath)
+	}
+	assert.Equal(t, []string{"bin/busybox", "rootfs.tar.gz/app.exe", "rootfs.tar.gz/lib/libc.so"}, units)
+}
+
+func TestUnpacker_Limits(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "firmware.zip")
+	writeZip(t, src, map[string][]byte{
+		"a.tar.gz": tarGz(t, map[string][]byte{"a": elfFile()}),
+		"b":        elfFile(),
+		"c":        elfFile(),
+	})
+
+	res, err := unpack.New(unpack.WithMaxFileCount(2), unpack.WithMaxDepth(1)).Unpack(src, filepath.Join(dir, "out"))
+	require.NoError(t, err)
+
+	var skipped int
+	for _, f := range res.Files {
+		if f.Path == "a.tar.gz" {
+			assert.Contains(t, f.Skipped, "max archive depth")
+		}
+		if f.Skipped != "" {
+			skipped++
+		}
+	}
+	// 超出文件数的文件及超出层数的压缩包
+	assert.Equal(t, 2, skipped)
+	assert.Len(t, res.Units(), 1)
+
+	// 非压缩包
+	bin := filepath.Join(dir, "busybox")
+	require.NoError(t, os.WriteFile(bin, elfFile(), 0o644))
+	res, err = unpack.New().Unpack(bin, filepath.Join(dir, "out2"))
+	require.NoError(t, err)
+	assert.Empty(t, res.Archive)
+	assert.Empty(t, res.Files)
+}
+
+func TestUnpacker_Firmware(t *testing.T) {
+	type file = struct {
+		name string
+		mode uint32
+		data []byte
+	}
+	rootfs := cpioNewc(
+		file{name: "bin/busybox", data: elfFile()},
+		file{name: "bin/sh", mode: 0o120777, data: []byte("busybox")},
+		file{name: "etc/passwd", data: []byte("root:x:0:0::/root:/bin/sh\n")},
+		file{name: "www/app.tar.gz", data: tarGz(t, map[string][]byte{"cgi": elfFile()})},
+	)
+	// 内核等数据之后为文件系统
+	image := append(bytes.Repeat([]byte{0xAA}, 0x200), rootfs...)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "DIR_850L.bin")
+	require.NoError(t, os.WriteFile(src, image, 0o644))
+
+	res, err := unpack.New(unpack.WithMaxDepth(2)).Unpack(src, filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, unpack.Firmware, res.Archive)
+
+	files := make(map[string]unpack.File)
+	for _, f := range res.Files {
+		files[f.Path] = f
+	}
+	fsFile := files["cpio@0x200"]
+	assert.Equal(t, models.TaskFileImage, fsFile.Type)
+	assert.Equal(t, "cpio", fsFile.Format)
+	assert.EqualValues(t, len(rootfs), fsFile.Size)
+	assert.Contains(t, files["cpio@0x200/bin/sh"].Skipped, "not a regular file")
+	assert.Equal(t, unpack.SkipNotExecutable, files["cpio@0x200/etc/passwd"].Skipped)
+	assert.Equal(t, models.TaskFileArchive, files["cpio@0x200/www/app.tar.gz"].Type)
+
+	var units []string
+	for _, f := range res.Units() {
+		units = append(units, f.Path)
+	}
+	assert.Equal(t, []string{"cpio@0x200/bin/busybox", "cpio@0x200/www/app.tar.gz/cgi"}, units)
+
+	// 压缩包中的固件镜像
+	zipSrc := filepath.Join(dir, "firmware.zip")
+	writeZip(t, zipSrc, map[string][]byte{"DIR_850L.bin": image})
+	res, err = unpack.New(unpack.WithMaxDepth(1)).Unpack(zipSrc, filepath.Join(dir, "out2"))
+	require.NoError(t, err)
+	require.Len(t, res.Files, 1)
+	assert.Equal(t, models.TaskFileImage, res.Files[0].Type)
+	assert.Contains(t, res.Files[0].Skipped, "max archive depth")
+}
+
+func TestUnpacker_Package(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "app.apk")
+	writeZip(t, src, map[string][]byte{
+		"AndroidManifest.xml":          []byte("manifest"),
+		"classes.dex":                  []byte("dex\n035"),
+		"assets/libasset.so":           elfFile(),
+		"lib/arm64-v8a/libnative.so":   elfFile(),
+		"lib/armeabi-v7a/libnative.so": elfFile(),
+		"lib/x86_64/libnative.so":      elfFile(),
+	})
+
+	pkg, err := unpack.Package(src)
+	require.NoError(t, err)
+	assert.Equal(t, unpack.PackageAPK, pkg)
+
+	res, err := unpack.New().Unpack(src, filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, unpack.PackageAPK, res.Archive)
+	// 仅解压 native 库
+	units := make(map[string][2]string)
+	for _, f := range res.Files {
+		units[f.Path] = [2]string{f.Abi, f.Arch}
+	}
+	assert.Equal(t, map[string][2]string{
+		"lib/arm64-v8a/libnative.so":   {"arm64-v8a", "arm64"},
+		"lib/armeabi-v7a/libnative.so": {"armeabi-v7a", "arm"},
+		"lib/x86_64/libnative.so":      {"x86_64", "x86_64"},
+	}, units)
+
+	// 压缩包中的 jar
+	jar := filepath.Join(dir, "sdk.jar")
+	writeZip(t, jar, map[string][]byte{
+		"META-INF/MANIFEST.MF":                 []byte("Manifest-Version: 1.0\n"),
+		"com/example/Native.class":             []byte("\xca\xfe\xba\xbe"),
+		"native/linux-x86-64/libjni.so":        elfFile(),
+		"native/darwin-aarch64/libjni.dylib":   elfFile(),
+		"native/win32-x86-64/jni.dll":          peFile(),
+		"native/linux-riscv64/libjni.so.debug": []byte("debug"),
+	})
+	data, err := os.ReadFile(jar)
+	require.NoError(t, err)
+	src = filepath.Join(dir, "bundle.zip")
+	writeZip(t, src, map[string][]byte{"sdk.jar": data})
+
+	res, err = unpack.New(unpack.WithMaxDepth(2)).Unpack(src, filepath.Join(dir, "out2"))
+	require.NoError(t, err)
+	assert.Equal(t, "zip", res.Archive)
+	units = make(map[string][2]string)
+	for _, f := range res.Units() {
+		units[f.Path] = [2]string{f.Abi, f.Arch}
+	}
+	assert.Equal(t, map[string][2]string{
+		"sdk.jar/native/linux-x86-64/libjni.so":      {"linux-x86-64", "x86_64"},
+		"sdk.jar/native/darwin-aarch64/libjni.dylib": {"darwin-aarch64", "arm64"},
+		"sdk.jar/native/win32-x86-64/jni.dll":        {"win32-x86-64", "x86_64"},
+	}, units)
+}
@@ -3,10 +3,12 @@ package archive
 import (
 	"context"
 	"crypto/cipher"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/mholt/archiver/v4"
 	"golang.org/x/sync/errgroup"
@@ -25,8 +27,13 @@ const (
 	TarSz  Kind = "tar.sz"
 	TarBz2 Kind = "tar.bz2"
 	TarLz4 Kind = "tar.lz4"
+	Tar    Kind = "tar"
+	SevenZ Kind = "7z" // 仅支持解压
 )
 
+// ErrUnsafePath 压缩包内文件路径越出解压目录
+var ErrUnsafePath = errors.New("unsafe path in archive")
+
 const (
 	Deflate uint16 = 8
 )
@@ -107,11 +114,67 @@ func (c *Compressor) format() (*archiver.CompressedArchive, error) {
 			Archival:    archiver.Tar{},
 			Compression: archiver.Lz4{},
 		}, nil
+	case Tar:
+		return &archiver.CompressedArchive{
+			Archival: archiver.Tar{},
+		}, nil
+	case SevenZ:
+		return &archiver.CompressedArchive{
+			Archival: archiver.SevenZip{},
+		}, nil
 	default:
 		return nil, fmt.Errorf("not implemented for %s", c.kind)
 	}
 }
 
+// Identify 根据文件内容识别压缩包类型，不是支持的压缩包时返回空
+func Identify(path string) (Kind, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	// 不传文件名，仅按文件内容识别
+	format, _, err := archiver.Identify("", f)
+	if err != nil {
+		if errors.Is(err, archiver.ErrNoMatch) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	switch v := format.(type) {
+	case archiver.Zip:
+		return Zip, nil
+	case archiver.Tar:
+		return Tar, nil
+	case archiver.SevenZip:
+		return SevenZ, nil
+	case archiver.CompressedArchive:
+		if _, ok := v.Archival.(archiver.Tar); !ok {
+			return "", nil
+		}
+		switch v.Compression.(type) {
+		case archiver.Gz:
+			return TarGz, nil
+		case archiver.Xz:
+			return TarXz, nil
+		case archiver.Zstd:
+			return TarZst, nil
+		case archiver.Zlib:
+			return TarZz, nil
+		case archiver.Sz:
+			return TarSz, nil
+		case archiver.Bz2:
+			return TarBz2, nil
+		case archiver.Lz4:
+			return TarLz4, nil
+		}
+	}
+	return "", nil
+}
+
 func (c *Compressor) Name() (string, error) {
 	format, err := c.format()
 	if err != nil {
@@ -311,18 +374,103 @@ func (c *Compressor) Extract(dstPath string, srcPath string, rules ...Rule) erro
 		}
 
 		if f.IsDir() {
-			return utils.NewFile(filepath.Join(dstPath, f.NameInArchive)).CreateDirIfNotExist()
+			name, err := SafeJoin(dstPath, f.NameInArchive)
+			if err != nil {
+				return err
+			}
+			return utils.NewFile(name).CreateDirIfNotExist()
+		}
+
+		return c.handleFile(ctx, f, dstPath)
+	})
+}
+
+// Walk 依次读取压缩包中的文件而不写入磁盘，由 handler 自行处理文件内容
+func (c *Compressor) Walk(srcPath string, handler archiver.FileHandler) error {
+	input, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("walk file failed, err: %w", err)
+	}
+	defer func() { _ = input.Close() }()
+
+	format, err := c.format()
+	if err != nil || format == nil {
+		return fmt.Errorf("init archive failed, err: %w", err)
+	}
+
+	reader, err := c.openReadCloser(input)
+	if err != nil {
+		return fmt.Errorf("init archive failed, err: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	return format.Extract(context.Background(), reader.RawReader(), nil, handler)
+}
+
+// SkipFunc 处理解压时跳过的文件
+type SkipFunc func(f archiver.File, reason error)
+
+// ExtractFunc 解压文件，与 Extract 不同的是不满足规则、路径越界及非普通文件均跳过并交由 skip 处理，不中断解压
+func (c *Compressor) ExtractFunc(dstPath string, srcPath string, skip SkipFunc, rules ...Rule) error {
+	input, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("extract file failed, err: %w", err)
+	}
+	defer func() { _ = input.Close() }()
+
+	format, err := c.format()
+	if err != nil || format == nil {
+		return fmt.Errorf("init archive failed, err: %w", err)
+	}
+
+	reader, err := c.openReadCloser(input)
+	if err != nil {
+		return fmt.Errorf("init archive failed, err: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	return format.Extract(context.Background(), reader.RawReader(), nil, func(ctx context.Context, f archiver.File) error {
+		name, err := SafeJoin(dstPath, f.NameInArchive)
+		if err != nil {
+			skip(f, err)
+			return nil
+		}
+		if f.IsDir() {
+			return utils.NewFile(name).CreateDirIfNotExist()
+		}
+		if !f.Mode().IsRegular() {
+			skip(f, fmt.Errorf("not a regular file (%s)", f.Mode().Type()))
+			return nil
+		}
+		if err = Chain([]archiver.File{f}, rules...); err != nil {
+			skip(f, err)
+			return nil
 		}
 
 		return c.handleFile(ctx, f, dstPath)
 	})
 }
 
+// SafeJoin 拼接解压路径，防止 ../ 及绝对路径越出解压目录
+func SafeJoin(dstPath, nameInArchive string) (string, error) {
+	name := filepath.Join(dstPath, filepath.FromSlash(strings.TrimLeft(nameInArchive, "/")))
+	rel, err := filepath.Rel(dstPath, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, nameInArchive)
+	}
+	return name, nil
+}
+
 func (c *Compressor) handleFile(ctx context.Context, f archiver.File, dstPath string) error {
 	var err error
 	var newFile *os.File
 
-	newFile, err = utils.NewFile(filepath.Join(dstPath, f.NameInArchive)).Create()
+	name, err := SafeJoin(dstPath, f.NameInArchive)
+	if err != nil {
+		return err
+	}
+
+	newFile, err = utils.NewFile(name).Create()
 	if err != nil {
 		return fmt.Errorf("create file failed, err: %w", err)
 	}
@@ -0,0 +1,100 @@
+package vulndb
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"bin-vul-inspector/pkg/models"
+)
+
+// ParseKEV 解析 CISA KEV CSV (known_exploited_vulnerabilities.csv)
+func ParseKEV(r io.Reader, handler func(kev models.VulnKEV) error) error {
+	return parseCSV(r, []string{"cveID", "dateAdded"}, func(row map[string]string) error {
+		kev := models.VulnKEV{CVE: strings.ToUpper(row["cveID"])}
+		if row["dateAdded"] != "" {
+			t, err := time.Parse(time.DateOnly, row["dateAdded"])
+			if err != nil {
+				return fmt.Errorf("invalid dateAdded %q, %w", row["dateAdded"], err)
+			}
+			kev.DateAdded = t
+		}
+		return handler(kev)
+	})
+}
+
+// ParseEPSS 解析 EPSS CSV (epss_scores-YYYY-MM-DD.csv)
+// 文件首行为 #model_version 注释
+func ParseEPSS(r io.Reader, handler func(epss models.VulnEPSS) error) error {
+	return parseCSV(r, []string{"cve", "epss", "percentile"}, func(row map[string]string) error {
+		epss := models.VulnEPSS{CVE: strings.ToUpper(row["cve"])}
+
+		var err error
+		if epss.EPSS, err = strconv.ParseFloat(row["epss"], 64); err != nil {
+			return fmt.Errorf("invalid epss %q, %w", row["epss"], err)
+		}
+		if epss.Percentile, err = strconv.ParseFloat(row["percentile"], 64); err != nil {
+			return fmt.Errorf("invalid percentile %q, %w", row["percentile"], err)
+		}
+		return handler(epss)
+	})
+}
+
+// parseCSV 按表头解析CSV，跳过#开头的注释行
+func parseCSV(r io.Reader, columns []string, handler func(row map[string]string) error) error {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.Peek(1)
+		if err != nil || b[0] != '#' {
+			break
+		}
+		if _, err = br.ReadString('\n'); err != nil {
+			break
+		}
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("csv header not found")
+		}
+		return err
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	for _, name := range columns {
+		if _, ok := index[name]; !ok {
+			return fmt.Errorf("csv column %s not found", name)
+		}
+	}
+
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		row := make(map[string]string, len(columns))
+		for _, name := range columns {
+			if i := index[name]; i < len(record) {
+				row[name] = strings.TrimSpace(record[i])
+			}
+		}
+		if err = handler(row); err != nil {
+			return fmt.Errorf("row %d: %w", n, err)
+		}
+	}
+}
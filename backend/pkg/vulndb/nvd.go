@@ -0,0 +1,254 @@
+package vulndb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/utils"
+)
+
+// nvdTimeLayout NVD时间格式，不含时区，为UTC时间
+const nvdTimeLayout = "2006-01-02T15:04:05.000"
+
+// CVSS版本优先级，依次选取作为漏洞的严重等级
+var nvdMetricKeys = []string{"cvssMetricV31", "cvssMetricV30", "cvssMetricV40", "cvssMetricV2"}
+
+type nvdItem struct {
+	CVE nvdCVE `json:"cve"`
+}
+
+type nvdCVE struct {
+	Id           string `json:"id"`
+	Published    string `json:"published"`
+	LastModified string `json:"lastModified"`
+	Descriptions []struct {
+		Lang  string `json:"lang"`
+		Value string `json:"value"`
+	} `json:"descriptions"`
+	Weaknesses []struct {
+		Description []struct {
+			Lang  string `json:"lang"`
+			Value string `json:"value"`
+		} `json:"description"`
+	} `json:"weaknesses"`
+	Metrics        map[string][]nvdMetric `json:"metrics"`
+	Configurations []struct {
+		Nodes []struct {
+			Negate   bool          `json:"negate"`
+			CpeMatch []nvdCpeMatch `json:"cpeMatch"`
+		} `json:"nodes"`
+	} `json:"configurations"`
+}
+
+type nvdCpeMatch struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	Criteria              string `json:"criteria"`
+	VersionStartIncluding string `json:"versionStartIncluding"`
+	VersionStartExcluding string `json:"versionStartExcluding"`
+	VersionEndIncluding   string `json:"versionEndIncluding"`
+	VersionEndExcluding   string `json:"versionEndExcluding"`
+}
+
+type nvdMetric struct {
+	Source   string `json:"source"`
+	Type     string `json:"type"`
+	CvssData struct {
+		Version      string  `json:"version"`
+		VectorString string  `json:"vectorString"`
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+	BaseSeverity string `json:"baseSeverity"` // CVSS v2 的严重等级不在cvssData中
+}
+
+// ParseNVD 流式解析 NVD JSON 2.0 数据源，逐个漏洞回调
+func ParseNVD(r io.Reader, handler func(vuln *models.Vulnerability) error) error {
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return fmt.Errorf("invalid nvd feed, %w", err)
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("invalid nvd feed, %w", err)
+		}
+		if key != "vulnerabilities" {
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return fmt.Errorf("invalid nvd feed, %w", err)
+			}
+			continue
+		}
+
+		if err = expectDelim(dec, '['); err != nil {
+			return fmt.Errorf("invalid nvd feed vulnerabilities, %w", err)
+		}
+		for i := 0; dec.More(); i++ {
+			var item nvdItem
+			if err = dec.Decode(&item); err != nil {
+				return fmt.Errorf("invalid nvd feed vulnerabilities[%d], %w", i, err)
+			}
+			if item.CVE.Id == "" {
+				return fmt.Errorf("invalid nvd feed vulnerabilities[%d], cve id is empty", i)
+			}
+			if err = handler(item.CVE.vulnerability()); err != nil {
+				return err
+			}
+		}
+		if err = expectDelim(dec, ']'); err != nil {
+			return fmt.Errorf("invalid nvd feed vulnerabilities, %w", err)
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func (c *nvdCVE) vulnerability() *models.Vulnerability {
+	vuln := &models.Vulnerability{
+		CVE:          c.Id,
+		CWEs:         make([]string, 0),
+		CVSS:         make([]models.CVSS, 0),
+		Severity:     models.SeverityUnknown,
+		Published:    parseNVDTime(c.Published),
+		LastModified: parseNVDTime(c.LastModified),
+	}
+
+	for _, d := range c.Descriptions {
+		if d.Lang == "en" {
+			vuln.Description = d.Value
+			break
+		}
+	}
+
+	for _, w := range c.Weaknesses {
+		for _, d := range w.Description {
+			// NVD-CWE-Other, NVD-CWE-noinfo 不是有效的CWE
+			if strings.HasPrefix(d.Value, "CWE-") && !utils.Contains(vuln.CWEs, d.Value) {
+				vuln.CWEs = append(vuln.CWEs, d.Value)
+			}
+		}
+	}
+
+	primary := -1
+	for _, key := range nvdMetricKeys {
+		for _, m := range c.Metrics[key] {
+			severity := m.CvssData.BaseSeverity
+			if severity == "" {
+				severity = m.BaseSeverity
+			}
+			vuln.CVSS = append(vuln.CVSS, models.CVSS{
+				Version:   m.CvssData.Version,
+				Vector:    m.CvssData.VectorString,
+				BaseScore: m.CvssData.BaseScore,
+				Severity:  strings.ToLower(severity),
+				Source:    m.Source,
+				Type:      m.Type,
+			})
+
+			// 同一版本中优先选取Primary评分
+			i := len(vuln.CVSS) - 1
+			if primary < 0 || (m.Type == "Primary" && vuln.CVSS[primary].Type != "Primary" && vuln.CVSS[primary].Version == vuln.CVSS[i].Version) {
+				primary = i
+			}
+		}
+	}
+	if primary >= 0 {
+		vuln.Score = vuln.CVSS[primary].BaseScore
+		if vuln.CVSS[primary].Severity != "" {
+			vuln.Severity = vuln.CVSS[primary].Severity
+		}
+	}
+
+	vuln.Affected = c.affected()
+
+	return vuln
+}
+
+// affected 提取CPE配置中受影响的产品版本范围，忽略取反的节点及运行环境(vulnerable=false)
+func (c *nvdCVE) affected() []models.VulnAffected {
+	var list []models.VulnAffected
+	for _, conf := range c.Configurations {
+		for _, node := range conf.Nodes {
+			if node.Negate {
+				continue
+			}
+			for _, m := range node.CpeMatch {
+				if !m.Vulnerable {
+					continue
+				}
+				a, ok := m.affected()
+				if ok && !utils.Contains(list, a) {
+					list = append(list, a)
+				}
+			}
+		}
+	}
+	return list
+}
+
+// affected 解析 cpe:2.3:part:vendor:product:version:update:...
+func (m *nvdCpeMatch) affected() (models.VulnAffected, bool) {
+	fields := splitCPE(m.Criteria)
+	if len(fields) < 7 || fields[0] != "cpe" || fields[1] != "2.3" {
+		return models.VulnAffected{}, false
+	}
+	a := models.VulnAffected{
+		Vendor:         fields[3],
+		Product:        fields[4],
+		StartIncluding: m.VersionStartIncluding,
+		StartExcluding: m.VersionStartExcluding,
+		EndIncluding:   m.VersionEndIncluding,
+		EndExcluding:   m.VersionEndExcluding,
+	}
+	// * 表示任意版本，- 表示不适用
+	if version := fields[5]; version != "*" && version != "-" {
+		a.Version = version
+		if update := fields[6]; update != "*" && update != "-" {
+			a.Version += "-" + update
+		}
+	}
+	return a, true
+}
+
+// splitCPE 按冒号拆分CPE，保留转义的冒号
+func splitCPE(cpe string) []string {
+	var fields []string
+	var b strings.Builder
+	for i := 0; i < len(cpe); i++ {
+		switch {
+		case cpe[i] == '\\' && i+1 < len(cpe):
+			i++
+			b.WriteByte(cpe[i])
+		case cpe[i] == ':':
+			fields = append(fields, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(cpe[i])
+		}
+	}
+	return append(fields, b.String())
+}
+
+func parseNVDTime(value string) time.Time {
+	for _, layout := range []string{nvdTimeLayout, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected '%s', got %v", want, token)
+	}
+	return nil
+}
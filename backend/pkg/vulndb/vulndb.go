@@ -0,0 +1,49 @@
+// Package vulndb 解析离线漏洞数据源
+//
+// 支持 NVD JSON 2.0 数据源、CISA KEV CSV 及 EPSS CSV，文件可为gzip压缩
+package vulndb
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+type file struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (f *file) Close() error {
+	var err error
+	for i := len(f.closers) - 1; i >= 0; i-- {
+		if e := f.closers[i].Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Open 打开数据源文件，gzip压缩的文件自动解压
+func Open(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(f)
+	magic, _ := r.Peek(len(gzipMagic))
+	if string(magic) != string(gzipMagic) {
+		return &file{Reader: r, closers: []io.Closer{f}}, nil
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &file{Reader: gz, closers: []io.Closer{f, gz}}, nil
+}
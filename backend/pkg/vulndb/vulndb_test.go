@@ -0,0 +1,99 @@
+package vulndb_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bin-vul-inspector/pkg/models"
+	"bin-vul-inspector/pkg/vulndb"
+)
+
+func TestParseNVD(t *testing.T) {
+	data := `{
+		"resultsPerPage": 1,
+		"format": "NVD_CVE",
+		"version": "2.0",
+		"vulnerabilities": [{
+			"cve": {
+				"id": "CVE-2014-0160",
+				"published": "2014-04-07T22:55:03.893",
+				"lastModified": "2023-11-07T02:18:10.590",
+				"descriptions": [{"lang": "es", "value": "x"}, {"lang": "en", "value": "Heartbleed"}],
+				"weaknesses": [{"description": [{"lang": "en", "value": "CWE-125"}, {"lang": "en", "value": "NVD-CWE-Other"}]}],
+				"metrics": {
+					"cvssMetricV31": [
+						{"source": "a@b", "type": "Secondary", "cvssData": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N", "baseScore": 5.0, "baseSeverity": "MEDIUM"}},
+						{"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L", "baseScore": 7.5, "baseSeverity": "HIGH"}}
+					],
+					"cvssMetricV2": [
+						{"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"version": "2.0", "vectorString": "AV:N/AC:L/Au:N/C:P/I:N/A:N", "baseScore": 5.0}, "baseSeverity": "MEDIUM"}
+					]
+				},
+				"configurations": [{
+					"nodes": [{
+						"operator": "OR",
+						"negate": false,
+						"cpeMatch": [
+							{"vulnerable": true, "criteria": "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", "versionStartIncluding": "1.0.1", "versionEndExcluding": "1.0.1g"},
+							{"vulnerable": true, "criteria": "cpe:2.3:a:openssl:openssl:1.0.2:beta1:*:*:*:*:*:*"},
+							{"vulnerable": false, "criteria": "cpe:2.3:o:debian:debian_linux:7.0:*:*:*:*:*:*:*"}
+						]
+					}]
+				}]
+			}
+		}]
+	}`
+
+	var vulns []*models.Vulnerability
+	err := vulndb.ParseNVD(strings.NewReader(data), func(v *models.Vulnerability) error {
+		vulns = append(vulns, v)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, vulns, 1) {
+		v := vulns[0]
+		assert.Equal(t, "CVE-2014-0160", v.CVE)
+		assert.Equal(t, "Heartbleed", v.Description)
+		assert.Equal(t, []string{"CWE-125"}, v.CWEs)
+		assert.Equal(t, models.SeverityHigh, v.Severity)
+		assert.Equal(t, 7.5, v.Score)
+		assert.Len(t, v.CVSS, 3)
+		assert.Equal(t, "medium", v.CVSS[2].Severity)
+		assert.Equal(t, 2014, v.Published.Year())
+		assert.Equal(t, []models.VulnAffected{
+			{Vendor: "openssl", Product: "openssl", StartIncluding: "1.0.1", EndExcluding: "1.0.1g"},
+			{Vendor: "openssl", Product: "openssl", Version: "1.0.2-beta1"},
+		}, v.Affected)
+	}
+}
+
+func TestParseKEVAndEPSS(t *testing.T) {
+	kev := "cveID,vendorProject,product,vulnerabilityName,dateAdded\n" +
+		"CVE-2014-0160,OpenSSL,OpenSSL,\"Heartbleed, info disclosure\",2022-05-04\n"
+	var kevs []models.VulnKEV
+	err := vulndb.ParseKEV(strings.NewReader(kev), func(v models.VulnKEV) error {
+		kevs = append(kevs, v)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, kevs, 1) {
+		assert.Equal(t, "CVE-2014-0160", kevs[0].CVE)
+		assert.Equal(t, 2022, kevs[0].DateAdded.Year())
+	}
+
+	epss := "#model_version:v2023.03.01,score_date:2024-01-01T00:00:00+0000\n" +
+		"cve,epss,percentile\n" +
+		"CVE-2014-0160,0.97,0.99\n"
+	var scores []models.VulnEPSS
+	err = vulndb.ParseEPSS(strings.NewReader(epss), func(v models.VulnEPSS) error {
+		scores = append(scores, v)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.VulnEPSS{{CVE: "CVE-2014-0160", EPSS: 0.97, Percentile: 0.99}}, scores)
+
+	err = vulndb.ParseEPSS(strings.NewReader("cve,score\n"), func(models.VulnEPSS) error { return nil })
+	assert.Error(t, err)
+}
@@ -60,9 +60,20 @@ interface ITask {
 			algorithm: string
 			detection_method: string
 			model_id: string
+			top_n: number
+			minimum_sim: number
+			model_hash?: string
+			no_cache?: boolean
+			input?: 'file' | 'container'
 		}
 	}
+	package?: 'apk' | 'aab' | 'aar' | 'jar'
+	binary?: IBinaryInfo
+	cached_from?: string
+	unpack?: ITaskUnpack
 	file_hash: string
+	file_sha1: string
+	file_md5: string
 	file_path: string
 	file_size: number
 	modified_at: string
@@ -76,6 +87,59 @@ interface ITask {
 	user_id: string
 	version: string
 }
+// 上传压缩包、安装包、固件镜像或容器镜像的解包统计
+interface ITaskUnpack {
+	archive: string
+	files: number
+	units: number
+	skipped: number
+	image?: ITaskImage
+}
+// 上传的容器镜像
+interface ITaskImage {
+	digest: string
+	tags?: string[]
+	platform?: string
+	layers: string[]
+}
+// 解包出的文件
+interface ITaskFile {
+	id: string
+	task_id: string
+	path: string
+	size: number
+	type: 'binary' | 'archive' | 'image' | 'other'
+	format?: 'elf' | 'pe' | 'macho' | 'squashfs' | 'cpio' | 'jffs2' | 'ubi' | 'ubifs'
+	object?: string
+	skipped?: string
+	layer?: string
+	abi?: string
+	arch?: string
+	binary?: IBinaryInfo
+	backend?: string
+}
+// 可执行文件的预分析结果
+interface IBinaryInfo {
+	format: 'elf' | 'pe' | 'macho'
+	arch: string
+	bits: number
+	endian: 'little' | 'big'
+	stripped: boolean
+	build_id?: string
+	soname?: string
+	needed?: string[]
+	compilers?: string[]
+	func_count: number
+}
+interface ITaskFileNode {
+	name: string
+	path: string
+	type: 'dir' | 'binary' | 'archive' | 'image' | 'other'
+	size?: number
+	format?: string
+	skipped?: string
+	children?: ITaskFileNode[]
+}
 interface IModel {
 	created_at: string
 	id: string
@@ -104,4 +168,35 @@ interface IFuncResult {
 	sim: string
 	task_id: string
 	version: string
+	triage?: ITriage
+	suppression_id?: string
+}
+interface ITriage {
+	status: 'untriaged' | 'confirmed' | 'false_positive' | 'wont_fix'
+	justification?: string
+	assignee?: string
+	comments?: ITriageComment[]
+	updated_at: string
+}
+interface ITriageComment {
+	author: string
+	content: string
+	created_at: string
+}
+interface ISuppression {
+	id: string
+	file_name: string
+	fname: string
+	addr: string
+	cve: string
+	min_sim: number
+	max_sim: number
+	project: string
+	file_hash: string
+	status: 'false_positive' | 'wont_fix'
+	justification: string
+	comment: string
+	author: string
+	created_at: string
+	updated_at: string
 }